@@ -0,0 +1,283 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// Builder constructs a Recurrence one part at a time, e.g.
+// calendar.Weekly().Every(2).On(time.Monday, time.Friday).Until(d).Build(), which starts today unless Starting is
+// called. The first invalid call is remembered and returned by Build, and later calls are ignored
+type Builder struct {
+	r   Recurrence
+	err error
+}
+
+// Daily starts building a recurrence every day
+func Daily() *Builder {
+	return &Builder{r: Recurrence{RecurrencePatternCode: FrequencyDaily, RecurEvery: 1}}
+}
+
+// Weekly starts building a recurrence every week, on the day of the week of its start unless On is called
+func Weekly() *Builder {
+	return &Builder{r: Recurrence{RecurrencePatternCode: FrequencyWeekly, RecurEvery: 1}}
+}
+
+// Monthly starts building a recurrence every month, on the day of the month of its start unless OnDay or OnNth is called
+func Monthly() *Builder {
+	return &Builder{r: Recurrence{RecurrencePatternCode: FrequencyMonthly, RecurEvery: 1}}
+}
+
+// Yearly starts building a recurrence every year, on the month and day of its start unless In, OnDay or OnNth is called
+func Yearly() *Builder {
+	return &Builder{r: Recurrence{RecurrencePatternCode: FrequencyYearly, RecurEvery: 1}}
+}
+
+// Starting sets the StartDate, which is today when it is not called
+func (b *Builder) Starting(start time.Time) *Builder {
+	if b.check(!start.IsZero(), "start date must be set") {
+		b.r.StartDate = start
+	}
+	return b
+}
+
+// Every sets the number of days, weeks, months or years between occurrences
+func (b *Builder) Every(n int) *Builder {
+	if b.check(n >= 1 && n <= 32767, "interval must be 1 to 32767, got %d", n) {
+		b.r.RecurEvery = int16(n)
+	}
+	return b
+}
+
+// Weekdays limits a daily recurrence to weekdays, so that Every counts weekdays
+func (b *Builder) Weekdays() *Builder {
+	if b.check(b.r.RecurrencePatternCode == FrequencyDaily, "only a daily recurrence can be limited to weekdays") {
+		b.r.DailyIsOnlyWeekday = boolPointer(true)
+	}
+	return b
+}
+
+// On sets the days of the week of a weekly recurrence
+func (b *Builder) On(days ...time.Weekday) *Builder {
+	if !b.check(b.r.RecurrencePatternCode == FrequencyWeekly, "only a weekly recurrence has days of the week") || !b.check(len(days) > 0, "no days of the week given") {
+		return b
+	}
+	for _, day := range days {
+		if !b.check(day >= time.Sunday && day <= time.Saturday, "day of the week %d is out of range", day) {
+			return b
+		}
+	}
+	b.r.SetWeekdays(NewWeekdays(days...))
+	return b
+}
+
+// OnDay sets the day of the month of a monthly or yearly recurrence. Negative days count back from the end of the
+// month, -1 being the last day
+func (b *Builder) OnDay(day int) *Builder {
+	if b.monthly("day of the month") && b.check(day != 0 && day >= -31 && day <= 31, "day of the month must be 1 to 31 or -1 to -31, got %d", day) &&
+		b.check(b.r.MonthlyDayOfWeek == nil, "day of the month and nth day of the week are both set") {
+		b.r.MonthlyDay = int16Pointer(day)
+	}
+	return b
+}
+
+// NearestWeekday moves the day of the month set by OnDay to the nearest weekday in the same month when it falls on
+// a weekend
+func (b *Builder) NearestWeekday() *Builder {
+	if b.monthly("nearest weekday") && b.check(b.r.MonthlyDay != nil, "nearest weekday needs a day of the month") {
+		b.r.MonthlyNearestWeekday = boolPointer(true)
+	}
+	return b
+}
+
+// OnNth sets the nth day of the week of a monthly or yearly recurrence, n being 1 to 5 or -1 for the last
+func (b *Builder) OnNth(n int, day time.Weekday) *Builder {
+	if !b.monthly("nth day of the week") || !b.check(n == -1 || n >= 1 && n <= 5, "nth day of the week must be 1 to 5 or -1, got %d", n) ||
+		!b.check(day >= time.Sunday && day <= time.Saturday, "day of the week %d is out of range", day) ||
+		!b.check(b.r.MonthlyDay == nil, "day of the month and nth day of the week are both set") {
+		return b
+	}
+	if n == -1 {
+		n = 54
+	}
+	b.r.MonthlyDayOfWeek, b.r.MonthlyWeekOfMonth = int16Pointer(int(day)), int16Pointer(n)
+	return b
+}
+
+// OnLast sets the last day of the week in the month of a monthly or yearly recurrence
+func (b *Builder) OnLast(day time.Weekday) *Builder {
+	return b.OnNth(-1, day)
+}
+
+// In sets the month of a yearly recurrence
+func (b *Builder) In(month time.Month) *Builder {
+	if b.check(b.r.RecurrencePatternCode == FrequencyYearly, "only a yearly recurrence has a month") &&
+		b.check(month >= time.January && month <= time.December, "month %d is out of range", month) {
+		b.r.YearlyMonth = int16Pointer(int(month))
+	}
+	return b
+}
+
+// Until sets the EndByDate
+func (b *Builder) Until(end time.Time) *Builder {
+	if b.check(b.r.NumberOfOccurrences == nil, "end date and number of occurrences are both set") {
+		b.r.EndByDate = &end
+	}
+	return b
+}
+
+// Times sets the NumberOfOccurrences, and Build the EndByDate of the last occurrence
+func (b *Builder) Times(n int) *Builder {
+	if b.check(b.r.EndByDate == nil, "end date and number of occurrences are both set") &&
+		b.check(n >= 1 && n <= 32767, "number of occurrences must be 1 to 32767, got %d", n) {
+		b.r.NumberOfOccurrences = int16Pointer(n)
+	}
+	return b
+}
+
+// Build returns the recurrence, filling in the StartDate of today in the local time zone when it was not given, the
+// days a weekly, monthly or yearly recurrence falls on from its start when they were not given and the EndByDate
+// of a number of occurrences, or the first error
+func (b *Builder) Build() (*Recurrence, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	r := b.r
+	if r.StartDate.IsZero() {
+		today := now()
+		r.StartDate = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local)
+	}
+	switch r.RecurrencePatternCode {
+	case FrequencyWeekly:
+		if r.WeeklyDaysIncluded == nil {
+			r.SetWeekdays(NewWeekdays(r.StartDate.Weekday()))
+		}
+	case FrequencyMonthly, FrequencyYearly:
+		if r.RecurrencePatternCode == FrequencyYearly && r.YearlyMonth == nil {
+			r.YearlyMonth = int16Pointer(int(r.StartDate.Month()))
+		}
+		if r.MonthlyDay == nil && r.MonthlyDayOfWeek == nil {
+			r.MonthlyDay = int16Pointer(r.StartDate.Day())
+		}
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if r.NumberOfOccurrences != nil {
+		if err := r.SetNumberOfOccurrences(int(*r.NumberOfOccurrences)); err != nil {
+			return nil, err
+		}
+	}
+	return &r, nil
+}
+
+// now is the clock Build starts a recurrence by when it is not given a StartDate
+var now = time.Now
+
+// Validate returns an error when the fields of the recurrence are missing, out of range or contradict each other,
+// e.g. a monthly recurrence with both MonthlyDay and MonthlyDayOfWeek or a yearly one on February 30
+func (r *Recurrence) Validate() error {
+	if r.StartDate.IsZero() {
+		return fmt.Errorf("start date must be set")
+	}
+	if r.RecurEvery < 1 {
+		return fmt.Errorf("interval must be at least 1, got %d", r.RecurEvery)
+	}
+	isSet := func(b *bool) bool { return b != nil && *b }
+	monthly := r.YearlyMonth != nil || r.MonthlyDay != nil || r.MonthlyDayOfWeek != nil || r.MonthlyWeekOfMonth != nil || isSet(r.MonthlyNearestWeekday)
+	switch r.RecurrencePatternCode {
+	case FrequencyDaily:
+		if monthly || r.WeeklyDaysIncluded != nil {
+			return fmt.Errorf("daily recurrence has weekly, monthly or yearly fields set")
+		}
+	case FrequencyWeekly:
+		if monthly || isSet(r.DailyIsOnlyWeekday) {
+			return fmt.Errorf("weekly recurrence has daily, monthly or yearly fields set")
+		}
+		if r.WeeklyDaysIncluded != nil && (*r.WeeklyDaysIncluded < 1 || *r.WeeklyDaysIncluded > 127) {
+			return fmt.Errorf("weekly days included must be 1 to 127, got %d", *r.WeeklyDaysIncluded)
+		}
+	case FrequencyMonthly, FrequencyYearly:
+		if r.WeeklyDaysIncluded != nil || isSet(r.DailyIsOnlyWeekday) {
+			return fmt.Errorf("monthly or yearly recurrence has daily or weekly fields set")
+		}
+		if err := r.validateMonthDay(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown recurrence pattern code %q", r.RecurrencePatternCode)
+	}
+	if r.NumberOfOccurrences != nil && *r.NumberOfOccurrences < 1 {
+		return fmt.Errorf("number of occurrences must be at least 1, got %d", *r.NumberOfOccurrences)
+	}
+	if r.EndByDate != nil {
+		start := time.Date(r.StartDate.Year(), r.StartDate.Month(), r.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+		end := time.Date(r.EndByDate.Year(), r.EndByDate.Month(), r.EndByDate.Day(), 0, 0, 0, 0, time.UTC)
+		if end.Before(start) {
+			return fmt.Errorf("end date %s is before start date %s", end.Format("2006-01-02"), start.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
+
+// validateMonthDay checks the day of the month or nth day of the week, and the month, of a monthly or yearly recurrence
+func (r *Recurrence) validateMonthDay() error {
+	if r.RecurrencePatternCode == FrequencyMonthly && r.YearlyMonth != nil {
+		return fmt.Errorf("monthly recurrence has a yearly month set")
+	}
+	if r.RecurrencePatternCode == FrequencyYearly && (r.YearlyMonth == nil || *r.YearlyMonth < 1 || *r.YearlyMonth > 12) {
+		return fmt.Errorf("yearly recurrence must have a month from 1 to 12")
+	}
+	switch {
+	case r.MonthlyDay != nil && (r.MonthlyDayOfWeek != nil || r.MonthlyWeekOfMonth != nil):
+		return fmt.Errorf("day of the month and nth day of the week are both set")
+	case r.MonthlyDay != nil:
+		day := int(*r.MonthlyDay)
+		if day == 0 || day < -31 || day > 31 {
+			return fmt.Errorf("day of the month must be 1 to 31 or -1 to -31, got %d", day)
+		}
+		if r.YearlyMonth != nil {
+			// February 29 is allowed since it occurs in leap years
+			if days := daysIn(time.Month(*r.YearlyMonth), 2024); day > days || -day > days {
+				return fmt.Errorf("%s has no day %d", time.Month(*r.YearlyMonth), day)
+			}
+		}
+	case r.MonthlyDayOfWeek != nil && r.MonthlyWeekOfMonth != nil:
+		if r.MonthlyNearestWeekday != nil && *r.MonthlyNearestWeekday {
+			return fmt.Errorf("nearest weekday needs a day of the month")
+		}
+		if dayOfWeek := *r.MonthlyDayOfWeek; dayOfWeek < 0 || dayOfWeek > 6 {
+			return fmt.Errorf("day of the week must be 0 to 6, got %d", dayOfWeek)
+		}
+		if week := *r.MonthlyWeekOfMonth; week != 54 && (week < 1 || week > 5) {
+			return fmt.Errorf("week of the month must be 1 to 5 or 54, got %d", week)
+		}
+	default:
+		return fmt.Errorf("recurrence has neither MonthlyDay nor MonthlyDayOfWeek and MonthlyWeekOfMonth")
+	}
+	return nil
+}
+
+// check records the error when ok is false and no earlier error was recorded, and returns whether to go on
+func (b *Builder) check(ok bool, format string, args ...interface{}) bool {
+	if b.err != nil {
+		return false
+	}
+	if !ok {
+		b.err = fmt.Errorf(format, args...)
+	}
+	return ok
+}
+
+func (b *Builder) monthly(part string) bool {
+	return b.check(b.r.RecurrencePatternCode == FrequencyMonthly || b.r.RecurrencePatternCode == FrequencyYearly, "only a monthly or yearly recurrence has a %s", part)
+}
+
+func int16Pointer(value int) *int16 {
+	v := int16(value)
+	return &v
+}
+
+func boolPointer(value bool) *bool {
+	return &value
+}
@@ -0,0 +1,153 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	start := time.Date(2026, 1, 7, 9, 0, 0, 0, time.UTC) // Wednesday
+	end := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	builders := []struct {
+		builder  *Builder
+		expected Recurrence
+	}{
+		{Daily().Starting(start), Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1}},
+		{Daily().Starting(start).Every(4).Weekdays().Times(10), Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 4,
+			DailyIsOnlyWeekday: boolPtr(true), NumberOfOccurrences: int16Ptr(10), EndByDate: timePtr(time.Date(2026, 2, 26, 0, 0, 0, 0, time.UTC))}},
+		{Weekly().Starting(start), Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(8)}},
+		{Weekly().Starting(start).Every(2).On(time.Monday, time.Friday).Until(end), Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 2,
+			WeeklyDaysIncluded: int16Ptr(32 + 2), EndByDate: &end}},
+		{Monthly().Starting(start), Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(7)}},
+		{Monthly().Starting(start).Every(3).OnDay(-1).NearestWeekday(), Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 3,
+			MonthlyDay: int16Ptr(-1), MonthlyNearestWeekday: boolPtr(true)}},
+		{Monthly().Starting(start).OnDay(31).Times(3), Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(31),
+			NumberOfOccurrences: int16Ptr(3), EndByDate: timePtr(time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC))}},
+		{Monthly().Starting(start).OnNth(2, time.Tuesday), Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1,
+			MonthlyDayOfWeek: int16Ptr(2), MonthlyWeekOfMonth: int16Ptr(2)}},
+		{Yearly().Starting(start), Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(1), MonthlyDay: int16Ptr(7)}},
+		{Yearly().Starting(start).In(time.November).OnLast(time.Thursday), Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1,
+			YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54)}},
+		{Yearly().Starting(start).Every(4).In(time.February).OnDay(29), Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 4,
+			YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(29)}},
+	}
+	for i, builder := range builders {
+		actual, err := builder.builder.Build()
+		if err != nil {
+			t.Error(i, err)
+			continue
+		}
+		compareRecurrences(t, &builder.expected, actual, string(builder.expected.RecurrencePatternCode))
+		if builder.expected.NumberOfOccurrences != nil {
+			compareOccurrenceCount(t, int(*builder.expected.NumberOfOccurrences), actual, string(builder.expected.RecurrencePatternCode))
+		}
+	}
+
+	invalid := []*Builder{
+		Daily().Starting(time.Time{}),
+		Daily().Starting(start).Every(0),
+		Daily().Starting(start).On(time.Monday),
+		Weekly().Starting(start).Weekdays(),
+		Weekly().Starting(start).On(),
+		Weekly().Starting(start).On(time.Weekday(7)),
+		Weekly().Starting(start).OnDay(1),
+		Monthly().Starting(start).OnDay(0),
+		Monthly().Starting(start).OnDay(32),
+		Monthly().Starting(start).NearestWeekday(),
+		Monthly().Starting(start).OnDay(1).OnNth(1, time.Monday),
+		Monthly().Starting(start).OnNth(1, time.Monday).OnDay(1),
+		Monthly().Starting(start).OnNth(6, time.Monday),
+		Monthly().Starting(start).OnNth(1, time.Weekday(-1)),
+		Monthly().Starting(start).In(time.March),
+		Yearly().Starting(start).In(13),
+		Yearly().Starting(start).In(time.February).OnDay(30),
+		Yearly().Starting(start).In(time.April).OnDay(-31),
+		Daily().Starting(start).Until(end).Times(3),
+		Daily().Starting(start).Times(3).Until(end),
+		Daily().Starting(start).Times(0),
+		Yearly().Starting(start).Every(4).In(time.February).OnDay(29).Times(1),
+		Daily().Starting(start).Until(start.AddDate(0, 0, -1)),
+	}
+	for i, builder := range invalid {
+		if r, err := builder.Build(); err == nil {
+			t.Errorf("expected builder %d to fail: %+v", i, r)
+		}
+	}
+
+	// without Starting, the recurrence starts today
+	now = func() time.Time { return time.Date(2026, 1, 7, 23, 30, 0, 0, time.Local) }
+	defer func() { now = time.Now }()
+	today := time.Date(2026, 1, 7, 0, 0, 0, 0, time.Local)
+	if actual, err := Weekly().Every(2).On(time.Monday, time.Friday).Until(end).Build(); err != nil {
+		t.Error(err)
+	} else {
+		compareRecurrences(t, &Recurrence{StartDate: today, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(32 + 2), EndByDate: &end}, actual, "W from today")
+	}
+	if actual, err := Monthly().Build(); err != nil {
+		t.Error(err)
+	} else {
+		compareRecurrences(t, &Recurrence{StartDate: today, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(7)}, actual, "M from today")
+	}
+
+	b := Weekly().Every(0).Every(2)
+	if _, err := b.Build(); err == nil || err.Error() != "interval must be 1 to 32767, got 0" {
+		t.Error("expected the first error to be kept", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	start := time.Date(2026, 1, 7, 9, 0, 0, 0, time.UTC)
+	valid := []Recurrence{
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(false)},
+		{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1},
+		{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(127), EndByDate: timePtr(start)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(31)},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(-29)},
+	}
+	for i, r := range valid {
+		if err := r.Validate(); err != nil {
+			t.Error(i, err)
+		}
+	}
+
+	invalid := []Recurrence{
+		{RecurrencePatternCode: "D", RecurEvery: 1},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 0},
+		{StartDate: start, RecurrencePatternCode: "X", RecurEvery: 1},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, MonthlyDay: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(0)},
+		{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(128)},
+		{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(1), WeeklyDaysIncluded: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(1), YearlyMonth: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(1), MonthlyDayOfWeek: int16Ptr(1), MonthlyWeekOfMonth: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(7), MonthlyWeekOfMonth: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(1), MonthlyWeekOfMonth: int16Ptr(6)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(1), MonthlyWeekOfMonth: int16Ptr(1), MonthlyNearestWeekday: boolPtr(true)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-32)},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, MonthlyDay: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(6), MonthlyDay: int16Ptr(31)},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, NumberOfOccurrences: int16Ptr(0)},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, EndByDate: timePtr(start.AddDate(0, 0, -1))},
+	}
+	for i, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("expected recurrence %d to be invalid", i)
+		}
+	}
+
+	// recurrences from the converters are valid
+	for _, value := range []string{"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;WKST=SU", "FREQ=MONTHLY;BYMONTHDAY=-3",
+		"FREQ=MONTHLY;BYDAY=-1FR;COUNT=4", "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH;UNTIL=20301231"} {
+		r, err := ParseRRule(value, start)
+		if err != nil {
+			t.Fatal(value, err)
+		}
+		if err := r.Validate(); err != nil {
+			t.Error(value, err)
+		}
+	}
+}
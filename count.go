@@ -0,0 +1,245 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"math/bits"
+	"time"
+)
+
+// maxYearsWithoutOccurrence bounds the search for the next monthly or yearly occurrence. The calendar repeats
+// every 400 years, so a recurrence with no occurrence in that time never has another
+const maxYearsWithoutOccurrence = 400
+
+// Count returns the number of occurrences GetOccurrences returns from timePeriodStart through timePeriodEnd,
+// without expanding the occurrences of a daily or weekly recurrence. The ExceptionDates of a Series are not
+// taken into account
+func (r *Recurrence) Count(timePeriodStart, timePeriodEnd time.Time) int {
+	count, _ := r.count(context.Background(), timePeriodStart, timePeriodEnd)
+	return count
+}
+
+// count is Count returning the error of ctx once it is done, which is checked every contextCheckPeriods occurrences
+// of a monthly or yearly recurrence
+func (r *Recurrence) count(ctx context.Context, timePeriodStart, timePeriodEnd time.Time) (int, error) {
+	// occurrences are at midnight UTC, so the first one counted is at or after timePeriodStart
+	from, to := timePeriodStart.UTC(), timePeriodEnd.UTC()
+	from, to = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC), time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	if from.Before(timePeriodStart) {
+		from = from.AddDate(0, 0, 1)
+	}
+	if r.RecurEvery < 1 || to.Before(from) {
+		return 0, nil
+	}
+	switch r.RecurrencePatternCode {
+	case FrequencyDaily, FrequencyWeekly:
+		return r.countThrough(to) - r.countThrough(from.AddDate(0, 0, -1)), nil
+	}
+	count := 0
+	for occurrence := range r.occurrencesFrom(from) {
+		if occurrence.After(to) {
+			break
+		}
+		count++
+		if isDone(ctx, count) {
+			return 0, ctx.Err()
+		}
+	}
+	return count, nil
+}
+
+// IndexOf returns the position of the occurrence on date among the occurrences from the StartDate, the first
+// being 0, or -1 when date is not an occurrence on or after the StartDate
+func (r *Recurrence) IndexOf(date time.Time) int {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if !r.IsValidOccurrenceDate(date) || date.Before(r.startDate()) {
+		return -1
+	}
+	return r.Count(r.startDate(), date) - 1
+}
+
+// OccurrenceAt returns the nth occurrence from the StartDate, the first being 0, or false when the recurrence
+// ends before it has n+1 occurrences
+func (r *Recurrence) OccurrenceAt(n int) (time.Time, bool) {
+	if n < 0 || r.RecurEvery < 1 {
+		return time.Time{}, false
+	}
+	startDate := r.startDate()
+	switch r.RecurrencePatternCode {
+	case FrequencyDaily:
+		occurrence := startDate.AddDate(0, 0, n*int(r.RecurEvery))
+		if r.DailyIsOnlyWeekday != nil && *r.DailyIsOnlyWeekday {
+			occurrence = addWeekdays(n*int(r.RecurEvery), startDate)
+		}
+		if endDate := r.endDate(); endDate != nil && occurrence.After(*endDate) {
+			return time.Time{}, false
+		}
+		return occurrence, true
+	case FrequencyWeekly:
+		weekdays := r.Weekdays()
+		perWeek := bits.OnesCount8(uint8(weekdays))
+		if perWeek == 0 {
+			return time.Time{}, false
+		}
+		n += r.weeklyCountThrough(startDate.AddDate(0, 0, -1)) // skip the days of the first week before the start
+		weekStartDate := startDate.AddDate(0, 0, 7*(n/perWeek)*int(r.RecurEvery)-int(startDate.Weekday()))
+		occurrence := weekStartDate.AddDate(0, 0, int(weekdays.Days()[n%perWeek]))
+		if !r.IsValidOccurrenceDate(occurrence) {
+			return time.Time{}, false // after the EndByDate
+		}
+		return occurrence, true
+	}
+	for occurrence := range r.occurrencesFrom(startDate) {
+		if n == 0 {
+			return occurrence, true
+		}
+		n--
+	}
+	return time.Time{}, false
+}
+
+// countThrough returns the number of occurrences of a daily or weekly recurrence on or before date
+func (r *Recurrence) countThrough(date time.Time) int {
+	startDate := r.startDate()
+	recurEvery := int(r.RecurEvery)
+	if endDate := r.endDate(); endDate != nil && date.After(*endDate) {
+		date = *endDate
+	}
+	if r.RecurrencePatternCode == FrequencyDaily {
+		days := getDays(startDate, date)
+		switch {
+		case days < 0:
+			return 0
+		case r.DailyIsOnlyWeekday != nil && *r.DailyIsOnlyWeekday:
+			return 1 + getWeekdays(days, startDate)/recurEvery // the start plus every recurEvery weekdays after it
+		}
+		return days/recurEvery + 1
+	}
+	if date.Before(startDate) {
+		return 0
+	}
+	return r.weeklyCountThrough(date) - r.weeklyCountThrough(startDate.AddDate(0, 0, -1))
+}
+
+// weeklyCountThrough returns the number of days of a weekly recurrence on or before date, counting from the
+// beginning of the week of the StartDate
+func (r *Recurrence) weeklyCountThrough(date time.Time) int {
+	startDate := r.startDate()
+	recurEvery := int(r.RecurEvery)
+	weekdays := r.Weekdays()
+	weeks := getWeeks(startDate.AddDate(0, 0, -1*int(startDate.Weekday())), date.AddDate(0, 0, -1*int(date.Weekday())))
+	if weeks < 0 {
+		return 0
+	}
+	count := (weeks + recurEvery - 1) / recurEvery * bits.OnesCount8(uint8(weekdays)) // whole weeks before the week of date
+	if weeks%recurEvery == 0 {
+		count += bits.OnesCount8(uint8(weekdays) & (2<<uint(date.Weekday()) - 1)) // days of its week up to date
+	}
+	return count
+}
+
+// occurrencesFrom yields the occurrences on or after date in order, until the EndByDate
+func (r *Recurrence) occurrencesFrom(date time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		if r.RecurEvery < 1 {
+			return
+		}
+		switch r.RecurrencePatternCode {
+		case FrequencyDaily, FrequencyWeekly:
+			for n := r.countThrough(date.AddDate(0, 0, -1)); ; n++ {
+				occurrence, ok := r.OccurrenceAt(n)
+				if !ok || !yield(occurrence) {
+					return
+				}
+			}
+		case FrequencyMonthly, FrequencyYearly:
+			r.monthlyOccurrencesFrom(date, yield)
+		}
+	}
+}
+
+// monthlyOccurrencesFrom walks the months, or the YearlyMonth of the years, that a monthly or yearly recurrence
+// has its occurrences in
+func (r *Recurrence) monthlyOccurrencesFrom(date time.Time, yield func(time.Time) bool) {
+	startDate, endDate := r.startDate(), r.endDate()
+	if date.Before(startDate) {
+		date = startDate
+	}
+	months := int(r.RecurEvery)
+	periodStartDate := startDate.AddDate(0, 0, 1-startDate.Day())
+	if r.RecurrencePatternCode == FrequencyYearly {
+		if r.YearlyMonth == nil {
+			return
+		}
+		months *= 12
+		periodStartDate = time.Date(startDate.Year(), time.Month(*r.YearlyMonth), 1, 0, 0, 0, 0, time.UTC)
+	}
+	if periods := getMonths(periodStartDate, date); periods > 0 {
+		periodStartDate = periodStartDate.AddDate(0, periods/months*months, 0)
+	}
+	last := date
+	for endDate == nil || !periodStartDate.After(*endDate) {
+		for _, occurrence := range getMonthOccurrence(periodStartDate, date, periodStartDate.AddDate(0, 1, 0), r.MonthlyDay, r.MonthlyDayOfWeek, r.MonthlyWeekOfMonth, r.MonthlyNearestWeekday) {
+			if endDate != nil && occurrence.After(*endDate) || !yield(occurrence) {
+				return
+			}
+			last = occurrence
+		}
+		if periodStartDate.After(last.AddDate(maxYearsWithoutOccurrence, 0, 0)) {
+			break
+		}
+		periodStartDate = periodStartDate.AddDate(0, months, 0)
+	}
+}
+
+func (r *Recurrence) startDate() time.Time {
+	return time.Date(r.StartDate.Year(), r.StartDate.Month(), r.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (r *Recurrence) endDate() *time.Time {
+	if r.EndByDate == nil {
+		return nil
+	}
+	end := time.Date(r.EndByDate.Year(), r.EndByDate.Month(), r.EndByDate.Day(), 0, 0, 0, 0, time.UTC)
+	return &end
+}
+
+// EndByDateFor returns the date of the last of count occurrences from the StartDate, ignoring any EndByDate
+func (r *Recurrence) EndByDateFor(count int) (time.Time, error) {
+	if count < 1 {
+		return time.Time{}, fmt.Errorf("number of occurrences must be at least 1, got %d", count)
+	}
+	if !r.RecurrencePatternCode.Valid() {
+		return time.Time{}, fmt.Errorf("unknown recurrence pattern code %q", r.RecurrencePatternCode)
+	}
+	unbounded := *r
+	unbounded.EndByDate = nil
+	date, ok := unbounded.OccurrenceAt(count - 1)
+	if !ok {
+		return time.Time{}, fmt.Errorf("recurrence has fewer than %d occurrences", count)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, r.StartDate.Location()), nil
+}
+
+// SetNumberOfOccurrences sets NumberOfOccurrences to count and EndByDate to the date of the last of those
+// occurrences, so that the recurrence ends after count occurrences
+func (r *Recurrence) SetNumberOfOccurrences(count int) error {
+	if count > 32767 {
+		return fmt.Errorf("number of occurrences must be at most 32767, got %d", count)
+	}
+	endByDate, err := r.EndByDateFor(count)
+	if err != nil {
+		return err
+	}
+	r.EndByDate, r.NumberOfOccurrences = &endByDate, int16Pointer(count)
+	return nil
+}
+
+// CountUntil returns the number of occurrences from the StartDate through endByDate, ignoring any EndByDate. It
+// is the NumberOfOccurrences of the recurrence ending by endByDate
+func (r *Recurrence) CountUntil(endByDate time.Time) int {
+	unbounded := *r
+	unbounded.EndByDate = nil
+	return unbounded.Count(r.startDate(), time.Date(endByDate.Year(), endByDate.Month(), endByDate.Day(), 0, 0, 0, 0, time.UTC))
+}
@@ -0,0 +1,151 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCount(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 30, 0, 0, time.UTC)
+	r := Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(42)} // MWF every 2 weeks
+	if actual := r.Count(start, start.AddDate(0, 1, 0)); actual != 6 {
+		t.Error("expected the 6 occurrences after the start time GetOccurrences returns", actual)
+	}
+	if actual := r.Count(start.AddDate(0, 1, 0), start); actual != 0 {
+		t.Error("expected no occurrences in a backwards time period", actual)
+	}
+	daily := Recurrence{StartDate: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "D", RecurEvery: 1}
+	if actual := daily.Count(time.Date(2016, 1, 10, 12, 30, 0, 0, time.UTC), time.Date(2016, 1, 12, 12, 30, 0, 0, time.UTC)); actual != 2 {
+		t.Error("expected the 11th and 12th but not the 10th before the time period starts", actual)
+	}
+
+	// every date in 4 year windows against IsValidOccurrenceDate
+	for i, r := range variedRecurrences() {
+		start := time.Date(r.StartDate.Year(), r.StartDate.Month(), r.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+		for _, from := range []time.Time{start.AddDate(0, 0, -40), start, start.AddDate(0, 2, 3), start.AddDate(1, 0, 0)} {
+			expected := 0
+			for date := from; !date.After(from.AddDate(4, 0, 0)); date = date.AddDate(0, 0, 1) {
+				if r.IsValidOccurrenceDate(date) {
+					expected++
+				}
+			}
+			if actual := r.Count(from, from.AddDate(4, 0, 0)); actual != expected {
+				t.Fatalf("recurrence %d %+v from %s: expected %d vs actual %d", i, r, from.Format("2006-01-02"), expected, actual)
+			}
+		}
+	}
+}
+
+// TestCountMatchesGetOccurrences checks Count against GetOccurrences for time periods that start and end during a day
+func TestCountMatchesGetOccurrences(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range variedRecurrences() {
+		start := r.startDate()
+		for _, from := range []time.Time{start.Add(-90 * time.Minute), start.Add(12*time.Hour + 30*time.Minute), start.AddDate(0, 2, 3).Add(time.Nanosecond),
+			time.Date(start.Year(), start.Month(), start.Day()+10, 21, 0, 0, 0, newYork)} {
+			for _, length := range []time.Duration{0, 36 * time.Hour, 17*24*time.Hour + 6*time.Hour, 400*24*time.Hour - time.Second} {
+				to := from.Add(length)
+				if expected, actual := len(r.GetOccurrences(from, to)), r.Count(from, to); expected != actual {
+					t.Fatalf("recurrence %d %+v from %s to %s: expected %d vs actual %d", i, r, from, to, expected, actual)
+				}
+			}
+		}
+	}
+}
+
+func TestOccurrenceAt(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 30, 0, 0, time.UTC)
+	r := Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 4, DailyIsOnlyWeekday: boolPtr(true)}
+	if actual, ok := r.OccurrenceAt(3); !ok || actual != time.Date(2016, 1, 19, 0, 0, 0, 0, time.UTC) {
+		t.Error("expected the 4th occurrence on the 19th", actual)
+	}
+	if _, ok := r.OccurrenceAt(-1); ok {
+		t.Error("expected no occurrence before the first")
+	}
+	if actual := r.IndexOf(time.Date(2016, 1, 19, 9, 0, 0, 0, time.Local)); actual != 3 {
+		t.Error("expected the 19th to be the 4th occurrence", actual)
+	}
+	if actual := r.IndexOf(time.Date(2016, 1, 18, 0, 0, 0, 0, time.UTC)); actual != -1 {
+		t.Error("expected the 18th not to be an occurrence", actual)
+	}
+
+	// every occurrence from the start against IsValidOccurrenceDate
+	for i, r := range variedRecurrences() {
+		start := time.Date(r.StartDate.Year(), r.StartDate.Month(), r.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+		n := 0
+		for date := start.AddDate(0, 0, -40); date.Before(start.AddDate(3, 0, 0)); date = date.AddDate(0, 0, 1) {
+			if !r.IsValidOccurrenceDate(date) || date.Before(start) {
+				if index := r.IndexOf(date); index != -1 {
+					t.Fatalf("recurrence %d %+v: expected %s not to have an index, got %d", i, r, date.Format("2006-01-02"), index)
+				}
+				continue
+			}
+			if actual, ok := r.OccurrenceAt(n); !ok || actual != date {
+				t.Fatalf("recurrence %d %+v: expected occurrence %d on %s vs actual %s", i, r, n, date.Format("2006-01-02"), actual.Format("2006-01-02"))
+			}
+			if index := r.IndexOf(date); index != n {
+				t.Fatalf("recurrence %d %+v: expected %s to be occurrence %d vs actual %d", i, r, date.Format("2006-01-02"), n, index)
+			}
+			n++
+		}
+		if r.EndByDate != nil {
+			if actual, ok := r.OccurrenceAt(n); ok {
+				t.Fatalf("recurrence %d %+v: expected %d occurrences, got another on %s", i, r, n, actual.Format("2006-01-02"))
+			}
+		}
+	}
+
+	// a monthly recurrence that never has an occurrence ends rather than searching forever
+	r = Recurrence{StartDate: time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 12, MonthlyDay: int16Ptr(30)}
+	if actual, ok := r.OccurrenceAt(0); ok {
+		t.Error("expected February 30 never to occur", actual)
+	}
+}
+
+func TestSetNumberOfOccurrences(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 30, 0, 0, time.UTC)
+	r := Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(42)} // MWF
+	if err := r.SetNumberOfOccurrences(4); err != nil {
+		t.Fatal(err)
+	}
+	if *r.NumberOfOccurrences != 4 || *r.EndByDate != time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC) {
+		t.Error("expected 4 occurrences ending on the 8th", *r.NumberOfOccurrences, *r.EndByDate)
+	}
+	expected := []time.Time{time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, r.GetOccurrences(expected[0], start.AddDate(1, 0, 0)), "TestSetNumberOfOccurrences, MWF 4 times")
+
+	for i, r := range variedRecurrences() {
+		if _, ok := r.OccurrenceAt(0); r.EndByDate != nil || !ok {
+			continue // e.g. February 29 every other year from an odd year
+		}
+		for count := 1; count <= 30; count++ {
+			if err := r.SetNumberOfOccurrences(count); err != nil {
+				t.Fatal(i, count, err)
+			}
+			last, ok := r.OccurrenceAt(count - 1)
+			if !ok || !last.Equal(*r.EndByDate) {
+				t.Fatalf("recurrence %d %+v: expected occurrence %d on the EndByDate, got %s", i, r, count, last)
+			}
+			if actual := r.Count(r.startDate(), r.EndByDate.AddDate(100, 0, 0)); actual != count {
+				t.Fatalf("recurrence %d %+v: expected %d occurrences vs actual %d", i, r, count, actual)
+			}
+			if actual := r.CountUntil(*r.EndByDate); actual != count {
+				t.Fatalf("recurrence %d %+v: expected %d occurrences until the EndByDate vs actual %d", i, r, count, actual)
+			}
+		}
+	}
+
+	for _, count := range []int{0, 32768} {
+		if err := r.SetNumberOfOccurrences(count); err == nil {
+			t.Error("expected an error for", count)
+		}
+	}
+	r = Recurrence{StartDate: time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 12, MonthlyDay: int16Ptr(30)}
+	if _, err := r.EndByDateFor(1); err == nil {
+		t.Error("expected an error for a recurrence without occurrences")
+	}
+}
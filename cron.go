@@ -0,0 +1,312 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var cronMonthNames = []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+var cronDayNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+
+// cronMacros are the @ shorthands understood by Vixie cron other than @reboot
+var cronMacros = map[string]string{
+	"@yearly": "0 0 1 1 *", "@annually": "0 0 1 1 *", "@monthly": "0 0 1 * *", "@weekly": "0 0 * * 0",
+	"@daily": "0 0 * * *", "@midnight": "0 0 * * *", "@hourly": "0 * * * *",
+}
+
+// ParseCron converts a 5 field cron expression (minute hour day-of-month month day-of-week) to the recurrences that
+// fire at the same times, starting on the date of start in its time zone. One Recurrence is returned per time of day
+// and per day of the month or month that a single Recurrence cannot combine. Days of the week limited to some months
+// or to some days of the month have no equivalent and return ErrNotRepresentable
+func ParseCron(expr string, start time.Time) ([]Recurrence, error) {
+	if macro, ok := cronMacros[strings.ToLower(strings.TrimSpace(expr))]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields", expr)
+	}
+	minutes, err := parseCronField(fields[0], 0, 59, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron minute %q: %w", fields[0], err)
+	}
+	hours, err := parseCronField(fields[1], 0, 23, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron hour %q: %w", fields[1], err)
+	}
+	days, err := parseCronField(fields[2], 1, 31, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron day of month %q: %w", fields[2], err)
+	}
+	months, err := parseCronField(fields[3], 1, 12, cronMonthNames)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron month %q: %w", fields[3], err)
+	}
+	weekdays, err := parseCronField(fields[4], 0, 7, cronDayNames)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron day of week %q: %w", fields[4], err)
+	}
+	if len(weekdays) > 0 && weekdays[len(weekdays)-1] == 7 { // 7 is another name for Sunday
+		weekdays = weekdays[:len(weekdays)-1]
+		if len(weekdays) == 0 || weekdays[0] != 0 {
+			weekdays = append([]int{0}, weekdays...)
+		}
+	}
+
+	// as in Vixie cron, the day fields are OR'd only when neither starts with *, even when stepped
+	allDays, allMonths, allWeekdays := len(days) == 31, len(months) == 12, len(weekdays) == 7
+	either := !strings.HasPrefix(fields[2], "*") && !strings.HasPrefix(fields[4], "*")
+	var dates []Recurrence
+	switch {
+	case either && (allDays || allWeekdays), !either && allDays && allWeekdays:
+		if !allMonths {
+			return nil, fmt.Errorf("%w: cron expression runs every day of some months", ErrNotRepresentable)
+		}
+		dates = append(dates, Recurrence{StartDate: start, RecurrencePatternCode: FrequencyDaily, RecurEvery: 1})
+	case !either && !allDays && !allWeekdays:
+		return nil, fmt.Errorf("%w: cron expression runs on days of the month that are also given days of the week", ErrNotRepresentable)
+	default:
+		if !allDays {
+			if dates, err = cronMonthlyDates(cronMonthlyDays(days), months, start); err != nil {
+				return nil, err
+			}
+		}
+		if !allWeekdays {
+			if !allMonths {
+				return nil, fmt.Errorf("%w: cron expression runs on days of the week in some months", ErrNotRepresentable)
+			}
+			var weeklyDaysIncluded int16
+			for _, weekday := range weekdays {
+				weeklyDaysIncluded |= weekdayBit(time.Weekday(weekday))
+			}
+			dates = append(dates, Recurrence{StartDate: start, RecurrencePatternCode: FrequencyWeekly, RecurEvery: 1, WeeklyDaysIncluded: &weeklyDaysIncluded})
+		}
+	}
+	return cronTimes(dates, hours, minutes, []int{0}), nil
+}
+
+// cronTimes returns a copy of each date recurrence per time of day
+func cronTimes(dates []Recurrence, hours, minutes, seconds []int) []Recurrence {
+	var recurrences []Recurrence
+	for _, date := range dates {
+		for _, hour := range hours {
+			for _, minute := range minutes {
+				for _, second := range seconds {
+					r := date
+					r.StartDate = time.Date(date.StartDate.Year(), date.StartDate.Month(), date.StartDate.Day(), hour, minute, second, 0, date.StartDate.Location())
+					recurrences = append(recurrences, r)
+				}
+			}
+		}
+	}
+	return recurrences
+}
+
+// cronMonthlyDays returns a day selector for each day of the month
+func cronMonthlyDays(days []int) []Recurrence {
+	selectors := make([]Recurrence, len(days))
+	for i, day := range days {
+		monthlyDay := int16(day)
+		selectors[i].MonthlyDay = &monthlyDay
+	}
+	return selectors
+}
+
+// cronMonthlyDates returns a monthly recurrence per day selector when months is every month or an even step through
+// the year, and otherwise a yearly recurrence per month and selector. Days a month never has are skipped
+func cronMonthlyDates(selectors []Recurrence, months []int, start time.Time) ([]Recurrence, error) {
+	step := cronMonthStep(months)
+	var dates []Recurrence
+	for _, selector := range selectors {
+		if step > 0 {
+			// the recurrence must start in one of the months so that every step months lands on the others
+			r := selector
+			r.StartDate, r.RecurrencePatternCode, r.RecurEvery = start, FrequencyMonthly, int16(step)
+			for (int(r.StartDate.Month())-months[0])%step != 0 {
+				r.StartDate = time.Date(r.StartDate.Year(), r.StartDate.Month()+1, 1, 0, 0, 0, 0, start.Location())
+			}
+			dates = append(dates, r)
+			continue
+		}
+		for _, month := range months {
+			if selector.MonthlyDay != nil {
+				day := int(*selector.MonthlyDay)
+				if day < 0 {
+					day = -day
+				}
+				if day > daysIn(time.Month(month), 2016) { // 2016 is a leap year so February 29 is kept
+					continue
+				}
+			}
+			yearlyMonth := int16(month)
+			r := selector
+			r.StartDate, r.RecurrencePatternCode, r.RecurEvery, r.YearlyMonth = start, FrequencyYearly, 1, &yearlyMonth
+			dates = append(dates, r)
+		}
+	}
+	if len(dates) == 0 {
+		return nil, fmt.Errorf("cron expression never runs")
+	}
+	return dates, nil
+}
+
+// cronMonthStep returns the number of months between each of several months when they repeat evenly every year, or 0
+func cronMonthStep(months []int) int {
+	if len(months) == 1 || 12%len(months) != 0 {
+		return 0
+	}
+	step := 12 / len(months)
+	for i, month := range months {
+		if month != months[0]+i*step {
+			return 0
+		}
+	}
+	return step
+}
+
+// parseCronField returns the sorted values of a comma separated list of *, values, ranges and /steps. names, when
+// given, are the three letter names of the values from min
+func parseCronField(field string, min, max int, names []string) ([]int, error) {
+	included := make([]bool, max+1)
+	for _, item := range strings.Split(field, ",") {
+		valueRange, stepValue, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepValue); err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step %q", stepValue)
+			}
+		}
+		first, last := min, max
+		if valueRange != "*" {
+			firstValue, lastValue, isRange := strings.Cut(valueRange, "-")
+			var err error
+			if first, err = parseCronValue(firstValue, min, max, names); err != nil {
+				return nil, err
+			}
+			last = first
+			if isRange {
+				if last, err = parseCronValue(lastValue, min, max, names); err != nil {
+					return nil, err
+				}
+				if last < first {
+					return nil, fmt.Errorf("invalid range %q", valueRange)
+				}
+			} else if hasStep {
+				last = max // a/step runs from a to the end of the range
+			}
+		}
+		for value := first; value <= last; value += step {
+			included[value] = true
+		}
+	}
+	var values []int
+	for value := min; value <= max; value++ {
+		if included[value] {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+func parseCronValue(value string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if name != "" && strings.EqualFold(value, name) {
+			return i, nil
+		}
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i < min || i > max {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return i, nil
+}
+
+// Cron returns the recurrence as a 5 field cron expression firing at the StartDate time of day. Cron has no end
+// or interval for days, weeks or years and no nth day of the week, so recurrences using those, and months that do not
+// repeat evenly each year, return ErrNotRepresentable
+func (r *Recurrence) Cron() (string, error) {
+	if r.EndByDate != nil || r.NumberOfOccurrences != nil {
+		return "", fmt.Errorf("%w: cron expressions cannot end", ErrNotRepresentable)
+	}
+	if r.StartDate.Second() != 0 || r.StartDate.Nanosecond() != 0 {
+		return "", fmt.Errorf("%w: cron expressions cannot run at seconds past the minute", ErrNotRepresentable)
+	}
+	if r.RecurEvery < 1 {
+		return "", fmt.Errorf("%w: cron interval must be at least 1, got %d", ErrNotRepresentable, r.RecurEvery)
+	}
+	day, month, weekday := "*", "*", "*"
+	switch r.RecurrencePatternCode {
+	case FrequencyDaily:
+		if r.RecurEvery != 1 {
+			return "", fmt.Errorf("%w: cron cannot run every %d days", ErrNotRepresentable, r.RecurEvery)
+		}
+		if r.DailyIsOnlyWeekday != nil && *r.DailyIsOnlyWeekday {
+			weekday = "1-5"
+		}
+	case FrequencyWeekly:
+		if r.RecurEvery != 1 {
+			return "", fmt.Errorf("%w: cron cannot run every %d weeks", ErrNotRepresentable, r.RecurEvery)
+		}
+		if included := r.Weekdays(); included != AllWeekdays {
+			var weekdays []int
+			for includedDay := range included.Iter() {
+				weekdays = append(weekdays, int(includedDay))
+			}
+			weekday = formatCronList(weekdays)
+		}
+	case FrequencyMonthly, FrequencyYearly:
+		if r.MonthlyDay == nil {
+			return "", fmt.Errorf("%w: cron cannot run on the nth day of the week of a month", ErrNotRepresentable)
+		}
+		if err := r.plainMonthlyDay(); err != nil {
+			return "", err
+		}
+		day = strconv.Itoa(int(*r.MonthlyDay))
+		if r.RecurrencePatternCode == FrequencyYearly {
+			if r.RecurEvery != 1 {
+				return "", fmt.Errorf("%w: cron cannot run every %d years", ErrNotRepresentable, r.RecurEvery)
+			}
+			if r.YearlyMonth == nil {
+				return "", fmt.Errorf("%w: yearly recurrence has no YearlyMonth", ErrNotRepresentable)
+			}
+			month = strconv.Itoa(int(*r.YearlyMonth))
+		} else if r.RecurEvery > 1 {
+			step := int(r.RecurEvery)
+			if 12%step != 0 {
+				return "", fmt.Errorf("%w: cron cannot run every %d months", ErrNotRepresentable, r.RecurEvery)
+			}
+			first := (int(r.StartDate.Month())-1)%step + 1
+			month = fmt.Sprintf("%d-12/%d", first, step)
+			if first == 1 {
+				month = fmt.Sprintf("*/%d", step)
+			}
+		}
+	default:
+		return "", fmt.Errorf("%w: unknown recurrence pattern code %q", ErrNotRepresentable, r.RecurrencePatternCode)
+	}
+	return fmt.Sprintf("%d %d %s %s %s", r.StartDate.Minute(), r.StartDate.Hour(), day, month, weekday), nil
+}
+
+// formatCronList joins sorted values with commas, writing runs of three or more as ranges
+func formatCronList(values []int) string {
+	var items []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			items = append(items, fmt.Sprintf("%d-%d", values[i], values[j]))
+		case j > i:
+			items = append(items, strconv.Itoa(values[i]), strconv.Itoa(values[j]))
+		default:
+			items = append(items, strconv.Itoa(values[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,145 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	start := time.Date(2016, 2, 10, 0, 0, 0, 0, time.UTC) // Wednesday
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2016, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	expressions := []struct {
+		expr     string
+		expected []Recurrence
+	}{
+		{"30 9 * * *", []Recurrence{{StartDate: at(2, 10, 9, 30), RecurrencePatternCode: "D", RecurEvery: 1}}},
+		{"@daily", []Recurrence{{StartDate: at(2, 10, 0, 0), RecurrencePatternCode: "D", RecurEvery: 1}}},
+		{"0 9,17 * * *", []Recurrence{
+			{StartDate: at(2, 10, 9, 0), RecurrencePatternCode: "D", RecurEvery: 1},
+			{StartDate: at(2, 10, 17, 0), RecurrencePatternCode: "D", RecurEvery: 1},
+		}},
+		{"0 8 * * 1-5", []Recurrence{{StartDate: at(2, 10, 8, 0), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(62)}}},
+		{"15 6 * * sat,SUN", []Recurrence{{StartDate: at(2, 10, 6, 15), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(64 + 1)}}},
+		{"0 0 * * 5-7", []Recurrence{{StartDate: at(2, 10, 0, 0), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(64 + 2 + 1)}}},
+		{"@weekly", []Recurrence{{StartDate: at(2, 10, 0, 0), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(64)}}},
+		{"0 0 1-31 * 0-6", []Recurrence{{StartDate: at(2, 10, 0, 0), RecurrencePatternCode: "D", RecurEvery: 1}}},
+		{"@monthly", []Recurrence{{StartDate: at(2, 10, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(1)}}},
+		{"0 12 1,15 * *", []Recurrence{
+			{StartDate: at(2, 10, 12, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(1)},
+			{StartDate: at(2, 10, 12, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15)},
+		}},
+		{"0 12 1 */3 *", []Recurrence{{StartDate: at(4, 1, 12, 0), RecurrencePatternCode: "M", RecurEvery: 3, MonthlyDay: int16Ptr(1)}}},
+		{"0 12 1 2-12/6 *", []Recurrence{{StartDate: at(2, 10, 12, 0), RecurrencePatternCode: "M", RecurEvery: 6, MonthlyDay: int16Ptr(1)}}},
+		{"0 0 25 DEC *", []Recurrence{{StartDate: at(2, 10, 0, 0), RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(12), MonthlyDay: int16Ptr(25)}}},
+		{"0 0 30 1,2,5 *", []Recurrence{
+			{StartDate: at(2, 10, 0, 0), RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(1), MonthlyDay: int16Ptr(30)},
+			{StartDate: at(2, 10, 0, 0), RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(5), MonthlyDay: int16Ptr(30)},
+		}},
+		{"0 0 8,9 * tue", []Recurrence{ // both day fields restricted runs on either
+			{StartDate: at(2, 10, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(8)},
+			{StartDate: at(2, 10, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(9)},
+			{StartDate: at(2, 10, 0, 0), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(16)},
+		}},
+		{"0 8 1 * mon", []Recurrence{
+			{StartDate: at(2, 10, 8, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(1)},
+			{StartDate: at(2, 10, 8, 0), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(32)},
+		}},
+	}
+	for _, expression := range expressions {
+		actual, err := ParseCron(expression.expr, start)
+		if err != nil {
+			t.Error(expression.expr, err)
+			continue
+		}
+		if len(actual) != len(expression.expected) {
+			t.Errorf("%s: expected %d recurrences vs actual %d", expression.expr, len(expression.expected), len(actual))
+			continue
+		}
+		for i := range actual {
+			compareRecurrences(t, &expression.expected[i], &actual[i], fmt.Sprintf("%s[%d]", expression.expr, i))
+		}
+	}
+
+	if hourly, err := ParseCron("@hourly", start); err != nil || len(hourly) != 24 || hourly[23].StartDate != at(2, 10, 23, 0) {
+		t.Error("expected a daily recurrence per hour", err)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	start := time.Date(2016, 2, 10, 0, 0, 0, 0, time.UTC)
+	unrepresentable := []string{
+		"0 0 * 6-8 *",
+		"0 0 * 12 1",
+		"0 0 */2 * 1",
+	}
+	for _, expr := range unrepresentable {
+		if _, err := ParseCron(expr, start); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be unrepresentable: %v", expr, err)
+		}
+	}
+
+	invalid := []string{"", "@reboot", "0 0 * *", "0 0 * * * *", "60 0 * * *", "0 24 * * *", "0 0 0 * *", "0 0 * 13 *", "0 0 * * 8",
+		"0 0 * * MON-SUNDAY", "*/0 0 * * *", "5-1 0 * * *", "0 0 31 2 *"}
+	for _, expr := range invalid {
+		if _, err := ParseCron(expr, start); err == nil || errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be invalid: %v", expr, err)
+		}
+	}
+}
+
+func TestCron(t *testing.T) {
+	start := time.Date(2016, 2, 10, 9, 30, 0, 0, time.UTC)
+	recurrences := []struct {
+		recurrence Recurrence
+		expr       string
+	}{
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1}, "30 9 * * *"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)}, "30 9 * * 1-5"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(32 + 8 + 2)}, "30 9 * * 1,3,5"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(64 + 32 + 16 + 1)}, "30 9 * * 0-2,6"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(127)}, "30 9 * * *"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15)}, "30 9 15 * *"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 3, MonthlyDay: int16Ptr(15)}, "30 9 15 2-12/3 *"},
+		{Recurrence{StartDate: start.AddDate(0, -1, 0), RecurrencePatternCode: "M", RecurEvery: 6, MonthlyDay: int16Ptr(1)}, "30 9 1 */6 *"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(7), MonthlyDay: int16Ptr(4)}, "30 9 4 7 *"},
+	}
+	for _, recurrence := range recurrences {
+		actual, err := recurrence.recurrence.Cron()
+		if err != nil {
+			t.Error(recurrence.expr, err)
+		} else if actual != recurrence.expr {
+			t.Errorf("expected %s vs actual %s", recurrence.expr, actual)
+		}
+
+		// the expression must fire on the same dates as the recurrence it came from
+		parsed, err := ParseCron(actual, recurrence.recurrence.StartDate)
+		if err != nil || len(parsed) != 1 {
+			t.Error(recurrence.expr, err)
+			continue
+		}
+		periodEnd := start.AddDate(2, 0, 0)
+		compareTimes(t, recurrence.recurrence.GetOccurrences(start, periodEnd), parsed[0].GetOccurrences(start, periodEnd), recurrence.expr)
+	}
+
+	unrepresentable := []Recurrence{
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 2},
+		{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 3, WeeklyDaysIncluded: int16Ptr(32)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 5, MonthlyDay: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(2), MonthlyWeekOfMonth: int16Ptr(2)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-1)},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 2, YearlyMonth: int16Ptr(7), MonthlyDay: int16Ptr(4)},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, EndByDate: timePtr(start.AddDate(1, 0, 0))},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, NumberOfOccurrences: int16Ptr(5)},
+		{StartDate: start.Add(15 * time.Second), RecurrencePatternCode: "D", RecurEvery: 1},
+		{StartDate: start, RecurrencePatternCode: "X", RecurEvery: 1},
+	}
+	for i, r := range unrepresentable {
+		if _, err := r.Cron(); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected recurrence %d to be unrepresentable: %v", i, err)
+		}
+	}
+}
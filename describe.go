@@ -0,0 +1,195 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Catalog holds the words and message templates Describe uses for one language. Templates are fmt formats and may
+// use explicit argument indexes such as %[2]s to put their arguments in the order the language needs
+type Catalog struct {
+	Days              [7]string        // names of the days of the week, Sunday first
+	Months            [12]string       // names of the months, January first
+	Weeks             [5]string        // MonthlyWeekOfMonth 1 to 5, e.g. "first"
+	LastWeek          string           // MonthlyWeekOfMonth 54, e.g. "last"
+	ListSeparator     string           // between items of a list but the last two, e.g. ", "
+	ListLastSeparator string           // between the last two items of a list, e.g. " and "
+	Ordinal           func(int) string // day of the month, e.g. "2nd"
+
+	EveryDay       string // e.g. "Every day"
+	EveryNDays     string // RecurEvery, e.g. "Every %d days"
+	EveryWeekday   string // e.g. "Every weekday"
+	EveryNWeekdays string // RecurEvery, e.g. "Every %d weekdays"
+	EveryWeek      string // days of the week, e.g. "Every week on %s"
+	EveryNWeeks    string // RecurEvery and days of the week, e.g. "Every %d weeks on %s"
+	EveryMonth     string // day, e.g. "The %s of every month"
+	EveryNMonths   string // RecurEvery and day, e.g. "The %[2]s of every %[1]d months"
+	EveryYear      string // day and month, e.g. "The %s of %s every year"
+	EveryNYears    string // RecurEvery, day and month, e.g. "The %[2]s of %[3]s every %[1]d years"
+
+	LastDay        string // MonthlyDay -1, e.g. "last day"
+	NthLastDay     string // ordinal of a negative MonthlyDay, e.g. "%s to last day"
+	NearestWeekday string // day of the month, e.g. "weekday nearest the %s"
+	NthWeekday     string // week and day of the week, e.g. "%s %s"
+
+	Once  string // description, e.g. "%s, once"
+	Times string // description and NumberOfOccurrences, e.g. "%s, %d times"
+	Until string // description and EndByDate, e.g. "%s, until %s"
+	Date  string // day, month name and year, e.g. "%d %s %d"
+}
+
+// English is the default Catalog
+var English = &Catalog{
+	Days:              [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	Months:            [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	Weeks:             [5]string{"first", "second", "third", "fourth", "fifth"},
+	LastWeek:          "last",
+	ListSeparator:     ", ",
+	ListLastSeparator: " and ",
+	Ordinal:           englishOrdinal,
+
+	EveryDay:       "Every day",
+	EveryNDays:     "Every %d days",
+	EveryWeekday:   "Every weekday",
+	EveryNWeekdays: "Every %d weekdays",
+	EveryWeek:      "Every week on %s",
+	EveryNWeeks:    "Every %d weeks on %s",
+	EveryMonth:     "The %s of every month",
+	EveryNMonths:   "The %[2]s of every %[1]d months",
+	EveryYear:      "The %s of %s every year",
+	EveryNYears:    "The %[2]s of %[3]s every %[1]d years",
+
+	LastDay:        "last day",
+	NthLastDay:     "%s to last day",
+	NearestWeekday: "weekday nearest the %s",
+	NthWeekday:     "%s %s",
+
+	Once:  "%s, once",
+	Times: "%s, %d times",
+	Until: "%s, until %s",
+	Date:  "%d %s %d",
+}
+
+// Describe returns the recurrence as a sentence in the language of c, or in English when c is nil, e.g. "Every 2
+// weeks on Monday, Wednesday and Friday, until 30 June 2026" or "The last Thursday of November every year"
+func (r *Recurrence) Describe(c *Catalog) (string, error) {
+	if c == nil {
+		c = English
+	}
+	if r.RecurEvery < 1 {
+		return "", fmt.Errorf("interval must be at least 1, got %d", r.RecurEvery)
+	}
+	every := int(r.RecurEvery)
+	var description string
+	switch r.RecurrencePatternCode {
+	case FrequencyDaily:
+		switch {
+		case r.DailyIsOnlyWeekday != nil && *r.DailyIsOnlyWeekday:
+			description = c.every(every, c.EveryWeekday, c.EveryNWeekdays)
+		default:
+			description = c.every(every, c.EveryDay, c.EveryNDays)
+		}
+	case FrequencyWeekly:
+		var days []string
+		for day := range r.Weekdays().Iter() {
+			days = append(days, c.Days[day])
+		}
+		if len(days) == 0 {
+			return "", fmt.Errorf("weekly recurrence includes no days")
+		}
+		description = c.every(every, c.EveryWeek, c.EveryNWeeks, c.list(days))
+	case FrequencyMonthly, FrequencyYearly:
+		day, err := c.monthDay(r)
+		if err != nil {
+			return "", err
+		}
+		if r.RecurrencePatternCode == FrequencyMonthly {
+			description = c.every(every, c.EveryMonth, c.EveryNMonths, day)
+		} else if r.YearlyMonth == nil || *r.YearlyMonth < 1 || *r.YearlyMonth > 12 {
+			return "", fmt.Errorf("yearly recurrence has no valid YearlyMonth")
+		} else {
+			description = c.every(every, c.EveryYear, c.EveryNYears, day, c.Months[*r.YearlyMonth-1])
+		}
+	default:
+		return "", fmt.Errorf("unknown recurrence pattern code %q", r.RecurrencePatternCode)
+	}
+
+	switch {
+	case r.NumberOfOccurrences != nil && *r.NumberOfOccurrences == 1:
+		description = fmt.Sprintf(c.Once, description)
+	case r.NumberOfOccurrences != nil:
+		description = fmt.Sprintf(c.Times, description, *r.NumberOfOccurrences)
+	case r.EndByDate != nil:
+		description = fmt.Sprintf(c.Until, description, c.date(*r.EndByDate))
+	}
+	return description, nil
+}
+
+// every formats the singular template when every is 1 and otherwise the plural template with every as its first argument
+func (c *Catalog) every(every int, singular, plural string, args ...interface{}) string {
+	if every == 1 {
+		return fmt.Sprintf(singular, args...)
+	}
+	return fmt.Sprintf(plural, append([]interface{}{every}, args...)...)
+}
+
+// monthDay describes the day of the month or nth day of the week of a monthly or yearly recurrence
+func (c *Catalog) monthDay(r *Recurrence) (string, error) {
+	switch {
+	case r.MonthlyDay != nil:
+		var day string
+		switch monthlyDay := int(*r.MonthlyDay); {
+		case monthlyDay > 0:
+			day = c.Ordinal(monthlyDay)
+		case monthlyDay == -1:
+			day = c.LastDay
+		case monthlyDay < -1:
+			day = fmt.Sprintf(c.NthLastDay, c.Ordinal(-monthlyDay))
+		default:
+			return "", fmt.Errorf("day of month must not be 0")
+		}
+		if r.MonthlyNearestWeekday != nil && *r.MonthlyNearestWeekday {
+			day = fmt.Sprintf(c.NearestWeekday, day)
+		}
+		return day, nil
+	case r.MonthlyDayOfWeek != nil && r.MonthlyWeekOfMonth != nil:
+		dayOfWeek, week := int(*r.MonthlyDayOfWeek), int(*r.MonthlyWeekOfMonth)
+		if dayOfWeek < 0 || dayOfWeek > 6 {
+			return "", fmt.Errorf("day of week %d is out of range", dayOfWeek)
+		}
+		switch {
+		case week == 54:
+			return fmt.Sprintf(c.NthWeekday, c.LastWeek, c.Days[dayOfWeek]), nil
+		case week >= 1 && week <= 5:
+			return fmt.Sprintf(c.NthWeekday, c.Weeks[week-1], c.Days[dayOfWeek]), nil
+		}
+		return "", fmt.Errorf("week of month %d is out of range", week)
+	}
+	return "", fmt.Errorf("recurrence has neither MonthlyDay nor MonthlyDayOfWeek and MonthlyWeekOfMonth")
+}
+
+func (c *Catalog) list(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], c.ListSeparator) + c.ListLastSeparator + items[len(items)-1]
+}
+
+func (c *Catalog) date(t time.Time) string {
+	return fmt.Sprintf(c.Date, t.Day(), c.Months[t.Month()-1], t.Year())
+}
+
+func englishOrdinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,115 @@
+package calendar
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	descriptions := []struct {
+		recurrence Recurrence
+		expected   string
+	}{
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1}, "Every day"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 3, NumberOfOccurrences: int16Ptr(10)}, "Every 3 days, 10 times"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)}, "Every weekday"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 4, DailyIsOnlyWeekday: boolPtr(true)}, "Every 4 weekdays"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(64)}, "Every week on Sunday"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(4 + 16)}, "Every week on Tuesday and Thursday"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(32 + 8 + 2),
+			EndByDate: timePtr(time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC))}, "Every 2 weeks on Monday, Wednesday and Friday, until 30 June 2026"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(1)}, "The 1st of every month"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 3, MonthlyDay: int16Ptr(22)}, "The 22nd of every 3 months"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(13), NumberOfOccurrences: int16Ptr(1)}, "The 13th of every month, once"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-1)}, "The last day of every month"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-3)}, "The 3rd to last day of every month"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15), MonthlyNearestWeekday: boolPtr(true)}, "The weekday nearest the 15th of every month"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(2), MonthlyWeekOfMonth: int16Ptr(2)}, "The second Tuesday of every month"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 2, MonthlyDayOfWeek: int16Ptr(5), MonthlyWeekOfMonth: int16Ptr(54)}, "The last Friday of every 2 months"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54)}, "The last Thursday of November every year"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 4, YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(29)}, "The 29th of February every 4 years"},
+	}
+	for _, description := range descriptions {
+		actual, err := description.recurrence.Describe(nil)
+		if err != nil {
+			t.Error(description.expected, err)
+		} else if actual != description.expected {
+			t.Errorf("expected %q vs actual %q", description.expected, actual)
+		}
+	}
+
+	invalid := []Recurrence{
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 0},
+		{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(0)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(0)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(7), MonthlyWeekOfMonth: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(1), MonthlyWeekOfMonth: int16Ptr(6)},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, MonthlyDay: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "X", RecurEvery: 1},
+	}
+	for i, r := range invalid {
+		if _, err := r.Describe(nil); err == nil {
+			t.Errorf("expected recurrence %d to be invalid", i)
+		}
+	}
+}
+
+func TestDescribeCatalog(t *testing.T) {
+	german := &Catalog{
+		Days:              [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		Months:            [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		Weeks:             [5]string{"ersten", "zweiten", "dritten", "vierten", "fünften"},
+		LastWeek:          "letzten",
+		ListSeparator:     ", ",
+		ListLastSeparator: " und ",
+		Ordinal:           func(n int) string { return fmt.Sprintf("%d.", n) },
+		EveryDay:          "Jeden Tag",
+		EveryNDays:        "Alle %d Tage",
+		EveryWeekday:      "Jeden Werktag",
+		EveryNWeekdays:    "Alle %d Werktage",
+		EveryWeek:         "Jede Woche am %s",
+		EveryNWeeks:       "Alle %d Wochen am %s",
+		EveryMonth:        "Am %s jedes Monats",
+		EveryNMonths:      "Am %[2]s alle %[1]d Monate",
+		EveryYear:         "Am %s im %s jedes Jahres",
+		EveryNYears:       "Am %[2]s im %[3]s alle %[1]d Jahre",
+		LastDay:           "letzten Tag",
+		NthLastDay:        "%s letzten Tag",
+		NearestWeekday:    "Werktag am nächsten zum %s",
+		NthWeekday:        "%s %s",
+		Once:              "%s, einmal",
+		Times:             "%s, %d Mal",
+		Until:             "%s, bis %s",
+		Date:              "%d. %s %d",
+	}
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	descriptions := []struct {
+		recurrence Recurrence
+		expected   string
+	}{
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(32 + 8 + 2),
+			EndByDate: timePtr(time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC))}, "Alle 2 Wochen am Montag, Mittwoch und Freitag, bis 30. Juni 2026"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54)}, "Am letzten Donnerstag im November jedes Jahres"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 3, MonthlyDay: int16Ptr(15), NumberOfOccurrences: int16Ptr(4)}, "Am 15. alle 3 Monate, 4 Mal"},
+	}
+	for _, description := range descriptions {
+		actual, err := description.recurrence.Describe(german)
+		if err != nil {
+			t.Error(description.expected, err)
+		} else if actual != description.expected {
+			t.Errorf("expected %q vs actual %q", description.expected, actual)
+		}
+	}
+}
+
+func TestEnglishOrdinal(t *testing.T) {
+	ordinals := map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 21: "21st", 22: "22nd", 23: "23rd", 31: "31st"}
+	for n, expected := range ordinals {
+		if actual := englishOrdinal(n); actual != expected {
+			t.Errorf("expected %s vs actual %s", expected, actual)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package calendar
+
+import "time"
+
+// Expander is anything that expands into occurrence dates, such as a *Recurrence or a *Series
+type Expander interface {
+	GetOccurrences(timePeriodStart, timePeriodEnd time.Time) []time.Time
+}
+
+// OccurrenceDiff is the change in the occurrences within a time period when a recurrence is edited, returned by Diff
+type OccurrenceDiff struct {
+	Added     []time.Time // occurrences of the edited recurrence only
+	Removed   []time.Time // occurrences of the original recurrence only
+	Unchanged []time.Time // occurrences of both
+}
+
+// Diff compares the occurrences of an original and an edited recurrence within the time period, whatever their
+// pattern codes. Occurrences are compared by date, so a change to the time of day of the StartDate leaves them
+// Unchanged. Pass a *Series to take its ExceptionDates into account
+func Diff(original, edited Expander, timePeriodStart, timePeriodEnd time.Time) *OccurrenceDiff {
+	before := original.GetOccurrences(timePeriodStart, timePeriodEnd)
+	after := edited.GetOccurrences(timePeriodStart, timePeriodEnd)
+	diff := &OccurrenceDiff{Added: []time.Time{}, Removed: []time.Time{}, Unchanged: []time.Time{}}
+	for len(before) > 0 || len(after) > 0 {
+		switch {
+		case len(after) == 0 || len(before) > 0 && before[0].Before(after[0]):
+			diff.Removed, before = append(diff.Removed, before[0]), before[1:]
+		case len(before) == 0 || after[0].Before(before[0]):
+			diff.Added, after = append(diff.Added, after[0]), after[1:]
+		default:
+			diff.Unchanged, before, after = append(diff.Unchanged, before[0]), before[1:], after[1:]
+		}
+	}
+	return diff
+}
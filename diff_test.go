@@ -0,0 +1,45 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	start := time.Date(2016, 1, 4, 9, 30, 0, 0, time.UTC)                                                                  // a Monday
+	original := &Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(42)} // MWF
+	edited := &Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 2}
+	diff := Diff(original, edited, time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 10, 0, 0, 0, 0, time.UTC))
+	compareTimes(t, []time.Time{time.Date(2016, 1, 10, 0, 0, 0, 0, time.UTC)}, diff.Added, "TestDiff, added")
+	compareTimes(t, []time.Time{time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC)},
+		diff.Unchanged, "TestDiff, unchanged")
+	if len(diff.Removed) != 0 {
+		t.Error("expected nothing removed", diff.Removed)
+	}
+
+	s := &Series{Recurrence: *original, ExceptionDates: []time.Time{time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC)}}
+	diff = Diff(original, s, time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 10, 0, 0, 0, 0, time.UTC))
+	compareTimes(t, []time.Time{time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC)}, diff.Removed, "TestDiff, exception removed")
+
+	recurrences := variedRecurrences()
+	for i := range recurrences {
+		a, b := &recurrences[i], &recurrences[(i*7+3)%len(recurrences)]
+		timePeriodStart, timePeriodEnd := a.startDate(), a.startDate().AddDate(2, 0, 0)
+		diff := Diff(a, b, timePeriodStart, timePeriodEnd)
+		for date := timePeriodStart; !date.After(timePeriodEnd); date = date.AddDate(0, 0, 1) {
+			inA, inB := a.IsValidOccurrenceDate(date), b.IsValidOccurrenceDate(date)
+			if contains(diff.Removed, date) != (inA && !inB) || contains(diff.Added, date) != (!inA && inB) || contains(diff.Unchanged, date) != (inA && inB) {
+				t.Fatalf("recurrences %d and %d: unexpected diff on %s", i, (i*7+3)%len(recurrences), date)
+			}
+		}
+	}
+}
+
+func contains(dates []time.Time, date time.Time) bool {
+	for _, d := range dates {
+		if d.Equal(date) {
+			return true
+		}
+	}
+	return false
+}
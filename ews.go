@@ -0,0 +1,380 @@
+package calendar
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EWSTypesNamespace is the Exchange Web Services types namespace that recurrence elements belong to
+const EWSTypesNamespace = "http://schemas.microsoft.com/exchange/services/2006/types"
+
+// EWSRecurrence is the Exchange Web Services Recurrence element. Exactly one pattern and one range element is set
+type EWSRecurrence struct {
+	XMLName                   xml.Name                      `xml:"http://schemas.microsoft.com/exchange/services/2006/types Recurrence"`
+	RelativeYearlyRecurrence  *EWSRelativeYearlyRecurrence  `xml:"RelativeYearlyRecurrence"`
+	AbsoluteYearlyRecurrence  *EWSAbsoluteYearlyRecurrence  `xml:"AbsoluteYearlyRecurrence"`
+	RelativeMonthlyRecurrence *EWSRelativeMonthlyRecurrence `xml:"RelativeMonthlyRecurrence"`
+	AbsoluteMonthlyRecurrence *EWSAbsoluteMonthlyRecurrence `xml:"AbsoluteMonthlyRecurrence"`
+	WeeklyRecurrence          *EWSWeeklyRecurrence          `xml:"WeeklyRecurrence"`
+	DailyRecurrence           *EWSDailyRecurrence           `xml:"DailyRecurrence"`
+	NoEndRecurrence           *EWSNoEndRecurrence           `xml:"NoEndRecurrence"`
+	EndDateRecurrence         *EWSEndDateRecurrence         `xml:"EndDateRecurrence"`
+	NumberedRecurrence        *EWSNumberedRecurrence        `xml:"NumberedRecurrence"`
+}
+
+// EWSRelativeYearlyRecurrence recurs on the DayOfWeekIndex DaysOfWeek of Month every year (e.g. last Thursday of November)
+type EWSRelativeYearlyRecurrence struct {
+	DaysOfWeek     string `xml:"DaysOfWeek"`
+	DayOfWeekIndex string `xml:"DayOfWeekIndex"`
+	Month          string `xml:"Month"`
+}
+
+// EWSAbsoluteYearlyRecurrence recurs on DayOfMonth of Month every year
+type EWSAbsoluteYearlyRecurrence struct {
+	DayOfMonth int    `xml:"DayOfMonth"`
+	Month      string `xml:"Month"`
+}
+
+// EWSRelativeMonthlyRecurrence recurs on the DayOfWeekIndex DaysOfWeek every Interval months
+type EWSRelativeMonthlyRecurrence struct {
+	Interval       int    `xml:"Interval"`
+	DaysOfWeek     string `xml:"DaysOfWeek"`
+	DayOfWeekIndex string `xml:"DayOfWeekIndex"`
+}
+
+// EWSAbsoluteMonthlyRecurrence recurs on DayOfMonth every Interval months
+type EWSAbsoluteMonthlyRecurrence struct {
+	Interval   int `xml:"Interval"`
+	DayOfMonth int `xml:"DayOfMonth"`
+}
+
+// EWSWeeklyRecurrence recurs on the space separated DaysOfWeek every Interval weeks
+type EWSWeeklyRecurrence struct {
+	Interval       int    `xml:"Interval"`
+	DaysOfWeek     string `xml:"DaysOfWeek"`
+	FirstDayOfWeek string `xml:"FirstDayOfWeek,omitempty"`
+}
+
+// EWSDailyRecurrence recurs every Interval days
+type EWSDailyRecurrence struct {
+	Interval int `xml:"Interval"`
+}
+
+// EWSNoEndRecurrence is a range with no end
+type EWSNoEndRecurrence struct {
+	StartDate string `xml:"StartDate"`
+}
+
+// EWSEndDateRecurrence is a range ending on EndDate
+type EWSEndDateRecurrence struct {
+	StartDate string `xml:"StartDate"`
+	EndDate   string `xml:"EndDate"`
+}
+
+// EWSNumberedRecurrence is a range ending after NumberOfOccurrences
+type EWSNumberedRecurrence struct {
+	StartDate           string `xml:"StartDate"`
+	NumberOfOccurrences int    `xml:"NumberOfOccurrences"`
+}
+
+const ewsDateLayout = "2006-01-02"
+
+var ewsDayOfWeekIndexes = map[int16]string{1: "First", 2: "Second", 3: "Third", 4: "Fourth", 54: "Last"}
+
+// ToEWS converts the recurrence to an Exchange Web Services Recurrence element. EWS yearly patterns always recur
+// every year and there is no daily weekday-only pattern, so those are written the way Exchange does where possible
+// and otherwise return ErrNotRepresentable
+func (r *Recurrence) ToEWS() (*EWSRecurrence, error) {
+	if r.RecurEvery < 1 {
+		return nil, fmt.Errorf("%w: ews interval must be at least 1, got %d", ErrNotRepresentable, r.RecurEvery)
+	}
+	e := &EWSRecurrence{}
+	interval := int(r.RecurEvery)
+	switch r.RecurrencePatternCode {
+	case FrequencyDaily:
+		if r.DailyIsOnlyWeekday != nil && *r.DailyIsOnlyWeekday {
+			if r.RecurEvery != 1 {
+				return nil, fmt.Errorf("%w: ews cannot recur every %d weekdays", ErrNotRepresentable, r.RecurEvery)
+			}
+			e.WeeklyRecurrence = &EWSWeeklyRecurrence{Interval: 1, DaysOfWeek: ewsDaysOfWeek(32 + 16 + 8 + 4 + 2), FirstDayOfWeek: "Sunday"}
+		} else {
+			e.DailyRecurrence = &EWSDailyRecurrence{Interval: interval}
+		}
+	case FrequencyWeekly:
+		weeklyDaysIncluded := r.Weekdays().Bitmask()
+		e.WeeklyRecurrence = &EWSWeeklyRecurrence{Interval: interval, DaysOfWeek: ewsDaysOfWeek(weeklyDaysIncluded), FirstDayOfWeek: "Sunday"}
+	case FrequencyMonthly:
+		switch {
+		case r.MonthlyDay != nil:
+			if err := r.plainMonthlyDay(); err != nil {
+				return nil, err
+			}
+			e.AbsoluteMonthlyRecurrence = &EWSAbsoluteMonthlyRecurrence{Interval: interval, DayOfMonth: int(*r.MonthlyDay)}
+		case r.MonthlyDayOfWeek != nil && r.MonthlyWeekOfMonth != nil:
+			index, err := ewsDayOfWeekIndex(*r.MonthlyWeekOfMonth)
+			if err != nil {
+				return nil, err
+			}
+			e.RelativeMonthlyRecurrence = &EWSRelativeMonthlyRecurrence{Interval: interval, DaysOfWeek: time.Weekday(*r.MonthlyDayOfWeek).String(), DayOfWeekIndex: index}
+		default:
+			return nil, fmt.Errorf("%w: recurrence has neither MonthlyDay nor MonthlyDayOfWeek and MonthlyWeekOfMonth", ErrNotRepresentable)
+		}
+	case FrequencyYearly:
+		if r.RecurEvery != 1 {
+			return nil, fmt.Errorf("%w: ews yearly recurrences cannot recur every %d years", ErrNotRepresentable, r.RecurEvery)
+		}
+		if r.YearlyMonth == nil || *r.YearlyMonth < 1 || *r.YearlyMonth > 12 {
+			return nil, fmt.Errorf("%w: yearly recurrence has no valid YearlyMonth", ErrNotRepresentable)
+		}
+		month := time.Month(*r.YearlyMonth).String()
+		switch {
+		case r.MonthlyDay != nil:
+			if err := r.plainMonthlyDay(); err != nil {
+				return nil, err
+			}
+			e.AbsoluteYearlyRecurrence = &EWSAbsoluteYearlyRecurrence{DayOfMonth: int(*r.MonthlyDay), Month: month}
+		case r.MonthlyDayOfWeek != nil && r.MonthlyWeekOfMonth != nil:
+			index, err := ewsDayOfWeekIndex(*r.MonthlyWeekOfMonth)
+			if err != nil {
+				return nil, err
+			}
+			e.RelativeYearlyRecurrence = &EWSRelativeYearlyRecurrence{DaysOfWeek: time.Weekday(*r.MonthlyDayOfWeek).String(), DayOfWeekIndex: index, Month: month}
+		default:
+			return nil, fmt.Errorf("%w: recurrence has neither MonthlyDay nor MonthlyDayOfWeek and MonthlyWeekOfMonth", ErrNotRepresentable)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown recurrence pattern code %q", ErrNotRepresentable, r.RecurrencePatternCode)
+	}
+
+	startDate := r.StartDate.Format(ewsDateLayout)
+	switch {
+	case r.NumberOfOccurrences != nil:
+		e.NumberedRecurrence = &EWSNumberedRecurrence{StartDate: startDate, NumberOfOccurrences: int(*r.NumberOfOccurrences)}
+	case r.EndByDate != nil:
+		e.EndDateRecurrence = &EWSEndDateRecurrence{StartDate: startDate, EndDate: r.EndByDate.Format(ewsDateLayout)}
+	default:
+		e.NoEndRecurrence = &EWSNoEndRecurrence{StartDate: startDate}
+	}
+	return e, nil
+}
+
+// FromEWS converts an Exchange Web Services Recurrence element to a Recurrence. Regeneration patterns and relative
+// patterns on Day, Weekday or WeekendDay have no equivalent and return ErrNotRepresentable
+func FromEWS(e *EWSRecurrence) (*Recurrence, error) {
+	patterns := countSet(e.RelativeYearlyRecurrence != nil, e.AbsoluteYearlyRecurrence != nil, e.RelativeMonthlyRecurrence != nil,
+		e.AbsoluteMonthlyRecurrence != nil, e.WeeklyRecurrence != nil, e.DailyRecurrence != nil)
+	if patterns > 1 {
+		return nil, fmt.Errorf("ews recurrence has %d pattern elements, expected one", patterns)
+	}
+	if ranges := countSet(e.NoEndRecurrence != nil, e.EndDateRecurrence != nil, e.NumberedRecurrence != nil); ranges > 1 {
+		return nil, fmt.Errorf("ews recurrence has %d range elements, expected one", ranges)
+	}
+	r := &Recurrence{RecurEvery: 1}
+	switch {
+	case e.DailyRecurrence != nil:
+		r.RecurrencePatternCode = FrequencyDaily
+		if err := setEWSInterval(r, e.DailyRecurrence.Interval); err != nil {
+			return nil, err
+		}
+	case e.WeeklyRecurrence != nil:
+		days, err := parseEWSDaysOfWeek(e.WeeklyRecurrence.DaysOfWeek)
+		if err != nil {
+			return nil, err
+		}
+		if err := setEWSInterval(r, e.WeeklyRecurrence.Interval); err != nil {
+			return nil, err
+		}
+		if e.WeeklyRecurrence.FirstDayOfWeek != "" && r.RecurEvery > 1 {
+			firstDay, err := parseEWSDayOfWeek(e.WeeklyRecurrence.FirstDayOfWeek)
+			if err != nil {
+				return nil, err
+			}
+			if !sundayWeeksMatch(days, firstDay) {
+				return nil, fmt.Errorf("%w: weeks starting on %s group these days differently than weeks starting on Sunday", ErrNotRepresentable, firstDay)
+			}
+		}
+		r.RecurrencePatternCode = FrequencyWeekly
+		r.WeeklyDaysIncluded = &days
+	case e.AbsoluteMonthlyRecurrence != nil:
+		r.RecurrencePatternCode = FrequencyMonthly
+		if err := setEWSInterval(r, e.AbsoluteMonthlyRecurrence.Interval); err != nil {
+			return nil, err
+		}
+		if err := setEWSDayOfMonth(r, e.AbsoluteMonthlyRecurrence.DayOfMonth); err != nil {
+			return nil, err
+		}
+	case e.RelativeMonthlyRecurrence != nil:
+		r.RecurrencePatternCode = FrequencyMonthly
+		if err := setEWSInterval(r, e.RelativeMonthlyRecurrence.Interval); err != nil {
+			return nil, err
+		}
+		if err := setEWSRelativeDay(r, e.RelativeMonthlyRecurrence.DaysOfWeek, e.RelativeMonthlyRecurrence.DayOfWeekIndex); err != nil {
+			return nil, err
+		}
+	case e.AbsoluteYearlyRecurrence != nil:
+		r.RecurrencePatternCode = FrequencyYearly
+		if err := setEWSMonth(r, e.AbsoluteYearlyRecurrence.Month); err != nil {
+			return nil, err
+		}
+		if err := setEWSDayOfMonth(r, e.AbsoluteYearlyRecurrence.DayOfMonth); err != nil {
+			return nil, err
+		}
+	case e.RelativeYearlyRecurrence != nil:
+		r.RecurrencePatternCode = FrequencyYearly
+		if err := setEWSMonth(r, e.RelativeYearlyRecurrence.Month); err != nil {
+			return nil, err
+		}
+		if err := setEWSRelativeDay(r, e.RelativeYearlyRecurrence.DaysOfWeek, e.RelativeYearlyRecurrence.DayOfWeekIndex); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: ews recurrence has no supported pattern", ErrNotRepresentable)
+	}
+
+	var startDate string
+	var count int
+	switch {
+	case e.NoEndRecurrence != nil:
+		startDate = e.NoEndRecurrence.StartDate
+	case e.EndDateRecurrence != nil:
+		startDate = e.EndDateRecurrence.StartDate
+		endDate, err := parseEWSDate(e.EndDateRecurrence.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ews EndDate: %w", err)
+		}
+		r.EndByDate = &endDate
+	case e.NumberedRecurrence != nil:
+		startDate = e.NumberedRecurrence.StartDate
+		if e.NumberedRecurrence.NumberOfOccurrences < 1 || e.NumberedRecurrence.NumberOfOccurrences > 32767 {
+			return nil, fmt.Errorf("%w: ews NumberOfOccurrences %d is out of range", ErrNotRepresentable, e.NumberedRecurrence.NumberOfOccurrences)
+		}
+		count = e.NumberedRecurrence.NumberOfOccurrences
+	default:
+		return nil, fmt.Errorf("%w: ews recurrence has no supported range", ErrNotRepresentable)
+	}
+	var err error
+	if r.StartDate, err = parseEWSDate(startDate); err != nil {
+		return nil, fmt.Errorf("invalid ews StartDate: %w", err)
+	}
+	if count > 0 {
+		if err := r.SetNumberOfOccurrences(count); err != nil {
+			return nil, fmt.Errorf("invalid ews NumberOfOccurrences: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// countSet returns how many of the elements are set
+func countSet(set ...bool) int {
+	count := 0
+	for _, s := range set {
+		if s {
+			count++
+		}
+	}
+	return count
+}
+
+// parseEWSDate parses an xs:date, ignoring any time zone offset that follows the date
+func parseEWSDate(value string) (time.Time, error) {
+	if len(value) > len(ewsDateLayout) {
+		value = value[:len(ewsDateLayout)]
+	}
+	return time.Parse(ewsDateLayout, value)
+}
+
+func setEWSInterval(r *Recurrence, interval int) error {
+	if interval < 1 || interval > 32767 {
+		return fmt.Errorf("%w: ews Interval %d is out of range", ErrNotRepresentable, interval)
+	}
+	r.RecurEvery = int16(interval)
+	return nil
+}
+
+func setEWSDayOfMonth(r *Recurrence, dayOfMonth int) error {
+	if dayOfMonth < 1 || dayOfMonth > 31 {
+		return fmt.Errorf("%w: ews DayOfMonth %d is out of range", ErrNotRepresentable, dayOfMonth)
+	}
+	day := int16(dayOfMonth)
+	r.MonthlyDay = &day
+	return nil
+}
+
+func setEWSMonth(r *Recurrence, name string) error {
+	for month := time.January; month <= time.December; month++ {
+		if name == month.String() {
+			yearlyMonth := int16(month)
+			r.YearlyMonth = &yearlyMonth
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown ews Month %q", ErrNotRepresentable, name)
+}
+
+func setEWSRelativeDay(r *Recurrence, daysOfWeek, dayOfWeekIndex string) error {
+	day, err := parseEWSDayOfWeek(daysOfWeek)
+	if err != nil {
+		return err
+	}
+	for week, name := range ewsDayOfWeekIndexes {
+		if name == dayOfWeekIndex {
+			dayOfWeek := int16(day)
+			r.MonthlyDayOfWeek = &dayOfWeek
+			r.MonthlyWeekOfMonth = &week
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown ews DayOfWeekIndex %q", ErrNotRepresentable, dayOfWeekIndex)
+}
+
+func ewsDayOfWeekIndex(monthlyWeekOfMonth int16) (string, error) {
+	index, ok := ewsDayOfWeekIndexes[monthlyWeekOfMonth]
+	if !ok {
+		return "", fmt.Errorf("%w: ews has no DayOfWeekIndex for week %d of the month", ErrNotRepresentable, monthlyWeekOfMonth)
+	}
+	return index, nil
+}
+
+func parseEWSDayOfWeek(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if name == day.String() {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unsupported ews day of week %q", ErrNotRepresentable, name)
+}
+
+func ewsDaysOfWeek(weeklyDaysIncluded int16) string {
+	var names []string
+	for _, day := range getIncludedWeeklyDays(weeklyDaysIncluded) {
+		names = append(names, day.String())
+	}
+	return strings.Join(names, " ")
+}
+
+// parseEWSDaysOfWeek parses a space separated DaysOfWeek list, which may also use Day, Weekday and WeekendDay
+func parseEWSDaysOfWeek(daysOfWeek string) (int16, error) {
+	var weeklyDaysIncluded int16
+	for _, name := range strings.Fields(daysOfWeek) {
+		switch name {
+		case "Day":
+			weeklyDaysIncluded |= 127
+		case "Weekday":
+			weeklyDaysIncluded |= 32 + 16 + 8 + 4 + 2
+		case "WeekendDay":
+			weeklyDaysIncluded |= 64 + 1
+		default:
+			day, err := parseEWSDayOfWeek(name)
+			if err != nil {
+				return 0, err
+			}
+			weeklyDaysIncluded |= weekdayBit(day)
+		}
+	}
+	if weeklyDaysIncluded == 0 {
+		return 0, fmt.Errorf("%w: ews weekly recurrence has no DaysOfWeek", ErrNotRepresentable)
+	}
+	return weeklyDaysIncluded, nil
+}
@@ -0,0 +1,135 @@
+package calendar
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEWSGolden(t *testing.T) {
+	goldens := []struct {
+		file     string
+		expected Recurrence
+	}{
+		{"daily.xml", Recurrence{StartDate: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "D", RecurEvery: 3}},
+		{"weekly.xml", Recurrence{StartDate: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "W", RecurEvery: 2,
+			WeeklyDaysIncluded: int16Ptr(32 + 8 + 2), EndByDate: timePtr(time.Date(2016, 6, 30, 0, 0, 0, 0, time.UTC))}},
+		{"absolute_monthly.xml", Recurrence{StartDate: time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 1,
+			MonthlyDay: int16Ptr(15), NumberOfOccurrences: int16Ptr(12), EndByDate: timePtr(time.Date(2016, 12, 15, 0, 0, 0, 0, time.UTC))}},
+		{"relative_monthly.xml", Recurrence{StartDate: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 2,
+			MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(4), EndByDate: timePtr(time.Date(2016, 7, 1, 0, 0, 0, 0, time.UTC))}},
+		{"absolute_yearly.xml", Recurrence{StartDate: time.Date(2010, 2, 14, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "Y", RecurEvery: 1,
+			YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(14)}},
+		{"relative_yearly.xml", Recurrence{StartDate: time.Date(2016, 11, 24, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "Y", RecurEvery: 1,
+			YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54)}},
+	}
+	for _, golden := range goldens {
+		data, err := os.ReadFile(filepath.Join("testdata", "ews", golden.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var e EWSRecurrence
+		if err := xml.Unmarshal(data, &e); err != nil {
+			t.Fatal(golden.file, err)
+		}
+		actual, err := FromEWS(&e)
+		if err != nil {
+			t.Error(golden.file, err)
+			continue
+		}
+		compareRecurrences(t, &golden.expected, actual, golden.file)
+		if e.NumberedRecurrence != nil {
+			if count := len(actual.GetOccurrences(actual.StartDate, actual.StartDate.AddDate(50, 0, 0))); count != e.NumberedRecurrence.NumberOfOccurrences {
+				t.Errorf("%s: expected %d occurrences, got %d", golden.file, e.NumberedRecurrence.NumberOfOccurrences, count)
+			}
+		}
+
+		exported, err := golden.expected.ToEWS()
+		if err != nil {
+			t.Error(golden.file, err)
+			continue
+		}
+		output, err := xml.MarshalIndent(exported, "", "  ")
+		if err != nil {
+			t.Fatal(golden.file, err)
+		}
+		if string(output) != strings.TrimSpace(string(data)) {
+			t.Errorf("%s: expected\n%s\nactual\n%s", golden.file, data, output)
+		}
+	}
+}
+
+func TestFromEWSPrefixed(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "ews", "prefixed.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e EWSRecurrence
+	if err := xml.Unmarshal(data, &e); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := FromEWS(&e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Recurrence{StartDate: time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "W", RecurEvery: 1,
+		WeeklyDaysIncluded: int16Ptr(32 + 16 + 8 + 4 + 2), NumberOfOccurrences: int16Ptr(10), EndByDate: timePtr(time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC))}
+	compareRecurrences(t, &expected, actual, "TestFromEWSPrefixed")
+	if count := len(actual.GetOccurrences(actual.StartDate, actual.StartDate.AddDate(1, 0, 0))); count != 10 {
+		t.Errorf("expected 10 occurrences, got %d", count)
+	}
+}
+
+func TestToEWSErrors(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	recurrences := []Recurrence{
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 2, DailyIsOnlyWeekday: boolPtr(true)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(2), MonthlyWeekOfMonth: int16Ptr(5)},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 2, YearlyMonth: int16Ptr(1), MonthlyDay: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, MonthlyDay: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-1)},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(1), MonthlyDay: int16Ptr(15), MonthlyNearestWeekday: boolPtr(true)},
+		{StartDate: start, RecurrencePatternCode: "B", RecurEvery: 1},
+	}
+	for i, r := range recurrences {
+		if _, err := r.ToEWS(); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected recurrence %d to be unrepresentable: %v", i, err)
+		}
+	}
+}
+
+func TestFromEWSErrors(t *testing.T) {
+	noEnd := &EWSNoEndRecurrence{StartDate: "2016-01-01"}
+	recurrences := []EWSRecurrence{
+		{NoEndRecurrence: noEnd},
+		{DailyRecurrence: &EWSDailyRecurrence{Interval: 1}},
+		{DailyRecurrence: &EWSDailyRecurrence{Interval: 0}, NoEndRecurrence: noEnd},
+		{RelativeMonthlyRecurrence: &EWSRelativeMonthlyRecurrence{Interval: 1, DaysOfWeek: "Weekday", DayOfWeekIndex: "First"}, NoEndRecurrence: noEnd},
+		{RelativeYearlyRecurrence: &EWSRelativeYearlyRecurrence{DaysOfWeek: "Monday", DayOfWeekIndex: "Fifth", Month: "May"}, NoEndRecurrence: noEnd},
+		{AbsoluteYearlyRecurrence: &EWSAbsoluteYearlyRecurrence{DayOfMonth: 1, Month: "Smarch"}, NoEndRecurrence: noEnd},
+		{WeeklyRecurrence: &EWSWeeklyRecurrence{Interval: 2, DaysOfWeek: "Sunday Tuesday", FirstDayOfWeek: "Monday"}, NoEndRecurrence: noEnd},
+	}
+	for i, e := range recurrences {
+		if _, err := FromEWS(&e); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected ews recurrence %d to be unrepresentable: %v", i, err)
+		}
+	}
+
+	daily := &EWSDailyRecurrence{Interval: 1}
+	invalid := []EWSRecurrence{
+		{DailyRecurrence: daily, WeeklyRecurrence: &EWSWeeklyRecurrence{Interval: 1, DaysOfWeek: "Monday"}, NoEndRecurrence: noEnd},
+		{DailyRecurrence: daily, NoEndRecurrence: noEnd, NumberedRecurrence: &EWSNumberedRecurrence{StartDate: "2016-01-01", NumberOfOccurrences: 3}},
+		{AbsoluteMonthlyRecurrence: &EWSAbsoluteMonthlyRecurrence{Interval: 1, DayOfMonth: 31}, EndDateRecurrence: &EWSEndDateRecurrence{StartDate: "2016-01-01", EndDate: "2016-01-30"},
+			NumberedRecurrence: &EWSNumberedRecurrence{StartDate: "2016-01-01", NumberOfOccurrences: 1}},
+		{AbsoluteMonthlyRecurrence: &EWSAbsoluteMonthlyRecurrence{Interval: 12, DayOfMonth: 30}, NumberedRecurrence: &EWSNumberedRecurrence{StartDate: "2016-02-01", NumberOfOccurrences: 1}},
+	}
+	for i, e := range invalid {
+		if _, err := FromEWS(&e); err == nil || errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected ews recurrence %d to be invalid: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Frequency is the RecurrencePatternCode of a recurrence. Its values are the legacy single letter codes, so
+// existing data and untyped "D", "W", "M" and "Y" literals keep working
+type Frequency string
+
+// Frequencies of a recurrence
+const (
+	FrequencyDaily   Frequency = "D"
+	FrequencyWeekly  Frequency = "W"
+	FrequencyMonthly Frequency = "M"
+	FrequencyYearly  Frequency = "Y"
+)
+
+// frequencies lists every Frequency. Tests check that each one is handled everywhere a Frequency is switched on,
+// so a new one must be added here
+var frequencies = []Frequency{FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly}
+
+// frequencyRRuleNames are the RFC 5545 FREQ names of each Frequency
+var frequencyRRuleNames = map[Frequency]string{
+	FrequencyDaily: "DAILY", FrequencyWeekly: "WEEKLY", FrequencyMonthly: "MONTHLY", FrequencyYearly: "YEARLY",
+}
+
+// ParseFrequency parses a legacy single letter code (D, W, M or Y) or an RFC 5545 FREQ name (DAILY, WEEKLY,
+// MONTHLY or YEARLY), in any case
+func ParseFrequency(value string) (Frequency, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	for _, f := range frequencies {
+		if value == string(f) || value == frequencyRRuleNames[f] {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown frequency %q", value)
+}
+
+// Valid returns whether f is one of the Frequency constants
+func (f Frequency) Valid() bool {
+	_, ok := frequencyRRuleNames[f]
+	return ok
+}
+
+// RRuleName returns the RFC 5545 FREQ name of f, e.g. WEEKLY, or "" when f is not valid
+func (f Frequency) RRuleName() string {
+	return frequencyRRuleNames[f]
+}
@@ -0,0 +1,166 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseFrequency(t *testing.T) {
+	values := map[string]Frequency{
+		"D": FrequencyDaily, "w": FrequencyWeekly, "M": FrequencyMonthly, "Y": FrequencyYearly,
+		"DAILY": FrequencyDaily, "Weekly": FrequencyWeekly, " monthly ": FrequencyMonthly, "YEARLY": FrequencyYearly,
+	}
+	for value, expected := range values {
+		if actual, err := ParseFrequency(value); err != nil || actual != expected {
+			t.Errorf("%q: expected %s vs actual %s: %v", value, expected, actual, err)
+		}
+	}
+	for _, value := range []string{"", "X", "HOURLY", "DD", "day"} {
+		if _, err := ParseFrequency(value); err == nil {
+			t.Errorf("expected %q to be invalid", value)
+		}
+	}
+	if Frequency("X").Valid() || Frequency("").Valid() || Frequency("X").RRuleName() != "" {
+		t.Error("expected X to be invalid")
+	}
+	if FrequencyWeekly.RRuleName() != "WEEKLY" {
+		t.Error("unexpected FREQ name", FrequencyWeekly.RRuleName())
+	}
+}
+
+// TestFrequenciesHandled fails for a Frequency added to frequencies without being handled by the engine, Validate
+// and the converters
+func TestFrequenciesHandled(t *testing.T) {
+	start := time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC) // a Wednesday
+	quartzExpressions := map[Frequency]string{
+		FrequencyDaily: "0 0 0 * * ?", FrequencyWeekly: "0 0 0 ? * WED", FrequencyMonthly: "0 0 0 7 * ?", FrequencyYearly: "0 0 0 7 1 ?",
+	}
+	if len(frequencies) != len(frequencyRRuleNames) || len(frequencies) != len(quartzExpressions) {
+		t.Fatal("every frequency needs an RRULE FREQ name and a Quartz expression")
+	}
+	for _, f := range frequencies {
+		if !f.Valid() {
+			t.Errorf("%s is not valid", f)
+		}
+		if parsed, err := ParseFrequency(f.RRuleName()); err != nil || parsed != f {
+			t.Errorf("%s: expected %s to parse", f, f.RRuleName())
+		}
+		var b *Builder
+		switch f {
+		case FrequencyDaily:
+			b = Daily()
+		case FrequencyWeekly:
+			b = Weekly()
+		case FrequencyMonthly:
+			b = Monthly()
+		case FrequencyYearly:
+			b = Yearly()
+		default:
+			t.Fatalf("%s has no builder", f)
+		}
+		r, err := b.Starting(start).Build()
+		if err != nil {
+			t.Fatal(f, err)
+		}
+		occurrences := r.GetOccurrences(start, start.AddDate(1, 0, 0))
+		if len(occurrences) == 0 || occurrences[0] != start {
+			t.Errorf("%s: expected occurrences from the start", f)
+		}
+		if count := r.Count(start, start.AddDate(1, 0, 0)); count != len(occurrences) {
+			t.Errorf("%s: expected a count of %d, got %d", f, len(occurrences), count)
+		}
+		if last, ok := r.OccurrenceAt(len(occurrences) - 1); !ok || last != occurrences[len(occurrences)-1] {
+			t.Errorf("%s: expected occurrence %d to be %s, got %s", f, len(occurrences)-1, occurrences[len(occurrences)-1], last)
+		}
+		if _, err := r.Describe(nil); err != nil {
+			t.Error(f, err)
+		}
+		if value, err := r.RRule(); err != nil {
+			t.Error(f, err)
+		} else if parsed, err := ParseRRule(value, start); err != nil || parsed.RecurrencePatternCode != f {
+			t.Errorf("%s: expected %s to parse back: %v", f, value, err)
+		}
+		if data, err := json.Marshal(r); err != nil {
+			t.Error(f, err)
+		} else if err := json.Unmarshal(data, r); err != nil {
+			t.Error(f, err)
+		}
+		if _, err := r.ToGraph(); err != nil {
+			t.Error(f, err)
+		}
+		if _, err := r.ToEWS(); err != nil {
+			t.Error(f, err)
+		}
+		var p AppointmentRecurrencePattern
+		if data, err := (&AppointmentRecurrencePattern{Recurrence: *r}).MarshalBinary(); err != nil {
+			t.Error(f, err)
+		} else if err := p.UnmarshalBinary(data); err != nil || p.RecurrencePatternCode != f {
+			t.Errorf("%s: expected the appointment recurrence pattern to decode back: %v", f, err)
+		}
+		if value, err := r.RepeatingInterval(); err != nil {
+			t.Error(f, err)
+		} else if parsed, err := ParseRepeatingInterval(value, time.UTC); err != nil || parsed.RecurrencePatternCode != f {
+			t.Errorf("%s: expected %s to parse back: %v", f, value, err)
+		}
+
+		// cron, Quartz and systemd expressions have the same occurrences, but not always the same frequency
+		cron, err := r.Cron()
+		if err != nil {
+			t.Error(f, err)
+		}
+		onCalendar, err := r.OnCalendar()
+		if err != nil {
+			t.Error(f, err)
+		}
+		expressions := []struct {
+			value string
+			parse func(string, time.Time) ([]Recurrence, error)
+		}{{cron, ParseCron}, {quartzExpressions[f], ParseQuartz}, {onCalendar, ParseOnCalendar}}
+		for _, expression := range expressions {
+			parsed, err := expression.parse(expression.value, start)
+			if err != nil || len(parsed) != 1 {
+				t.Errorf("%s: expected %q to parse back to one recurrence: %v", f, expression.value, err)
+				continue
+			}
+			compareTimes(t, occurrences, parsed[0].GetOccurrences(start, start.AddDate(1, 0, 0)), string(f)+" "+expression.value)
+		}
+	}
+
+	// a frequency that is not handled has no occurrences, and every entry point that can return an error does
+	r := &Recurrence{StartDate: start, RecurrencePatternCode: Frequency("X"), RecurEvery: 1}
+	if r.RecurrencePatternCode.Valid() {
+		t.Fatal("expected X not to be valid")
+	}
+	if len(r.GetOccurrences(start, start.AddDate(1, 0, 0))) != 0 || r.IsValidOccurrenceDate(start) || r.Count(start, start.AddDate(1, 0, 0)) != 0 {
+		t.Error("expected no occurrences")
+	}
+	if _, ok := r.OccurrenceAt(0); ok {
+		t.Error("expected no first occurrence")
+	}
+	if _, err := r.occurrences(context.Background(), start, start.AddDate(1, 0, 0)); err == nil {
+		t.Error("expected the expansion to fail")
+	}
+	if _, err := r.GetOccurrencesContext(context.Background(), start, start.AddDate(1, 0, 0), nil); err == nil {
+		t.Error("expected an error for the unknown frequency")
+	}
+	if _, err := r.EndByDateFor(3); err == nil {
+		t.Error("expected no end for the unknown frequency")
+	}
+	converters := map[string]func() error{
+		"RRule":             func() error { _, err := r.RRule(); return err },
+		"ToGraph":           func() error { _, err := r.ToGraph(); return err },
+		"ToEWS":             func() error { _, err := r.ToEWS(); return err },
+		"Cron":              func() error { _, err := r.Cron(); return err },
+		"OnCalendar":        func() error { _, err := r.OnCalendar(); return err },
+		"RepeatingInterval": func() error { _, err := r.RepeatingInterval(); return err },
+		"Describe":          func() error { _, err := r.Describe(nil); return err },
+		"MarshalBinary":     func() error { _, err := (&AppointmentRecurrencePattern{Recurrence: *r}).MarshalBinary(); return err },
+	}
+	for name, convert := range converters {
+		if err := convert(); err == nil {
+			t.Errorf("%s: expected an error for the unknown frequency", name)
+		}
+	}
+}
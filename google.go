@@ -0,0 +1,125 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// GoogleEvent is the part of a Google Calendar API event resource that describes when it recurs. Recurring
+// instance records have RecurringEventID and OriginalStartTime set instead of Recurrence
+type GoogleEvent struct {
+	ID                string               `json:"id,omitempty"`
+	Start             GoogleEventDateTime  `json:"start"`
+	End               GoogleEventDateTime  `json:"end"`
+	Recurrence        []string             `json:"recurrence,omitempty"`        // RRULE, EXRULE, RDATE and EXDATE lines
+	RecurringEventID  string               `json:"recurringEventId,omitempty"`  // ID of the recurring event an instance belongs to
+	OriginalStartTime *GoogleEventDateTime `json:"originalStartTime,omitempty"` // start of an instance as generated by the recurrence
+}
+
+// GoogleEventDateTime is a Google Calendar API start or end time. Date is set for all-day events and DateTime otherwise
+type GoogleEventDateTime struct {
+	Date     string `json:"date,omitempty"`     // yyyy-mm-dd
+	DateTime string `json:"dateTime,omitempty"` // RFC 3339
+	TimeZone string `json:"timeZone,omitempty"` // IANA time zone the time is expressed in
+}
+
+const googleDateLayout = "2006-01-02"
+
+// FromGoogleEvent converts a recurring Google Calendar event to a Series. RDATE and EXRULE lines have no equivalent
+// and return ErrNotRepresentable
+func FromGoogleEvent(e *GoogleEvent) (*Series, error) {
+	start, allDay, err := e.Start.Time()
+	if err != nil {
+		return nil, fmt.Errorf("invalid google event start: %w", err)
+	}
+	end, _, err := e.End.Time()
+	if err != nil {
+		return nil, fmt.Errorf("invalid google event end: %w", err)
+	}
+	properties := make([]icalProperty, len(e.Recurrence))
+	for i, line := range e.Recurrence {
+		if properties[i], err = parseICSProperty(line); err != nil {
+			return nil, err
+		}
+	}
+	return newICalSeries(start, end, allDay, properties)
+}
+
+// ToGoogleEvent converts the series to a recurring Google Calendar event with the given id
+func (s *Series) ToGoogleEvent(id string) (*GoogleEvent, error) {
+	properties, err := s.icalRecurrenceProperties()
+	if err != nil {
+		return nil, err
+	}
+	e := &GoogleEvent{
+		ID:    id,
+		Start: NewGoogleEventDateTime(s.StartDate, s.AllDay),
+		End:   NewGoogleEventDateTime(s.StartDate.Add(s.Duration), s.AllDay),
+	}
+	for _, property := range properties {
+		e.Recurrence = append(e.Recurrence, property.contentLine())
+	}
+	return e, nil
+}
+
+// ToGoogleInstance returns the instance record Google Calendar lists for the occurrence on occurrenceDate of the
+// series with the given id. The instance id follows Google's id_yyyymmddThhmmssZ (or id_yyyymmdd) format
+func (s *Series) ToGoogleInstance(seriesID string, occurrenceDate time.Time) GoogleEvent {
+	start := s.occurrenceStart(occurrenceDate)
+	originalStartTime := NewGoogleEventDateTime(start, s.AllDay)
+	suffix := start.UTC().Format(icalDateTimeLayout) + "Z"
+	if s.AllDay {
+		suffix = start.Format(icalDateLayout)
+	}
+	return GoogleEvent{
+		ID:                seriesID + "_" + suffix,
+		Start:             originalStartTime,
+		End:               NewGoogleEventDateTime(start.Add(s.Duration), s.AllDay),
+		RecurringEventID:  seriesID,
+		OriginalStartTime: &originalStartTime,
+	}
+}
+
+// FromGoogleInstance returns the recurring event id and original start of a Google Calendar instance record
+func FromGoogleInstance(e *GoogleEvent) (seriesID string, originalStart time.Time, err error) {
+	if e.RecurringEventID == "" || e.OriginalStartTime == nil {
+		return "", time.Time{}, fmt.Errorf("google event %q is not an instance of a recurring event", e.ID)
+	}
+	originalStart, _, err = e.OriginalStartTime.Time()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid google event originalStartTime: %w", err)
+	}
+	return e.RecurringEventID, originalStart, nil
+}
+
+// NewGoogleEventDateTime returns the Google Calendar API representation of t. All-day times use only the date
+func NewGoogleEventDateTime(t time.Time, allDay bool) GoogleEventDateTime {
+	if allDay {
+		return GoogleEventDateTime{Date: t.Format(googleDateLayout)}
+	}
+	dateTime := GoogleEventDateTime{DateTime: t.Format(time.RFC3339)}
+	if t.Location() != time.Local {
+		dateTime.TimeZone = t.Location().String()
+	}
+	return dateTime
+}
+
+// Time returns the time in TimeZone, or the offset it was written with when TimeZone is empty, and whether it is
+// an all-day date. All-day dates are midnight UTC
+func (d *GoogleEventDateTime) Time() (t time.Time, allDay bool, err error) {
+	if d.Date != "" {
+		t, err = time.Parse(googleDateLayout, d.Date)
+		return t, true, err
+	}
+	if t, err = time.Parse(time.RFC3339, d.DateTime); err != nil {
+		return t, false, err
+	}
+	if d.TimeZone != "" {
+		loc, err := time.LoadLocation(d.TimeZone)
+		if err != nil {
+			return t, false, err
+		}
+		t = t.In(loc)
+	}
+	return t, false, nil
+}
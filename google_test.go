@@ -0,0 +1,134 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGoogleEventFixtures(t *testing.T) {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures := []struct {
+		file     string
+		id       string
+		expected Series
+	}{
+		{"timed_weekly.json", "7cbh8rpc10lrc0ckih9tafss99", Series{
+			Recurrence: Recurrence{StartDate: time.Date(2016, 1, 4, 9, 30, 0, 0, losAngeles), RecurrencePatternCode: "W", RecurEvery: 2,
+				WeeklyDaysIncluded: int16Ptr(32 + 8 + 2), EndByDate: timePtr(time.Date(2016, 6, 30, 0, 0, 0, 0, losAngeles))},
+			Duration:       30 * time.Minute,
+			ExceptionDates: []time.Time{time.Date(2016, 1, 6, 9, 30, 0, 0, losAngeles), time.Date(2016, 1, 18, 9, 30, 0, 0, losAngeles)},
+		}},
+		{"all_day_yearly.json", "4o8hq2fnbss1rpgmvb1spmk0f8", Series{
+			Recurrence: Recurrence{StartDate: time.Date(2016, 11, 24, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "Y", RecurEvery: 1,
+				YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54), NumberOfOccurrences: int16Ptr(10),
+				EndByDate: timePtr(time.Date(2025, 11, 27, 0, 0, 0, 0, time.UTC))},
+			AllDay:         true,
+			Duration:       24 * time.Hour,
+			ExceptionDates: []time.Time{time.Date(2017, 11, 30, 0, 0, 0, 0, time.UTC)},
+		}},
+	}
+	for _, fixture := range fixtures {
+		e := readGoogleEvent(t, fixture.file)
+		actual, err := FromGoogleEvent(e)
+		if err != nil {
+			t.Error(fixture.file, err)
+			continue
+		}
+		compareSeries(t, &fixture.expected, actual, fixture.file)
+		if fixture.expected.NumberOfOccurrences != nil {
+			compareOccurrenceCount(t, int(*fixture.expected.NumberOfOccurrences), &actual.Recurrence, fixture.file)
+		}
+
+		exported, err := fixture.expected.ToGoogleEvent(fixture.id)
+		if err != nil {
+			t.Error(fixture.file, err)
+			continue
+		}
+		if !reflect.DeepEqual(e, exported) {
+			t.Errorf("%s: expected %+v vs actual %+v", fixture.file, *e, *exported)
+		}
+	}
+}
+
+func TestGoogleInstanceFixtures(t *testing.T) {
+	series := []*Series{}
+	for _, file := range []string{"timed_weekly.json", "all_day_yearly.json"} {
+		s, err := FromGoogleEvent(readGoogleEvent(t, file))
+		if err != nil {
+			t.Fatal(file, err)
+		}
+		series = append(series, s)
+	}
+	instances := []struct {
+		file           string
+		series         *Series
+		occurrenceDate time.Time
+	}{
+		{"timed_instance.json", series[0], time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC)},
+		{"all_day_instance.json", series[1], time.Date(2018, 11, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, instance := range instances {
+		e := readGoogleEvent(t, instance.file)
+		seriesID, originalStart, err := FromGoogleInstance(e)
+		if err != nil {
+			t.Error(instance.file, err)
+			continue
+		}
+		expectedStart := instance.series.occurrenceStart(instance.occurrenceDate)
+		if seriesID != e.RecurringEventID || !originalStart.Equal(expectedStart) {
+			t.Errorf("%s: expected %s at %v vs actual %s at %v", instance.file, e.RecurringEventID, expectedStart, seriesID, originalStart)
+		}
+		if !instance.series.IsValidOccurrenceDate(originalStart) {
+			t.Errorf("%s: expected %v to be an occurrence of the series", instance.file, originalStart)
+		}
+
+		exported := instance.series.ToGoogleInstance(e.RecurringEventID, instance.occurrenceDate)
+		if !reflect.DeepEqual(e, &exported) {
+			t.Errorf("%s: expected %+v vs actual %+v", instance.file, *e, exported)
+		}
+	}
+
+	if _, _, err := FromGoogleInstance(readGoogleEvent(t, "timed_weekly.json")); err == nil {
+		t.Error("expected error for a recurring event that is not an instance")
+	}
+}
+
+func TestFromGoogleEventErrors(t *testing.T) {
+	start := GoogleEventDateTime{Date: "2016-01-01"}
+	events := []GoogleEvent{
+		{Start: start, End: start},
+		{Start: start, End: start, Recurrence: []string{"RRULE:FREQ=DAILY", "RDATE;VALUE=DATE:20160105"}},
+		{Start: start, End: start, Recurrence: []string{"RRULE:FREQ=DAILY", "RRULE:FREQ=WEEKLY"}},
+		{Start: start, End: start, Recurrence: []string{"RRULE:FREQ=HOURLY"}},
+	}
+	for i, e := range events {
+		if _, err := FromGoogleEvent(&e); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected google event %d to be unrepresentable: %v", i, err)
+		}
+	}
+
+	e := GoogleEvent{Start: GoogleEventDateTime{DateTime: "2016-01-01 09:00"}, End: start, Recurrence: []string{"RRULE:FREQ=DAILY"}}
+	if _, err := FromGoogleEvent(&e); err == nil {
+		t.Error("expected invalid start error")
+	}
+}
+
+func readGoogleEvent(t *testing.T, file string) *GoogleEvent {
+	data, err := os.ReadFile(filepath.Join("testdata", "google", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &GoogleEvent{}
+	if err := json.Unmarshal(data, e); err != nil {
+		t.Fatal(file, err)
+	}
+	return e
+}
@@ -0,0 +1,266 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GraphPatternedRecurrence is the Microsoft Graph patternedRecurrence resource
+type GraphPatternedRecurrence struct {
+	Pattern GraphRecurrencePattern `json:"pattern"`
+	Range   GraphRecurrenceRange   `json:"range"`
+}
+
+// GraphRecurrencePattern is the Microsoft Graph recurrencePattern resource
+type GraphRecurrencePattern struct {
+	Type           string   `json:"type"`                     // daily, weekly, absoluteMonthly, relativeMonthly, absoluteYearly or relativeYearly
+	Interval       int      `json:"interval"`                 // number of units between occurrences
+	Month          int      `json:"month,omitempty"`          // month of the year (absoluteYearly and relativeYearly)
+	DayOfMonth     int      `json:"dayOfMonth,omitempty"`     // day of the month (absoluteMonthly and absoluteYearly)
+	DaysOfWeek     []string `json:"daysOfWeek,omitempty"`     // lower case day names (weekly, relativeMonthly and relativeYearly)
+	FirstDayOfWeek string   `json:"firstDayOfWeek,omitempty"` // first day of the week (weekly)
+	Index          string   `json:"index,omitempty"`          // first, second, third, fourth or last (relativeMonthly and relativeYearly)
+}
+
+// GraphRecurrenceRange is the Microsoft Graph recurrenceRange resource
+type GraphRecurrenceRange struct {
+	Type                string `json:"type"`                          // endDate, noEnd or numbered
+	StartDate           string `json:"startDate"`                     // yyyy-mm-dd
+	EndDate             string `json:"endDate,omitempty"`             // yyyy-mm-dd (endDate)
+	NumberOfOccurrences int    `json:"numberOfOccurrences,omitempty"` // number of occurrences (numbered)
+	RecurrenceTimeZone  string `json:"recurrenceTimeZone,omitempty"`  // IANA time zone of startDate and endDate
+}
+
+const graphDateLayout = "2006-01-02"
+
+var graphIndexes = map[int16]string{1: "first", 2: "second", 3: "third", 4: "fourth", 54: "last"}
+
+// ToGraph converts the recurrence to a Microsoft Graph patternedRecurrence. Graph has no daily weekday-only
+// pattern, so a DailyIsOnlyWeekday recurrence every 1 day is written as weekly on Monday through Friday the
+// same way Outlook does, and every N weekdays returns ErrNotRepresentable
+func (r *Recurrence) ToGraph() (*GraphPatternedRecurrence, error) {
+	if r.RecurEvery < 1 {
+		return nil, fmt.Errorf("%w: graph interval must be at least 1, got %d", ErrNotRepresentable, r.RecurEvery)
+	}
+	g := &GraphPatternedRecurrence{Pattern: GraphRecurrencePattern{Interval: int(r.RecurEvery)}}
+	switch r.RecurrencePatternCode {
+	case FrequencyDaily:
+		if r.DailyIsOnlyWeekday != nil && *r.DailyIsOnlyWeekday {
+			if r.RecurEvery != 1 {
+				return nil, fmt.Errorf("%w: graph cannot recur every %d weekdays", ErrNotRepresentable, r.RecurEvery)
+			}
+			g.Pattern.Type = "weekly"
+			g.Pattern.DaysOfWeek = graphDaysOfWeek(32 + 16 + 8 + 4 + 2)
+			g.Pattern.FirstDayOfWeek = "sunday"
+		} else {
+			g.Pattern.Type = "daily"
+		}
+	case FrequencyWeekly:
+		weeklyDaysIncluded := r.Weekdays().Bitmask()
+		g.Pattern.Type = "weekly"
+		g.Pattern.DaysOfWeek = graphDaysOfWeek(weeklyDaysIncluded)
+		g.Pattern.FirstDayOfWeek = "sunday"
+	case FrequencyMonthly, FrequencyYearly:
+		prefix := "Monthly"
+		if r.RecurrencePatternCode == FrequencyYearly {
+			if r.YearlyMonth == nil {
+				return nil, fmt.Errorf("%w: yearly recurrence has no YearlyMonth", ErrNotRepresentable)
+			}
+			prefix = "Yearly"
+			g.Pattern.Month = int(*r.YearlyMonth)
+		}
+		switch {
+		case r.MonthlyDay != nil:
+			if err := r.plainMonthlyDay(); err != nil {
+				return nil, err
+			}
+			g.Pattern.Type = "absolute" + prefix
+			g.Pattern.DayOfMonth = int(*r.MonthlyDay)
+		case r.MonthlyDayOfWeek != nil && r.MonthlyWeekOfMonth != nil:
+			index, ok := graphIndexes[*r.MonthlyWeekOfMonth]
+			if !ok {
+				return nil, fmt.Errorf("%w: graph has no index for week %d of the month", ErrNotRepresentable, *r.MonthlyWeekOfMonth)
+			}
+			g.Pattern.Type = "relative" + prefix
+			g.Pattern.DaysOfWeek = []string{graphDayName(time.Weekday(*r.MonthlyDayOfWeek))}
+			g.Pattern.Index = index
+		default:
+			return nil, fmt.Errorf("%w: recurrence has neither MonthlyDay nor MonthlyDayOfWeek and MonthlyWeekOfMonth", ErrNotRepresentable)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown recurrence pattern code %q", ErrNotRepresentable, r.RecurrencePatternCode)
+	}
+
+	g.Range.StartDate = r.StartDate.Format(graphDateLayout)
+	if loc := r.StartDate.Location(); loc != time.UTC && loc != time.Local {
+		g.Range.RecurrenceTimeZone = loc.String()
+	}
+	switch {
+	case r.NumberOfOccurrences != nil:
+		g.Range.Type = "numbered"
+		g.Range.NumberOfOccurrences = int(*r.NumberOfOccurrences)
+	case r.EndByDate != nil:
+		g.Range.Type = "endDate"
+		g.Range.EndDate = r.EndByDate.Format(graphDateLayout)
+	default:
+		g.Range.Type = "noEnd"
+	}
+	return g, nil
+}
+
+// FromGraph converts a Microsoft Graph patternedRecurrence to a Recurrence. Weekly patterns are evaluated with
+// weeks starting on Sunday, so a weekly pattern with an interval greater than 1 whose firstDayOfWeek would group
+// its days differently returns ErrNotRepresentable
+func FromGraph(g *GraphPatternedRecurrence) (*Recurrence, error) {
+	p := g.Pattern
+	if p.Interval < 1 || p.Interval > 32767 {
+		return nil, fmt.Errorf("%w: graph interval %d is out of range", ErrNotRepresentable, p.Interval)
+	}
+	r := &Recurrence{RecurEvery: int16(p.Interval)}
+	switch p.Type {
+	case "daily":
+		r.RecurrencePatternCode = FrequencyDaily
+	case "weekly":
+		days, err := graphWeeklyDaysIncluded(p.DaysOfWeek)
+		if err != nil {
+			return nil, err
+		}
+		if p.FirstDayOfWeek != "" && p.Interval > 1 {
+			firstDay, err := parseGraphDayName(p.FirstDayOfWeek)
+			if err != nil {
+				return nil, err
+			}
+			if !sundayWeeksMatch(days, firstDay) {
+				return nil, fmt.Errorf("%w: weeks starting on %s group these days differently than weeks starting on Sunday", ErrNotRepresentable, firstDay)
+			}
+		}
+		r.RecurrencePatternCode = FrequencyWeekly
+		r.WeeklyDaysIncluded = &days
+	case "absoluteMonthly", "absoluteYearly":
+		if p.DayOfMonth < 1 || p.DayOfMonth > 31 {
+			return nil, fmt.Errorf("%w: graph dayOfMonth %d is out of range", ErrNotRepresentable, p.DayOfMonth)
+		}
+		day := int16(p.DayOfMonth)
+		r.MonthlyDay = &day
+	case "relativeMonthly", "relativeYearly":
+		if len(p.DaysOfWeek) != 1 {
+			return nil, fmt.Errorf("%w: relative patterns must have exactly one day of the week, got %d", ErrNotRepresentable, len(p.DaysOfWeek))
+		}
+		day, err := parseGraphDayName(p.DaysOfWeek[0])
+		if err != nil {
+			return nil, err
+		}
+		week, err := parseGraphIndex(p.Index)
+		if err != nil {
+			return nil, err
+		}
+		dayOfWeek := int16(day)
+		r.MonthlyDayOfWeek = &dayOfWeek
+		r.MonthlyWeekOfMonth = &week
+	default:
+		return nil, fmt.Errorf("%w: unknown graph pattern type %q", ErrNotRepresentable, p.Type)
+	}
+	switch p.Type {
+	case "absoluteMonthly", "relativeMonthly":
+		r.RecurrencePatternCode = FrequencyMonthly
+	case "absoluteYearly", "relativeYearly":
+		if p.Month < 1 || p.Month > 12 {
+			return nil, fmt.Errorf("%w: graph month %d is out of range", ErrNotRepresentable, p.Month)
+		}
+		month := int16(p.Month)
+		r.RecurrencePatternCode = FrequencyYearly
+		r.YearlyMonth = &month
+	}
+
+	loc := time.UTC
+	if g.Range.RecurrenceTimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(g.Range.RecurrenceTimeZone); err != nil {
+			return nil, fmt.Errorf("invalid graph recurrenceTimeZone: %w", err)
+		}
+	}
+	startDate, err := time.ParseInLocation(graphDateLayout, g.Range.StartDate, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid graph startDate: %w", err)
+	}
+	r.StartDate = startDate
+	switch g.Range.Type {
+	case "endDate":
+		endDate, err := time.ParseInLocation(graphDateLayout, g.Range.EndDate, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid graph endDate: %w", err)
+		}
+		r.EndByDate = &endDate
+	case "numbered":
+		if g.Range.NumberOfOccurrences < 1 || g.Range.NumberOfOccurrences > 32767 {
+			return nil, fmt.Errorf("%w: graph numberOfOccurrences %d is out of range", ErrNotRepresentable, g.Range.NumberOfOccurrences)
+		}
+		if err := r.SetNumberOfOccurrences(g.Range.NumberOfOccurrences); err != nil {
+			return nil, fmt.Errorf("invalid graph range: %w", err)
+		}
+	case "noEnd":
+	default:
+		return nil, fmt.Errorf("%w: unknown graph range type %q", ErrNotRepresentable, g.Range.Type)
+	}
+	return r, nil
+}
+
+func graphDayName(day time.Weekday) string {
+	return strings.ToLower(day.String())
+}
+
+func parseGraphDayName(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(name, day.String()) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown graph day of week %q", ErrNotRepresentable, name)
+}
+
+func graphDaysOfWeek(weeklyDaysIncluded int16) []string {
+	var names []string
+	for _, day := range getIncludedWeeklyDays(weeklyDaysIncluded) {
+		names = append(names, graphDayName(day))
+	}
+	return names
+}
+
+func graphWeeklyDaysIncluded(names []string) (int16, error) {
+	var weeklyDaysIncluded int16
+	for _, name := range names {
+		day, err := parseGraphDayName(name)
+		if err != nil {
+			return 0, err
+		}
+		weeklyDaysIncluded |= weekdayBit(day)
+	}
+	if weeklyDaysIncluded == 0 {
+		return 0, fmt.Errorf("%w: weekly pattern has no days of the week", ErrNotRepresentable)
+	}
+	return weeklyDaysIncluded, nil
+}
+
+func parseGraphIndex(index string) (int16, error) {
+	for week, name := range graphIndexes {
+		if strings.EqualFold(index, name) {
+			return week, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown graph index %q", ErrNotRepresentable, index)
+}
+
+// sundayWeeksMatch reports whether weeks starting on firstDay contain the same included days as weeks starting
+// on Sunday, which is true when every included day falls on the same side of firstDay
+func sundayWeeksMatch(weeklyDaysIncluded int16, firstDay time.Weekday) bool {
+	before, after := false, false
+	for _, day := range getIncludedWeeklyDays(weeklyDaysIncluded) {
+		if day < firstDay {
+			before = true
+		} else {
+			after = true
+		}
+	}
+	return !(before && after)
+}
@@ -0,0 +1,137 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGraphFixtures(t *testing.T) {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures := []struct {
+		file        string
+		expected    Recurrence
+		occurrences int // of a numbered range
+	}{
+		{"daily.json", Recurrence{StartDate: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "D", RecurEvery: 3}, 0},
+		{"weekly.json", Recurrence{StartDate: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "W", RecurEvery: 2,
+			WeeklyDaysIncluded: int16Ptr(32 + 8 + 2), EndByDate: timePtr(time.Date(2016, 6, 30, 0, 0, 0, 0, time.UTC))}, 0},
+		{"every_weekday.json", Recurrence{StartDate: time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "W", RecurEvery: 1,
+			WeeklyDaysIncluded: int16Ptr(32 + 16 + 8 + 4 + 2), NumberOfOccurrences: int16Ptr(10), EndByDate: timePtr(time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC))}, 10},
+		{"absolute_monthly.json", Recurrence{StartDate: time.Date(2016, 1, 15, 0, 0, 0, 0, losAngeles), RecurrencePatternCode: "M", RecurEvery: 1,
+			MonthlyDay: int16Ptr(15)}, 0},
+		{"relative_monthly.json", Recurrence{StartDate: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 2,
+			MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(4), EndByDate: timePtr(time.Date(2016, 7, 1, 0, 0, 0, 0, time.UTC))}, 0},
+		{"absolute_yearly.json", Recurrence{StartDate: time.Date(2010, 2, 14, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "Y", RecurEvery: 1,
+			YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(14), NumberOfOccurrences: int16Ptr(5), EndByDate: timePtr(time.Date(2014, 2, 14, 0, 0, 0, 0, time.UTC))}, 5},
+		{"relative_yearly.json", Recurrence{StartDate: time.Date(2016, 11, 24, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "Y", RecurEvery: 1,
+			YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54)}, 0},
+	}
+	for _, fixture := range fixtures {
+		data, err := os.ReadFile(filepath.Join("testdata", "graph", fixture.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var g GraphPatternedRecurrence
+		if err := json.Unmarshal(data, &g); err != nil {
+			t.Fatal(fixture.file, err)
+		}
+		actual, err := FromGraph(&g)
+		if err != nil {
+			t.Error(fixture.file, err)
+			continue
+		}
+		compareRecurrences(t, &fixture.expected, actual, fixture.file)
+		if fixture.occurrences > 0 {
+			if occurrences := actual.GetOccurrences(actual.StartDate, actual.StartDate.AddDate(50, 0, 0)); len(occurrences) != fixture.occurrences {
+				t.Errorf("%s: expected %d occurrences vs actual %d", fixture.file, fixture.occurrences, len(occurrences))
+			}
+		}
+
+		exported, err := fixture.expected.ToGraph()
+		if err != nil {
+			t.Error(fixture.file, err)
+			continue
+		}
+		if !reflect.DeepEqual(&g, exported) {
+			t.Errorf("%s: expected %+v vs actual %+v", fixture.file, g, *exported)
+		}
+	}
+}
+
+func TestToGraphDailyIsOnlyWeekday(t *testing.T) {
+	r := Recurrence{StartDate: time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)}
+	g, err := r.ToGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Pattern.Type != "weekly" || !reflect.DeepEqual(g.Pattern.DaysOfWeek, []string{"monday", "tuesday", "wednesday", "thursday", "friday"}) {
+		t.Error("expected weekly on weekdays", g.Pattern)
+	}
+
+	r.RecurEvery = 2
+	if _, err := r.ToGraph(); !errors.Is(err, ErrNotRepresentable) {
+		t.Error("expected every other weekday to be unrepresentable", err)
+	}
+}
+
+func TestToGraphErrors(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	recurrences := []Recurrence{
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(2), MonthlyWeekOfMonth: int16Ptr(5)}, // 5th week only exists some months
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, MonthlyDay: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-1)},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(1), MonthlyDay: int16Ptr(15), MonthlyNearestWeekday: boolPtr(true)},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 0},
+		{StartDate: start, RecurrencePatternCode: "B", RecurEvery: 1},
+	}
+	for i, r := range recurrences {
+		if _, err := r.ToGraph(); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected recurrence %d to be unrepresentable: %v", i, err)
+		}
+	}
+}
+
+func TestFromGraphErrors(t *testing.T) {
+	validRange := GraphRecurrenceRange{Type: "noEnd", StartDate: "2016-01-01"}
+	recurrences := []GraphPatternedRecurrence{
+		{Pattern: GraphRecurrencePattern{Type: "hourly", Interval: 1}, Range: validRange},
+		{Pattern: GraphRecurrencePattern{Type: "daily", Interval: 0}, Range: validRange},
+		{Pattern: GraphRecurrencePattern{Type: "weekly", Interval: 1}, Range: validRange},
+		{Pattern: GraphRecurrencePattern{Type: "weekly", Interval: 2, DaysOfWeek: []string{"sunday", "tuesday"}, FirstDayOfWeek: "monday"}, Range: validRange},
+		{Pattern: GraphRecurrencePattern{Type: "relativeMonthly", Interval: 1, DaysOfWeek: []string{"monday", "friday"}, Index: "first"}, Range: validRange},
+		{Pattern: GraphRecurrencePattern{Type: "relativeMonthly", Interval: 1, DaysOfWeek: []string{"monday"}, Index: "fifth"}, Range: validRange},
+		{Pattern: GraphRecurrencePattern{Type: "absoluteYearly", Interval: 1, DayOfMonth: 1, Month: 13}, Range: validRange},
+		{Pattern: GraphRecurrencePattern{Type: "daily", Interval: 1}, Range: GraphRecurrenceRange{Type: "numbered", StartDate: "2016-01-01"}},
+	}
+	for i, g := range recurrences {
+		if _, err := FromGraph(&g); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected graph recurrence %d to be unrepresentable: %v", i, err)
+		}
+	}
+
+	g := GraphPatternedRecurrence{Pattern: GraphRecurrencePattern{Type: "daily", Interval: 1}, Range: GraphRecurrenceRange{Type: "noEnd", StartDate: "01/01/2016"}}
+	if _, err := FromGraph(&g); err == nil {
+		t.Error("expected invalid startDate error")
+	}
+}
+
+func TestFromGraphWeeklyFirstDayOfWeek(t *testing.T) {
+	// Monday and Wednesday fall in the same week whether weeks start on Sunday or Monday
+	g := GraphPatternedRecurrence{
+		Pattern: GraphRecurrencePattern{Type: "weekly", Interval: 2, DaysOfWeek: []string{"monday", "wednesday"}, FirstDayOfWeek: "monday"},
+		Range:   GraphRecurrenceRange{Type: "noEnd", StartDate: "2016-01-04"}}
+	r, err := FromGraph(&g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareInt16s(t, int16Ptr(32+8), r.WeeklyDaysIncluded, "TestFromGraphWeeklyFirstDayOfWeek")
+}
@@ -0,0 +1,429 @@
+package calendar
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// icalProperty is an iCalendar property in the model shared by the .ics, jCal and xCal formats. Names are upper
+// case and values are in their .ics form (20160104T093000, FREQ=WEEKLY;BYDAY=MO) without TEXT escaping
+type icalProperty struct {
+	name      string
+	params    map[string]string // upper case parameter names other than VALUE
+	valueType string            // upper case value type such as DATE, DATE-TIME, RECUR or TEXT
+	values    []string
+}
+
+// icalComponent is an iCalendar component such as VCALENDAR or VEVENT
+type icalComponent struct {
+	name       string
+	properties []icalProperty
+	components []icalComponent
+}
+
+const icalProductID = "-//robarchibald//calendar//EN"
+
+// icalDefaultValueTypes are the value types of the recurrence properties when no VALUE parameter is given
+var icalDefaultValueTypes = map[string]string{
+	"DTSTART": "DATE-TIME", "DTEND": "DATE-TIME", "EXDATE": "DATE-TIME", "RDATE": "DATE-TIME", "RRULE": "RECUR", "EXRULE": "RECUR",
+	"RECURRENCE-ID": "DATE-TIME",
+}
+
+// ToICS returns the series as an iCalendar (RFC 5545) VCALENDAR holding one VEVENT with the given UID. TZID
+// parameters name IANA time zones and no VTIMEZONE components are written
+func (s *Series) ToICS(uid string) ([]byte, error) {
+	calendar, err := s.icalCalendar(uid)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	writeICSComponent(&b, calendar)
+	return b.Bytes(), nil
+}
+
+// FromICS reads the first VEVENT of an iCalendar (RFC 5545) stream as a Series. VTIMEZONE components are ignored,
+// so TZID parameters must name IANA time zones
+func FromICS(data []byte) (*Series, error) {
+	calendar, err := parseICS(data)
+	if err != nil {
+		return nil, err
+	}
+	return seriesFromICalCalendar(calendar)
+}
+
+// icalCalendar returns a VCALENDAR holding the series as a VEVENT with the given UID
+func (s *Series) icalCalendar(uid string) (*icalComponent, error) {
+	properties, err := s.icalProperties()
+	if err != nil {
+		return nil, err
+	}
+	event := icalComponent{name: "VEVENT", properties: append([]icalProperty{newICalTextProperty("UID", uid)}, properties...)}
+	return &icalComponent{
+		name:       "VCALENDAR",
+		properties: []icalProperty{newICalTextProperty("VERSION", "2.0"), newICalTextProperty("PRODID", icalProductID)},
+		components: []icalComponent{event},
+	}, nil
+}
+
+// icalProperties returns the DTSTART, DTEND, RRULE and EXDATE properties of the series
+func (s *Series) icalProperties() ([]icalProperty, error) {
+	recurrence, err := s.icalRecurrenceProperties()
+	if err != nil {
+		return nil, err
+	}
+	properties := []icalProperty{
+		newICalTimeProperty("DTSTART", s.AllDay, s.StartDate),
+		newICalTimeProperty("DTEND", s.AllDay, s.StartDate.Add(s.Duration)),
+	}
+	return append(properties, recurrence...), nil
+}
+
+// icalRecurrenceProperties returns the RRULE and EXDATE properties of the series
+func (s *Series) icalRecurrenceProperties() ([]icalProperty, error) {
+	rule, err := s.rrule(s.AllDay)
+	if err != nil {
+		return nil, err
+	}
+	properties := []icalProperty{{name: "RRULE", valueType: "RECUR", values: []string{rule.String()}}}
+	if len(s.ExceptionDates) > 0 {
+		exceptions := make([]time.Time, len(s.ExceptionDates))
+		for i, exceptionDate := range s.ExceptionDates {
+			exceptions[i] = s.occurrenceStart(exceptionDate)
+		}
+		properties = append(properties, newICalTimeProperty("EXDATE", s.AllDay, exceptions...))
+	}
+	return properties, nil
+}
+
+func newICalTextProperty(name, value string) icalProperty {
+	return icalProperty{name: name, valueType: "TEXT", values: []string{value}}
+}
+
+// newICalTimeProperty returns a DATE or DATE-TIME property. Times are written with a TZID in their IANA time zone,
+// floating when in time.Local and in UTC otherwise
+func newICalTimeProperty(name string, allDay bool, times ...time.Time) icalProperty {
+	property := icalProperty{name: name, params: map[string]string{}, valueType: "DATE-TIME"}
+	if allDay {
+		property.valueType = "DATE"
+	}
+	loc := times[0].Location()
+	zoned := !allDay && loc != time.UTC && loc != time.Local && loc.String() != ""
+	if zoned {
+		property.params["TZID"] = loc.String()
+	}
+	for _, t := range times {
+		switch {
+		case allDay:
+			property.values = append(property.values, t.Format(icalDateLayout))
+		case zoned:
+			property.values = append(property.values, t.In(loc).Format(icalDateTimeLayout))
+		case loc == time.Local:
+			property.values = append(property.values, t.Format(icalDateTimeLayout))
+		default:
+			property.values = append(property.values, t.UTC().Format(icalDateTimeLayout)+"Z")
+		}
+	}
+	return property
+}
+
+// seriesFromICalCalendar converts the first VEVENT of a VCALENDAR, or a lone VEVENT, to a Series
+func seriesFromICalCalendar(c *icalComponent) (*Series, error) {
+	if c.name == "VEVENT" {
+		return seriesFromICalProperties(c.properties)
+	}
+	for _, component := range c.components {
+		if component.name == "VEVENT" {
+			return seriesFromICalProperties(component.properties)
+		}
+	}
+	return nil, fmt.Errorf("%s has no VEVENT", c.name)
+}
+
+// seriesFromICalProperties converts the properties of a VEVENT to a Series. An event without DTEND lasts one day
+// when all-day and no time otherwise
+func seriesFromICalProperties(properties []icalProperty) (*Series, error) {
+	var start, end *icalProperty
+	for i := range properties {
+		switch properties[i].name {
+		case "DTSTART":
+			start = &properties[i]
+		case "DTEND":
+			end = &properties[i]
+		}
+	}
+	if start == nil {
+		return nil, fmt.Errorf("event has no DTSTART")
+	}
+	startTimes, allDay, err := start.times(time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if len(startTimes) != 1 {
+		return nil, fmt.Errorf("event DTSTART must have a single value")
+	}
+	endTime := startTimes[0]
+	if allDay {
+		endTime = endTime.AddDate(0, 0, 1)
+	}
+	if end != nil {
+		endTimes, _, err := end.times(startTimes[0].Location())
+		if err != nil {
+			return nil, err
+		}
+		if len(endTimes) != 1 {
+			return nil, fmt.Errorf("event DTEND must have a single value")
+		}
+		endTime = endTimes[0]
+	}
+	return newICalSeries(startTimes[0], endTime, allDay, properties)
+}
+
+// newICalSeries builds a Series starting at start from the RRULE and EXDATE properties of an event. RDATE and
+// EXRULE have no equivalent and return ErrNotRepresentable. Other properties are ignored
+func newICalSeries(start, end time.Time, allDay bool, properties []icalProperty) (*Series, error) {
+	s := &Series{AllDay: allDay, Duration: end.Sub(start)}
+	var recurrence *Recurrence
+	for _, property := range properties {
+		switch property.name {
+		case "RRULE":
+			if recurrence != nil {
+				return nil, fmt.Errorf("%w: event has more than one RRULE", ErrNotRepresentable)
+			}
+			if len(property.values) != 1 {
+				return nil, fmt.Errorf("event RRULE must have a single value")
+			}
+			var err error
+			if recurrence, err = ParseRRule(property.values[0], start); err != nil {
+				return nil, err
+			}
+		case "EXDATE":
+			exceptionDates, _, err := property.times(start.Location())
+			if err != nil {
+				return nil, err
+			}
+			for _, exceptionDate := range exceptionDates {
+				s.ExceptionDates = append(s.ExceptionDates, exceptionDate.In(start.Location()))
+			}
+		case "RDATE", "EXRULE":
+			return nil, fmt.Errorf("%w: event uses %s", ErrNotRepresentable, property.name)
+		}
+	}
+	if recurrence == nil {
+		return nil, fmt.Errorf("%w: event has no RRULE", ErrNotRepresentable)
+	}
+	s.Recurrence = *recurrence
+	return s, nil
+}
+
+// times parses a DATE or DATE-TIME property. DATEs are midnight UTC and DATE-TIMEs are in their TZID, in UTC when
+// they end in Z, or otherwise floating in loc
+func (p *icalProperty) times(loc *time.Location) (times []time.Time, isDate bool, err error) {
+	switch p.valueType {
+	case "DATE":
+		isDate, loc = true, time.UTC
+	case "DATE-TIME":
+		if tzid := p.params["TZID"]; tzid != "" {
+			if loc, err = time.LoadLocation(tzid); err != nil {
+				return nil, false, fmt.Errorf("invalid %s TZID: %w", p.name, err)
+			}
+		}
+	default:
+		return nil, false, fmt.Errorf("%s must be a DATE or DATE-TIME, got %s", p.name, p.valueType)
+	}
+	for _, value := range p.values {
+		t, valueIsDate, err := parseICalDateTime(value, loc)
+		if err == nil && valueIsDate != isDate {
+			err = fmt.Errorf("expected %s", p.valueType)
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid %s %q: %w", p.name, value, err)
+		}
+		times = append(times, t)
+	}
+	return times, isDate, nil
+}
+
+// contentLine formats the property as an unfolded .ics content line
+func (p *icalProperty) contentLine() string {
+	line := p.name
+	if defaultType, ok := icalDefaultValueTypes[p.name]; (ok && p.valueType != defaultType) || (!ok && p.valueType != "TEXT") {
+		line += ";VALUE=" + p.valueType
+	}
+	for _, name := range sortedKeys(p.params) {
+		value := p.params[name]
+		if strings.ContainsAny(value, ":;,") {
+			value = `"` + value + `"`
+		}
+		line += ";" + name + "=" + value
+	}
+	values := p.values
+	if p.valueType == "TEXT" {
+		values = make([]string, len(p.values))
+		for i, value := range p.values {
+			values[i] = icalTextEscaper.Replace(value)
+		}
+	}
+	return line + ":" + strings.Join(values, ",")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var icalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+func writeICSComponent(b *bytes.Buffer, c *icalComponent) {
+	writeICSLine(b, "BEGIN:"+c.name)
+	for _, property := range c.properties {
+		writeICSLine(b, property.contentLine())
+	}
+	for i := range c.components {
+		writeICSComponent(b, &c.components[i])
+	}
+	writeICSLine(b, "END:"+c.name)
+}
+
+// writeICSLine writes a CRLF terminated content line folded to 75 octets without splitting UTF-8 characters
+func writeICSLine(b *bytes.Buffer, line string) {
+	length := 0
+	for _, c := range line {
+		size := len(string(c))
+		if length+size > 75 {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+		b.WriteRune(c)
+		length += size
+	}
+	b.WriteString("\r\n")
+}
+
+// parseICS unfolds and parses an .ics stream into its outermost component
+func parseICS(data []byte) (*icalComponent, error) {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+		} else if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	var root *icalComponent
+	var stack []*icalComponent
+	for _, line := range lines {
+		property, err := parseICSProperty(line)
+		if err != nil {
+			return nil, err
+		}
+		switch property.name {
+		case "BEGIN":
+			if root != nil {
+				return nil, fmt.Errorf("ics has content after END:%s", root.name)
+			}
+			stack = append(stack, &icalComponent{name: strings.ToUpper(strings.Join(property.values, ","))})
+		case "END":
+			name := strings.ToUpper(strings.Join(property.values, ","))
+			if len(stack) == 0 || stack[len(stack)-1].name != name {
+				return nil, fmt.Errorf("ics has unexpected END:%s", name)
+			}
+			component := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				root = component
+			} else {
+				parent := stack[len(stack)-1]
+				parent.components = append(parent.components, *component)
+			}
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("ics property %s is outside of a component", property.name)
+			}
+			component := stack[len(stack)-1]
+			component.properties = append(component.properties, property)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("ics has no END:%s", stack[len(stack)-1].name)
+	}
+	if root == nil {
+		return nil, fmt.Errorf("ics has no components")
+	}
+	return root, nil
+}
+
+// parseICSProperty parses an unfolded .ics content line
+func parseICSProperty(line string) (icalProperty, error) {
+	name, params, value, err := parseContentLine(line)
+	if err != nil {
+		return icalProperty{}, err
+	}
+	property := icalProperty{name: name, params: params, valueType: "TEXT"}
+	if defaultType, ok := icalDefaultValueTypes[name]; ok {
+		property.valueType = defaultType
+	}
+	if valueType := params["VALUE"]; valueType != "" {
+		property.valueType = strings.ToUpper(valueType)
+		delete(params, "VALUE")
+	}
+	switch property.valueType {
+	case "RECUR":
+		property.values = []string{value}
+	case "TEXT":
+		property.values = splitICalText(value)
+	default:
+		property.values = strings.Split(value, ",")
+	}
+	return property, nil
+}
+
+// splitICalText splits a TEXT value on unescaped commas and removes its escaping
+func splitICalText(value string) []string {
+	var values []string
+	var current strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			i++
+			if value[i] == 'n' || value[i] == 'N' {
+				current.WriteByte('\n')
+			} else {
+				current.WriteByte(value[i])
+			}
+		case value[i] == ',':
+			values = append(values, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(value[i])
+		}
+	}
+	return append(values, current.String())
+}
+
+// icalExtendedDateTime converts a DATE or DATE-TIME from its .ics form (20160104T093000Z) to the form jCal and
+// xCal use (2016-01-04T09:30:00Z)
+func icalExtendedDateTime(value string) string {
+	date, clock, hasTime := strings.Cut(value, "T")
+	if len(date) == 8 {
+		date = date[:4] + "-" + date[4:6] + "-" + date[6:]
+	}
+	if !hasTime {
+		return date
+	}
+	if len(clock) >= 6 {
+		clock = clock[:2] + ":" + clock[2:4] + ":" + clock[4:]
+	}
+	return date + "T" + clock
+}
+
+// icalBasicDateTime converts a jCal or xCal DATE or DATE-TIME back to its .ics form
+func icalBasicDateTime(value string) string {
+	return strings.NewReplacer("-", "", ":", "").Replace(value)
+}
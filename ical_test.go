@@ -0,0 +1,144 @@
+package calendar
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type icalFixture struct {
+	name     string
+	uid      string
+	expected Series
+}
+
+// icalFixtures are the series stored in testdata/ical, testdata/jcal and testdata/xcal under the same name
+func icalFixtures(t *testing.T) []icalFixture {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []icalFixture{
+		{"timed_weekly", "7cbh8rpc10lrc0ckih9tafss99@google.com", Series{
+			Recurrence: Recurrence{StartDate: time.Date(2016, 1, 4, 9, 30, 0, 0, losAngeles), RecurrencePatternCode: "W", RecurEvery: 2,
+				WeeklyDaysIncluded: int16Ptr(32 + 8 + 2), EndByDate: timePtr(time.Date(2016, 6, 30, 0, 0, 0, 0, losAngeles))},
+			Duration:       30 * time.Minute,
+			ExceptionDates: []time.Time{time.Date(2016, 1, 6, 9, 30, 0, 0, losAngeles), time.Date(2016, 1, 18, 9, 30, 0, 0, losAngeles)},
+		}},
+		{"all_day_yearly", "4o8hq2fnbss1rpgmvb1spmk0f8@google.com", Series{
+			Recurrence: Recurrence{StartDate: time.Date(2016, 11, 24, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "Y", RecurEvery: 1,
+				YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54), NumberOfOccurrences: int16Ptr(10),
+				EndByDate: timePtr(time.Date(2025, 11, 27, 0, 0, 0, 0, time.UTC))},
+			AllDay:         true,
+			Duration:       24 * time.Hour,
+			ExceptionDates: []time.Time{time.Date(2017, 11, 30, 0, 0, 0, 0, time.UTC)},
+		}},
+	}
+}
+
+func TestICSFixtures(t *testing.T) {
+	for _, fixture := range icalFixtures(t) {
+		file := fixture.name + ".ics"
+		data := readTestData(t, "ical", file)
+		actual, err := FromICS(data)
+		if err != nil {
+			t.Error(file, err)
+			continue
+		}
+		compareSeries(t, &fixture.expected, actual, file)
+		if fixture.expected.NumberOfOccurrences != nil {
+			compareOccurrenceCount(t, int(*fixture.expected.NumberOfOccurrences), &actual.Recurrence, file)
+		}
+
+		exported, err := fixture.expected.ToICS(fixture.uid)
+		if err != nil {
+			t.Error(file, err)
+		} else if string(exported) != string(data) {
+			t.Errorf("%s: expected\n%s\nvs actual\n%s", file, data, exported)
+		}
+	}
+}
+
+func TestFromICS(t *testing.T) {
+	// folded lines, LF line endings, other properties and components, and overridden instances are accepted
+	data := "BEGIN:VCALENDAR\nVERSION:2.0\nBEGIN:VTIMEZONE\nTZID:America/New_York\nEND:VTIMEZONE\n" +
+		"BEGIN:VEVENT\nUID:1\nSUMMARY:Team meeting\\, weekly\nDTSTART;TZID=America/New_York:20160105T090000\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=TU,\n TH\nBEGIN:VALARM\nACTION:DISPLAY\nEND:VALARM\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nUID:1\nRECURRENCE-ID;TZID=America/New_York:20160107T090000\nDTSTART;TZID=America/New_York:20160107T100000\nEND:VEVENT\n" +
+		"END:VCALENDAR\n"
+	actual, err := FromICS([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, _ := time.LoadLocation("America/New_York")
+	expected := Series{Recurrence: Recurrence{StartDate: time.Date(2016, 1, 5, 9, 0, 0, 0, newYork), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(16 + 4)}}
+	compareSeries(t, &expected, actual, "TestFromICS")
+
+	unrepresentable := []string{
+		"BEGIN:VEVENT\r\nDTSTART:20160105T090000Z\r\nEND:VEVENT\r\n",
+		"BEGIN:VEVENT\r\nDTSTART:20160105T090000Z\r\nRRULE:FREQ=DAILY\r\nRDATE:20160110T090000Z\r\nEND:VEVENT\r\n",
+		"BEGIN:VEVENT\r\nDTSTART:20160105T090000Z\r\nRRULE:FREQ=DAILY\r\nEXRULE:FREQ=WEEKLY\r\nEND:VEVENT\r\n",
+	}
+	for _, ics := range unrepresentable {
+		if _, err := FromICS([]byte(ics)); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %q to be unrepresentable: %v", ics, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VEVENT\r\nRRULE:FREQ=DAILY\r\n",
+		"DTSTART:20160105T090000Z\r\n",
+		"BEGIN:VEVENT\r\nRRULE:FREQ=DAILY\r\nEND:VEVENT\r\n",
+		"BEGIN:VEVENT\r\nDTSTART;TZID=Nowhere/Special:20160105T090000\r\nRRULE:FREQ=DAILY\r\nEND:VEVENT\r\n",
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20160105T090000\r\nRRULE:FREQ=DAILY\r\nEND:VEVENT\r\n",
+		"BEGIN:VEVENT\r\nDTSTART:20160105T090000Z\r\nRRULE:FREQ=DAILY\r\nEND:VEVENT\r\nBEGIN:VEVENT\r\nEND:VEVENT\r\n",
+	}
+	for _, ics := range invalid {
+		if _, err := FromICS([]byte(ics)); err == nil || errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %q to be invalid: %v", ics, err)
+		}
+	}
+}
+
+func TestICSContentLines(t *testing.T) {
+	s := Series{Recurrence: Recurrence{StartDate: time.Date(2016, 1, 5, 9, 0, 0, 0, time.Local), RecurrencePatternCode: "D", RecurEvery: 1}}
+	data, err := s.ToICS("a;b,c\\d " + strings.Repeat("x", 80))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\r\n")
+	for _, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("expected %q to be folded", line)
+		}
+	}
+	if lines[4] != `UID:a\;b\,c\\d `+strings.Repeat("x", 60) || lines[5] != " "+strings.Repeat("x", 20) {
+		t.Error("expected escaped and folded UID", lines[4], lines[5])
+	}
+	if lines[6] != "DTSTART:20160105T090000" {
+		t.Error("expected floating DTSTART for local time", lines[6])
+	}
+
+	calendar, err := parseICS(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uid := calendar.components[0].properties[0]
+	if len(uid.values) != 1 || uid.values[0] != "a;b,c\\d "+strings.Repeat("x", 80) {
+		t.Error("expected unescaped UID", uid.values)
+	}
+}
+
+func readTestData(t *testing.T, dir, file string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", dir, file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
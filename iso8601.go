@@ -0,0 +1,284 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// isoDateTimeLayouts are the extended and basic ISO 8601 forms accepted for the start of a repeating interval
+var isoDateTimeLayouts = []string{
+	"2006-01-02T15:04:05Z07:00", "2006-01-02T15:04Z07:00", "2006-01-02",
+	"20060102T150405Z0700", "20060102T1504Z0700", "20060102",
+}
+
+// isoLocalDateTimeLayouts are the ISO 8601 date-time forms without a UTC offset
+var isoLocalDateTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "20060102T150405", "20060102T1504"}
+
+// ISODuration is an ISO 8601 duration such as P1Y2M, P2W or PT36H
+type ISODuration struct {
+	Years, Months, Weeks, Days, Hours, Minutes, Seconds int
+}
+
+// ParseISODuration parses an ISO 8601 duration in the PnYnMnWnDTnHnMnS form. Fractions of a component are valid ISO
+// 8601 but have no whole number equivalent and return ErrNotRepresentable
+func ParseISODuration(value string) (ISODuration, error) {
+	var d ISODuration
+	rest, ok := strings.CutPrefix(value, "P")
+	if !ok || rest == "" || strings.HasSuffix(rest, "T") {
+		return d, fmt.Errorf("invalid ISO 8601 duration %q", value)
+	}
+	units, inTime := "YMWD", false
+	for rest != "" {
+		if rest[0] == 'T' && !inTime {
+			units, inTime, rest = "HMS", true, rest[1:]
+			continue
+		}
+		end := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' && r != ',' })
+		if end < 1 {
+			return d, fmt.Errorf("invalid ISO 8601 duration %q", value)
+		}
+		number, unit := rest[:end], rest[end]
+		if strings.ContainsAny(number, ".,") {
+			return d, fmt.Errorf("%w: ISO 8601 duration %q has a fraction", ErrNotRepresentable, value)
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return d, fmt.Errorf("invalid ISO 8601 duration %q: %w", value, err)
+		}
+		// each unit may appear once and in order
+		i := strings.IndexByte(units, unit)
+		if i < 0 {
+			return d, fmt.Errorf("invalid ISO 8601 duration %q", value)
+		}
+		units = units[i+1:]
+		switch {
+		case inTime && unit == 'H':
+			d.Hours = n
+		case inTime && unit == 'M':
+			d.Minutes = n
+		case inTime && unit == 'S':
+			d.Seconds = n
+		case unit == 'Y':
+			d.Years = n
+		case unit == 'M':
+			d.Months = n
+		case unit == 'W':
+			d.Weeks = n
+		case unit == 'D':
+			d.Days = n
+		}
+		rest = rest[end+1:]
+	}
+	return d, nil
+}
+
+// String returns the duration in the PnYnMnWnDTnHnMnS form, leaving out zero components. The zero duration is PT0S
+func (d ISODuration) String() string {
+	var b strings.Builder
+	b.WriteByte('P')
+	for _, component := range []struct {
+		n    int
+		unit string
+	}{{d.Years, "Y"}, {d.Months, "M"}, {d.Weeks, "W"}, {d.Days, "D"}} {
+		if component.n != 0 {
+			b.WriteString(strconv.Itoa(component.n) + component.unit)
+		}
+	}
+	if d.Hours != 0 || d.Minutes != 0 || d.Seconds != 0 || b.Len() == 1 {
+		b.WriteByte('T')
+		for _, component := range []struct {
+			n    int
+			unit string
+		}{{d.Hours, "H"}, {d.Minutes, "M"}, {d.Seconds, "S"}} {
+			if component.n != 0 {
+				b.WriteString(strconv.Itoa(component.n) + component.unit)
+			}
+		}
+		if d.Hours == 0 && d.Minutes == 0 && d.Seconds == 0 {
+			b.WriteString("0S")
+		}
+	}
+	return b.String()
+}
+
+// ParseRepeatingInterval converts an ISO 8601 repeating interval in the R[n]/start/duration form, such as
+// R12/2024-01-05T09:00:00Z/P1M, to a Recurrence starting at start with n as NumberOfOccurrences, which also sets the
+// EndByDate to the last occurrence. Times without a UTC offset are in loc. Years and months combine into a monthly
+// interval and weeks and days into a daily interval, but durations mixing the two or with hours, minutes or seconds
+// return ErrNotRepresentable, as do the start/end and duration/end forms. Adding months to the 31st lands on the
+// last day of shorter months, which is a MonthlyDay of -1, but a day only some of the months lack, such as the 30th
+// every month, returns ErrNotRepresentable
+func ParseRepeatingInterval(value string, loc *time.Location) (*Recurrence, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "R") {
+		return nil, fmt.Errorf("invalid ISO 8601 repeating interval %q", value)
+	}
+	r := &Recurrence{}
+	count := 0
+	if repetitions := parts[0][1:]; repetitions != "" {
+		var err error
+		count, err = strconv.Atoi(repetitions)
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid ISO 8601 repetitions %q", repetitions)
+		}
+		if count > 32767 {
+			return nil, fmt.Errorf("%w: %d repetitions is out of range", ErrNotRepresentable, count)
+		}
+	}
+	if strings.HasPrefix(parts[1], "P") || !strings.HasPrefix(parts[2], "P") {
+		return nil, fmt.Errorf("%w: ISO 8601 repeating interval %q does not have a start and a duration", ErrNotRepresentable, value)
+	}
+	var err error
+	if r.StartDate, err = parseISODateTime(parts[1], loc); err != nil {
+		return nil, err
+	}
+	d, err := ParseISODuration(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	if d.Hours != 0 || d.Minutes != 0 || d.Seconds != 0 {
+		return nil, fmt.Errorf("%w: ISO 8601 duration %s is not a whole number of days", ErrNotRepresentable, parts[2])
+	}
+	months, days := d.Years*12+d.Months, d.Weeks*7+d.Days
+	var every int
+	switch {
+	case months != 0 && days != 0:
+		return nil, fmt.Errorf("%w: ISO 8601 duration %s mixes months and days", ErrNotRepresentable, parts[2])
+	case d.Months == 0 && d.Years != 0:
+		every = d.Years
+		yearlyMonth := int16(r.StartDate.Month())
+		r.RecurrencePatternCode, r.YearlyMonth = FrequencyYearly, &yearlyMonth
+	case months != 0:
+		every = months
+		r.RecurrencePatternCode = FrequencyMonthly
+	case d.Days == 0 && d.Weeks != 0:
+		every = d.Weeks
+		weeklyDaysIncluded := weekdayBit(r.StartDate.Weekday())
+		r.RecurrencePatternCode, r.WeeklyDaysIncluded = FrequencyWeekly, &weeklyDaysIncluded
+	case days != 0:
+		every = days
+		r.RecurrencePatternCode = FrequencyDaily
+	default:
+		return nil, fmt.Errorf("invalid ISO 8601 repeating interval %q: the duration is zero", value)
+	}
+	if every > 32767 {
+		return nil, fmt.Errorf("%w: ISO 8601 duration %s is out of range", ErrNotRepresentable, parts[2])
+	}
+	r.RecurEvery = int16(every)
+	if months != 0 {
+		monthlyDay, err := isoMonthlyDay(r.StartDate, months)
+		if err != nil {
+			return nil, err
+		}
+		r.MonthlyDay = &monthlyDay
+	}
+	if count > 0 {
+		if err := r.SetNumberOfOccurrences(count); err != nil {
+			return nil, fmt.Errorf("invalid ISO 8601 repetitions: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// RepeatingInterval returns the recurrence as an ISO 8601 repeating interval in the R[n]/start/duration form. start is
+// written in UTC for time.UTC, without an offset for time.Local and with its UTC offset otherwise. An EndByDate is
+// written as the number of occurrences up to it. Only recurrences that repeat by adding the duration to StartDate,
+// that is daily, weekly on the StartDate day of the week and monthly or yearly on the StartDate day of the month or
+// the MonthlyDay ParseRepeatingInterval reads for it, can be written and others return ErrNotRepresentable. So do
+// recurrences that end before their first occurrence, since the repetitions must be at least 1
+func (r *Recurrence) RepeatingInterval() (string, error) {
+	if r.RecurEvery < 1 {
+		return "", fmt.Errorf("%w: interval must be at least 1, got %d", ErrNotRepresentable, r.RecurEvery)
+	}
+	every := int(r.RecurEvery)
+	var d ISODuration
+	switch r.RecurrencePatternCode {
+	case FrequencyDaily:
+		if r.DailyIsOnlyWeekday != nil && *r.DailyIsOnlyWeekday {
+			return "", fmt.Errorf("%w: ISO 8601 repeating intervals cannot skip weekends", ErrNotRepresentable)
+		}
+		d.Days = every
+	case FrequencyWeekly:
+		if r.WeeklyDaysIncluded == nil || *r.WeeklyDaysIncluded&127 != weekdayBit(r.StartDate.Weekday()) {
+			return "", fmt.Errorf("%w: ISO 8601 repeating intervals recur on the StartDate day of the week only", ErrNotRepresentable)
+		}
+		d.Weeks = every
+	case FrequencyMonthly, FrequencyYearly:
+		months := every
+		if r.RecurrencePatternCode == FrequencyMonthly {
+			d.Months = every
+		} else if r.YearlyMonth == nil || time.Month(*r.YearlyMonth) != r.StartDate.Month() {
+			return "", fmt.Errorf("%w: ISO 8601 repeating intervals recur in the StartDate month only", ErrNotRepresentable)
+		} else {
+			d.Years, months = every, 12*every
+		}
+		if monthlyDay, err := isoMonthlyDay(r.StartDate, months); err != nil || r.MonthlyDay == nil || *r.MonthlyDay != monthlyDay || r.MonthlyNearestWeekday != nil && *r.MonthlyNearestWeekday {
+			return "", fmt.Errorf("%w: ISO 8601 repeating intervals recur on the StartDate day of the month only", ErrNotRepresentable)
+		}
+	default:
+		return "", fmt.Errorf("%w: unknown recurrence pattern code %q", ErrNotRepresentable, r.RecurrencePatternCode)
+	}
+
+	count := 0
+	switch {
+	case r.NumberOfOccurrences != nil:
+		count = int(*r.NumberOfOccurrences)
+	case r.EndByDate != nil:
+		count = r.CountUntil(*r.EndByDate)
+	}
+	repetitions := ""
+	if r.NumberOfOccurrences != nil || r.EndByDate != nil {
+		if count < 1 {
+			return "", fmt.Errorf("%w: ISO 8601 repeating intervals repeat at least once, the recurrence ends before its first occurrence", ErrNotRepresentable)
+		}
+		repetitions = strconv.Itoa(count)
+	}
+	return "R" + repetitions + "/" + formatISODateTime(r.StartDate) + "/" + d.String(), nil
+}
+
+// isoMonthlyDay returns the MonthlyDay of a recurrence adding months to start, which moves a day that a month does
+// not have back to the last day of the month. That is the day of start when every month it reaches has that day, or
+// -1 when none of them is longer
+func isoMonthlyDay(start time.Time, months int) (int16, error) {
+	day, shortest, longest := start.Day(), 31, 28
+	for i := 0; i < 12; i++ {
+		month := time.Month((int(start.Month())-1+i*months)%12 + 1)
+		shortest, longest = min(shortest, daysIn(month, 2023)), max(longest, daysIn(month, 2024))
+	}
+	switch {
+	case day <= shortest:
+		return int16(day), nil
+	case day >= longest:
+		return -1, nil
+	}
+	return 0, fmt.Errorf("%w: adding months to day %d of %s moves it back in some months only", ErrNotRepresentable, day, start.Month())
+}
+
+func parseISODateTime(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range isoDateTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			if len(layout) == len("20060102") || len(layout) == len("2006-01-02") {
+				t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+			}
+			return t, nil
+		}
+	}
+	for _, layout := range isoLocalDateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid ISO 8601 date-time %q", value)
+}
+
+func formatISODateTime(t time.Time) string {
+	switch t.Location() {
+	case time.UTC:
+		return t.Format("2006-01-02T15:04:05Z")
+	case time.Local:
+		return t.Format("2006-01-02T15:04:05")
+	}
+	return t.Format("2006-01-02T15:04:05-07:00")
+}
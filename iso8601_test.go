@@ -0,0 +1,192 @@
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseISODuration(t *testing.T) {
+	durations := []struct {
+		value    string
+		expected ISODuration
+		text     string
+	}{
+		{"P1Y2M3DT4H5M6S", ISODuration{Years: 1, Months: 2, Days: 3, Hours: 4, Minutes: 5, Seconds: 6}, "P1Y2M3DT4H5M6S"},
+		{"P2W", ISODuration{Weeks: 2}, "P2W"},
+		{"P1M", ISODuration{Months: 1}, "P1M"},
+		{"PT1M", ISODuration{Minutes: 1}, "PT1M"},
+		{"PT36H", ISODuration{Hours: 36}, "PT36H"},
+		{"P0D", ISODuration{}, "PT0S"},
+		{"P1Y0M", ISODuration{Years: 1}, "P1Y"},
+	}
+	for _, duration := range durations {
+		actual, err := ParseISODuration(duration.value)
+		if err != nil {
+			t.Error(duration.value, err)
+		} else if actual != duration.expected {
+			t.Errorf("%s: expected %+v vs actual %+v", duration.value, duration.expected, actual)
+		}
+		if text := duration.expected.String(); text != duration.text {
+			t.Errorf("expected %s vs actual %s", duration.text, text)
+		}
+	}
+
+	if _, err := ParseISODuration("P1.5D"); !errors.Is(err, ErrNotRepresentable) {
+		t.Error("expected fraction to be unrepresentable", err)
+	}
+	invalid := []string{"", "P", "PT", "P1DT", "1D", "P1", "PD", "P1M1Y", "P1D1D", "P1H", "PT1D", "P1DT2H3H", "p1d", "P-1D"}
+	for _, value := range invalid {
+		if _, err := ParseISODuration(value); err == nil || errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be invalid: %v", value, err)
+		}
+	}
+}
+
+func TestParseRepeatingInterval(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	intervals := []struct {
+		value    string
+		expected Recurrence
+	}{
+		{"R12/2024-01-05T09:00:00Z/P1M", Recurrence{StartDate: time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 1,
+			MonthlyDay: int16Ptr(5), NumberOfOccurrences: int16Ptr(12), EndByDate: timePtr(time.Date(2024, 12, 5, 0, 0, 0, 0, time.UTC))}},
+		{"R/2024-01-01/P2W", Recurrence{StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, newYork), RecurrencePatternCode: "W", RecurEvery: 2,
+			WeeklyDaysIncluded: int16Ptr(32)}},
+		{"R5/2024-01-05T09:00/P3D", Recurrence{StartDate: time.Date(2024, 1, 5, 9, 0, 0, 0, newYork), RecurrencePatternCode: "D", RecurEvery: 3,
+			NumberOfOccurrences: int16Ptr(5), EndByDate: timePtr(time.Date(2024, 1, 17, 0, 0, 0, 0, newYork))}},
+		{"R/20240105T090000Z/P1W3D", Recurrence{StartDate: time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC), RecurrencePatternCode: "D", RecurEvery: 10}},
+		{"R/2024-02-29/P4Y", Recurrence{StartDate: time.Date(2024, 2, 29, 0, 0, 0, 0, newYork), RecurrencePatternCode: "Y", RecurEvery: 4,
+			YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(-1)}},
+		{"R3/2024-01-31/P1M", Recurrence{StartDate: time.Date(2024, 1, 31, 0, 0, 0, 0, newYork), RecurrencePatternCode: "M", RecurEvery: 1,
+			MonthlyDay: int16Ptr(-1), NumberOfOccurrences: int16Ptr(3), EndByDate: timePtr(time.Date(2024, 3, 31, 0, 0, 0, 0, newYork))}},
+		{"R/2024-01-30/P12M", Recurrence{StartDate: time.Date(2024, 1, 30, 0, 0, 0, 0, newYork), RecurrencePatternCode: "M", RecurEvery: 12,
+			MonthlyDay: int16Ptr(30)}},
+		{"R/2024-03-31/P2M", Recurrence{StartDate: time.Date(2024, 3, 31, 0, 0, 0, 0, newYork), RecurrencePatternCode: "M", RecurEvery: 2,
+			MonthlyDay: int16Ptr(-1)}},
+		{"R/2024-01-15/P1Y6M", Recurrence{StartDate: time.Date(2024, 1, 15, 0, 0, 0, 0, newYork), RecurrencePatternCode: "M", RecurEvery: 18,
+			MonthlyDay: int16Ptr(15)}},
+	}
+	for _, interval := range intervals {
+		actual, err := ParseRepeatingInterval(interval.value, newYork)
+		if err != nil {
+			t.Error(interval.value, err)
+			continue
+		}
+		compareRecurrences(t, &interval.expected, actual, interval.value)
+		if interval.expected.NumberOfOccurrences != nil {
+			compareOccurrenceCount(t, int(*interval.expected.NumberOfOccurrences), actual, interval.value)
+		}
+	}
+
+	monthEnd, err := ParseRepeatingInterval("R4/2024-01-31/P1M", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []time.Time{time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, monthEnd.GetOccurrences(monthEnd.StartDate, monthEnd.StartDate.AddDate(1, 0, 0)), "TestParseRepeatingInterval, P1M from the 31st")
+
+	offset, err := ParseRepeatingInterval("R/2024-01-05T09:00:00+05:30/P1D", newYork)
+	if err != nil || !offset.StartDate.Equal(time.Date(2024, 1, 5, 3, 30, 0, 0, time.UTC)) {
+		t.Error("expected the UTC offset to be kept", offset, err)
+	}
+
+	unrepresentable := []string{"R/2024-01-05T09:00:00Z/P1M15D", "R/2024-01-05T09:00:00Z/PT12H", "R/2024-01-05T09:00:00Z/P1DT12H",
+		"R/2024-01-05T09:00:00Z/2024-01-06T09:00:00Z", "R/P1D/2024-01-05T09:00:00Z", "R40000/2024-01-05/P1D", "R/2024-01-05/P0.5D",
+		"R/2024-01-30/P1M", "R/2024-01-29/P1M", "R/2024-11-30/P3M"}
+	for _, value := range unrepresentable {
+		if _, err := ParseRepeatingInterval(value, time.UTC); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be unrepresentable: %v", value, err)
+		}
+	}
+	invalid := []string{"", "R", "R/2024-01-05", "2024-01-05/P1D", "R0/2024-01-05/P1D", "Rx/2024-01-05/P1D", "R/2024-13-05/P1D",
+		"R/2024-01-05/P0D", "R/2024-01-05/P1D/P1D"}
+	for _, value := range invalid {
+		if _, err := ParseRepeatingInterval(value, time.UTC); err == nil || errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be invalid: %v", value, err)
+		}
+	}
+}
+
+func TestRepeatingInterval(t *testing.T) {
+	start := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC) // Friday
+	recurrences := []struct {
+		recurrence Recurrence
+		value      string
+	}{
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 3, NumberOfOccurrences: int16Ptr(5)}, "R5/2024-01-05T09:00:00Z/P3D"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(2)}, "R/2024-01-05T09:00:00Z/P2W"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(5), NumberOfOccurrences: int16Ptr(12)}, "R12/2024-01-05T09:00:00Z/P1M"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(1), MonthlyDay: int16Ptr(5)}, "R/2024-01-05T09:00:00Z/P1Y"},
+		{Recurrence{StartDate: time.Date(2024, 1, 5, 9, 0, 0, 0, time.Local), RecurrencePatternCode: "D", RecurEvery: 1}, "R/2024-01-05T09:00:00/P1D"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(2), EndByDate: timePtr(time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC))}, "R4/2024-01-05T09:00:00Z/P1W"},
+	}
+	for _, recurrence := range recurrences {
+		actual, err := recurrence.recurrence.RepeatingInterval()
+		if err != nil {
+			t.Error(recurrence.value, err)
+		} else if actual != recurrence.value {
+			t.Errorf("expected %s vs actual %s", recurrence.value, actual)
+		}
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Recurrence{StartDate: time.Date(2024, 7, 5, 9, 0, 0, 0, newYork), RecurrencePatternCode: "D", RecurEvery: 1}
+	if actual, err := r.RepeatingInterval(); err != nil || actual != "R/2024-07-05T09:00:00-04:00/P1D" {
+		t.Error("expected the UTC offset of StartDate", actual, err)
+	}
+
+	unrepresentable := []Recurrence{
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)},
+		{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(32 + 2)},
+		{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(32)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(5), MonthlyWeekOfMonth: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(5), MonthlyNearestWeekday: boolPtr(true)},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(5)},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 0},
+		{StartDate: start, RecurrencePatternCode: "X", RecurEvery: 1},
+	}
+	for i, r := range unrepresentable {
+		if _, err := r.RepeatingInterval(); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected recurrence %d to be unrepresentable: %v", i, err)
+		}
+	}
+
+	noOccurrences := []Recurrence{
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(5), EndByDate: timePtr(start.AddDate(0, 0, -1))},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, NumberOfOccurrences: int16Ptr(0)},
+	}
+	for i, r := range noOccurrences {
+		if actual, err := r.RepeatingInterval(); err == nil {
+			t.Errorf("expected recurrence %d without occurrences to be an error, got %s", i, actual)
+		}
+	}
+}
+
+// TestRepeatingIntervalRoundTrip checks that the repeating intervals written for recurrences read back as them
+func TestRepeatingIntervalRoundTrip(t *testing.T) {
+	values := []string{"R5/2024-01-05T09:00:00Z/P3D", "R/2024-01-05T09:00:00Z/P2W", "R12/2024-01-05T09:00:00Z/P1M", "R3/2024-01-31T00:00:00Z/P1M",
+		"R/2024-02-29T00:00:00Z/P1Y", "R/2024-01-30T00:00:00Z/P12M", "R2/2024-08-31T00:00:00Z/P6M"}
+	for _, value := range values {
+		r, err := ParseRepeatingInterval(value, time.UTC)
+		if err != nil {
+			t.Error(value, err)
+			continue
+		}
+		if actual, err := r.RepeatingInterval(); err != nil || actual != value {
+			t.Errorf("expected %s vs actual %s: %v", value, actual, err)
+		}
+		r.NumberOfOccurrences = nil // the same number of occurrences from the EndByDate
+		if actual, err := r.RepeatingInterval(); err != nil || actual != value {
+			t.Errorf("expected %s from the EndByDate vs actual %s: %v", value, actual, err)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// rruleIntegerParts are the RECUR rule parts that jCal writes as numbers
+var rruleIntegerParts = map[string]bool{
+	"COUNT": true, "INTERVAL": true, "BYSECOND": true, "BYMINUTE": true, "BYHOUR": true, "BYMONTHDAY": true,
+	"BYYEARDAY": true, "BYWEEKNO": true, "BYMONTH": true, "BYSETPOS": true,
+}
+
+// ToJCal returns the series as a jCal (RFC 7265) vcalendar holding one vevent with the given UID
+func (s *Series) ToJCal(uid string) ([]byte, error) {
+	calendar, err := s.icalCalendar(uid)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jcalComponent(calendar))
+}
+
+// FromJCal reads the first vevent of a jCal (RFC 7265) vcalendar as a Series
+func FromJCal(data []byte) (*Series, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("invalid jcal: %w", err)
+	}
+	calendar, err := parseJCalComponent(value)
+	if err != nil {
+		return nil, err
+	}
+	return seriesFromICalCalendar(calendar)
+}
+
+// jcalComponent returns the [name, properties, components] array of a component
+func jcalComponent(c *icalComponent) []interface{} {
+	properties := []interface{}{}
+	for _, property := range c.properties {
+		params := map[string]string{}
+		for name, value := range property.params {
+			params[strings.ToLower(name)] = value
+		}
+		item := []interface{}{strings.ToLower(property.name), params, strings.ToLower(property.valueType)}
+		for _, value := range property.values {
+			switch property.valueType {
+			case "DATE", "DATE-TIME":
+				item = append(item, icalExtendedDateTime(value))
+			case "RECUR":
+				item = append(item, jcalRecur(value))
+			default:
+				item = append(item, value)
+			}
+		}
+		properties = append(properties, item)
+	}
+	components := []interface{}{}
+	for i := range c.components {
+		components = append(components, jcalComponent(&c.components[i]))
+	}
+	return []interface{}{strings.ToLower(c.name), properties, components}
+}
+
+// jcalRecur converts an RRULE value to a jCal recur object. Parts with more than one value become arrays
+func jcalRecur(value string) map[string]interface{} {
+	recur := map[string]interface{}{}
+	for _, part := range strings.Split(value, ";") {
+		name, partValue, _ := strings.Cut(part, "=")
+		var items []interface{}
+		for _, item := range strings.Split(partValue, ",") {
+			if i, err := strconv.Atoi(item); err == nil && rruleIntegerParts[name] {
+				items = append(items, i)
+			} else if name == "UNTIL" {
+				items = append(items, icalExtendedDateTime(item))
+			} else {
+				items = append(items, item)
+			}
+		}
+		if len(items) == 1 {
+			recur[strings.ToLower(name)] = items[0]
+		} else {
+			recur[strings.ToLower(name)] = items
+		}
+	}
+	return recur
+}
+
+func parseJCalComponent(value interface{}) (*icalComponent, error) {
+	items, ok := value.([]interface{})
+	if !ok || len(items) != 3 {
+		return nil, fmt.Errorf("jcal component must be a [name, properties, components] array")
+	}
+	name, ok := items[0].(string)
+	properties, propertiesOk := items[1].([]interface{})
+	components, componentsOk := items[2].([]interface{})
+	if !ok || !propertiesOk || !componentsOk {
+		return nil, fmt.Errorf("jcal component must be a [name, properties, components] array")
+	}
+	c := &icalComponent{name: strings.ToUpper(name)}
+	for _, item := range properties {
+		property, err := parseJCalProperty(item)
+		if err != nil {
+			return nil, err
+		}
+		c.properties = append(c.properties, property)
+	}
+	for _, item := range components {
+		component, err := parseJCalComponent(item)
+		if err != nil {
+			return nil, err
+		}
+		c.components = append(c.components, *component)
+	}
+	return c, nil
+}
+
+func parseJCalProperty(value interface{}) (icalProperty, error) {
+	items, ok := value.([]interface{})
+	if !ok || len(items) < 4 {
+		return icalProperty{}, fmt.Errorf("jcal property must be a [name, parameters, type, value...] array")
+	}
+	name, ok := items[0].(string)
+	params, paramsOk := items[1].(map[string]interface{})
+	valueType, valueTypeOk := items[2].(string)
+	if !ok || !paramsOk || !valueTypeOk {
+		return icalProperty{}, fmt.Errorf("jcal property must be a [name, parameters, type, value...] array")
+	}
+	property := icalProperty{name: strings.ToUpper(name), params: map[string]string{}, valueType: strings.ToUpper(valueType)}
+	for paramName, paramValue := range params {
+		property.params[strings.ToUpper(paramName)] = strings.Join(jcalStrings(paramValue), ",")
+	}
+	for _, item := range items[3:] {
+		switch property.valueType {
+		case "DATE", "DATE-TIME":
+			s, ok := item.(string)
+			if !ok {
+				return icalProperty{}, fmt.Errorf("jcal %s value must be a string", name)
+			}
+			property.values = append(property.values, icalBasicDateTime(s))
+		case "RECUR":
+			recur, ok := item.(map[string]interface{})
+			if !ok {
+				return icalProperty{}, fmt.Errorf("jcal %s value must be an object", name)
+			}
+			property.values = append(property.values, jcalRRule(recur))
+		default:
+			property.values = append(property.values, jcalStrings(item)...)
+		}
+	}
+	return property, nil
+}
+
+// jcalRRule converts a jCal recur object to an RRULE value
+func jcalRRule(recur map[string]interface{}) string {
+	names := make([]string, 0, len(recur))
+	for name := range recur {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		values := jcalStrings(recur[name])
+		if strings.EqualFold(name, "until") {
+			for j := range values {
+				values[j] = icalBasicDateTime(values[j])
+			}
+		}
+		parts[i] = strings.ToUpper(name) + "=" + strings.Join(values, ",")
+	}
+	return strings.Join(parts, ";")
+}
+
+// jcalStrings returns the text of a jCal value, or of each item when it is an array
+func jcalStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, jcalStrings(item)...)
+		}
+		return values
+	case string:
+		return []string{v}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,98 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestJCalFixtures(t *testing.T) {
+	for _, fixture := range icalFixtures(t) {
+		file := fixture.name + ".json"
+		data := readTestData(t, "jcal", file)
+		actual, err := FromJCal(data)
+		if err != nil {
+			t.Error(file, err)
+			continue
+		}
+		compareSeries(t, &fixture.expected, actual, file)
+		if fixture.expected.NumberOfOccurrences != nil {
+			compareOccurrenceCount(t, int(*fixture.expected.NumberOfOccurrences), &actual.Recurrence, file)
+		}
+
+		exported, err := fixture.expected.ToJCal(fixture.uid)
+		if err != nil {
+			t.Error(file, err)
+			continue
+		}
+		var expectedJSON, actualJSON interface{}
+		if err := json.Unmarshal(data, &expectedJSON); err != nil {
+			t.Fatal(file, err)
+		}
+		if err := json.Unmarshal(exported, &actualJSON); err != nil {
+			t.Fatal(file, err)
+		}
+		if !reflect.DeepEqual(expectedJSON, actualJSON) {
+			t.Errorf("%s: expected\n%s\nvs actual\n%s", file, data, exported)
+		}
+	}
+}
+
+func TestICalFormatsInterchangeable(t *testing.T) {
+	for _, fixture := range icalFixtures(t) {
+		fromICS, err := FromICS(readTestData(t, "ical", fixture.name+".ics"))
+		if err != nil {
+			t.Fatal(fixture.name, err)
+		}
+		jcal, err := fromICS.ToJCal(fixture.uid)
+		if err != nil {
+			t.Fatal(fixture.name, err)
+		}
+		fromJCal, err := FromJCal(jcal)
+		if err != nil {
+			t.Fatal(fixture.name, err)
+		}
+		xcal, err := fromJCal.ToXCal(fixture.uid)
+		if err != nil {
+			t.Fatal(fixture.name, err)
+		}
+		fromXCal, err := FromXCal(xcal)
+		if err != nil {
+			t.Fatal(fixture.name, err)
+		}
+		ics, err := fromXCal.ToICS(fixture.uid)
+		if err != nil {
+			t.Fatal(fixture.name, err)
+		}
+		if expected := readTestData(t, "ical", fixture.name+".ics"); string(ics) != string(expected) {
+			t.Errorf("%s: expected\n%s\nvs actual\n%s", fixture.name, expected, ics)
+		}
+	}
+}
+
+func TestFromJCalErrors(t *testing.T) {
+	unrepresentable := []string{
+		`["vcalendar", [], [["vevent", [["dtstart", {}, "date", "2016-01-05"], ["rrule", {}, "recur", {"freq": "DAILY"}], ["rdate", {}, "date", "2016-01-10"]], []]]]`,
+		`["vcalendar", [], [["vevent", [["dtstart", {}, "date", "2016-01-05"], ["rrule", {}, "recur", {"freq": "MONTHLY", "bysetpos": -1, "byday": ["MO", "FR"]}]], []]]]`,
+	}
+	for _, jcal := range unrepresentable {
+		if _, err := FromJCal([]byte(jcal)); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be unrepresentable: %v", jcal, err)
+		}
+	}
+
+	invalid := []string{
+		`{}`,
+		`["vcalendar", []]`,
+		`["vcalendar", [["version", {}, "text"]], []]`,
+		`["vcalendar", [], [["vevent", [["dtstart", {}, "date", 20160105]], []]]]`,
+		`["vcalendar", [], [["vevent", [["dtstart", {}, "date", "2016-01-05"], ["rrule", {}, "recur", "FREQ=DAILY"]], []]]]`,
+		`["vcalendar", [], []]`,
+	}
+	for _, jcal := range invalid {
+		if _, err := FromJCal([]byte(jcal)); err == nil || errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be invalid: %v", jcal, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLimitExceeded is wrapped by the LimitError returned when expanding a recurrence would exceed its Limits
+var ErrLimitExceeded = errors.New("recurrence expansion limit exceeded")
+
+// Limits bounds the expansion of user supplied recurrences by GetOccurrencesContext. A zero field is not limited
+type Limits struct {
+	MaxOccurrences int           // most occurrences returned by one expansion
+	MaxWindow      time.Duration // longest time period expanded, from timePeriodStart to timePeriodEnd
+}
+
+// DefaultLimits is used by GetOccurrencesContext when it is given nil Limits. It allows a daily recurrence to be
+// expanded over 100 years
+var DefaultLimits = &Limits{MaxOccurrences: 36525, MaxWindow: 36525 * 24 * time.Hour}
+
+// LimitError reports the Limits field an expansion would exceed
+type LimitError struct {
+	Limit string // name of the Limits field, MaxOccurrences or MaxWindow
+	Max   int64  // value of the Limits field, in nanoseconds for MaxWindow
+	Value int64  // number of occurrences or length of the time period that exceeds it
+}
+
+func (e *LimitError) Error() string {
+	if e.Limit == "MaxWindow" {
+		return fmt.Sprintf("%s: time period of %s exceeds MaxWindow of %s", ErrLimitExceeded, time.Duration(e.Value), time.Duration(e.Max))
+	}
+	return fmt.Sprintf("%s: %d occurrences exceed %s of %d", ErrLimitExceeded, e.Value, e.Limit, e.Max)
+}
+
+// Unwrap returns ErrLimitExceeded
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// GetOccurrencesContext is GetOccurrences for recurrences that cannot be trusted. It returns the error from
+// Validate for a recurrence that GetOccurrences cannot expand, a *LimitError when the time period or the number of
+// occurrences in it exceeds limits (DefaultLimits when nil) and the error of ctx once it is done, which is checked
+// while the occurrences are counted and expanded. The occurrences are counted before any are expanded, so an
+// expansion that would exceed MaxOccurrences allocates nothing
+func (r *Recurrence) GetOccurrencesContext(ctx context.Context, timePeriodStart, timePeriodEnd time.Time, limits *Limits) ([]time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if limits == nil {
+		limits = DefaultLimits
+	}
+	if window := timePeriodEnd.Sub(timePeriodStart); limits.MaxWindow > 0 && window > limits.MaxWindow {
+		return nil, &LimitError{Limit: "MaxWindow", Max: int64(limits.MaxWindow), Value: int64(window)}
+	}
+	if limits.MaxOccurrences > 0 {
+		count, err := r.count(ctx, timePeriodStart, timePeriodEnd)
+		if err != nil {
+			return nil, err
+		}
+		if count > limits.MaxOccurrences {
+			return nil, &LimitError{Limit: "MaxOccurrences", Max: int64(limits.MaxOccurrences), Value: int64(count)}
+		}
+	}
+	return r.occurrences(ctx, timePeriodStart, timePeriodEnd)
+}
+
+// GetOccurrencesContext is GetOccurrences with the safeguards of Recurrence.GetOccurrencesContext. Removed
+// ExceptionDates count towards MaxOccurrences
+func (s *Series) GetOccurrencesContext(ctx context.Context, timePeriodStart, timePeriodEnd time.Time, limits *Limits) ([]time.Time, error) {
+	occurrences, err := s.Recurrence.GetOccurrencesContext(ctx, timePeriodStart, timePeriodEnd, limits)
+	if err != nil {
+		return nil, err
+	}
+	return s.withoutExceptionDates(occurrences), nil
+}
@@ -0,0 +1,87 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetOccurrencesContext(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1}
+	occurrences, err := r.GetOccurrencesContext(context.Background(), start, start.AddDate(0, 0, 9), nil)
+	if err != nil || len(occurrences) != 10 {
+		t.Error("expected 10 occurrences", occurrences, err)
+	}
+
+	_, err = r.GetOccurrencesContext(context.Background(), start, start.AddDate(0, 0, 9), &Limits{MaxOccurrences: 9})
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != "MaxOccurrences" || limitErr.Max != 9 || limitErr.Value != 10 || !errors.Is(err, ErrLimitExceeded) {
+		t.Error("expected MaxOccurrences to be exceeded", err)
+	}
+	if err.Error() != "recurrence expansion limit exceeded: 10 occurrences exceed MaxOccurrences of 9" {
+		t.Error("unexpected error", err)
+	}
+
+	_, err = r.GetOccurrencesContext(context.Background(), start, start.AddDate(500, 0, 0), nil)
+	if !errors.As(err, &limitErr) || limitErr.Limit != "MaxWindow" || limitErr.Max != int64(DefaultLimits.MaxWindow) {
+		t.Error("expected the default MaxWindow to be exceeded", err)
+	}
+	if occurrences, err := r.GetOccurrencesContext(context.Background(), start, start.AddDate(500, 0, 0), &Limits{}); err != nil || len(occurrences) != 182622 {
+		t.Error("expected zero Limits not to limit", len(occurrences), err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.GetOccurrencesContext(ctx, start, start.AddDate(1, 0, 0), nil); !errors.Is(err, context.Canceled) {
+		t.Error("expected the context error", err)
+	}
+
+	// a long expansion is stopped once the context is done, whether it is counting or expanding the occurrences
+	monthly := Recurrence{StartDate: start, RecurrencePatternCode: FrequencyMonthly, RecurEvery: 1, MonthlyDay: int16Ptr(7)}
+	for _, test := range []struct {
+		r      Recurrence
+		limits *Limits
+	}{{r, &Limits{}}, {monthly, &Limits{}}, {monthly, &Limits{MaxOccurrences: 1 << 30}}} {
+		ctx := &countdownContext{Context: context.Background(), checks: 3}
+		if _, err := test.r.GetOccurrencesContext(ctx, start, start.AddDate(5000, 0, 0), test.limits); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected the context error, got %v", test.r.RecurrencePatternCode, err)
+		}
+		if ctx.calls > 5 {
+			t.Errorf("%s: expected the expansion to stop once the context was done, it was checked %d times", test.r.RecurrencePatternCode, ctx.calls)
+		}
+	}
+
+	// recurrences that would loop forever or panic in GetOccurrences
+	for _, r := range []Recurrence{
+		{StartDate: start, RecurrencePatternCode: "D"},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, MonthlyDay: int16Ptr(1)},
+	} {
+		if _, err := r.GetOccurrencesContext(context.Background(), start, start.AddDate(1, 0, 0), nil); err == nil {
+			t.Errorf("expected %+v to be invalid", r)
+		}
+		if occurrences := r.GetOccurrences(start, start.AddDate(1, 0, 0)); len(occurrences) != 0 {
+			t.Error("expected no occurrences", occurrences)
+		}
+	}
+
+	s := Series{Recurrence: r, ExceptionDates: []time.Time{start.AddDate(0, 0, 1)}}
+	if occurrences, err := s.GetOccurrencesContext(context.Background(), start, start.AddDate(0, 0, 9), nil); err != nil || len(occurrences) != 9 {
+		t.Error("expected 9 occurrences without the exception", occurrences, err)
+	}
+}
+
+// countdownContext is done after its Err has been called checks times
+type countdownContext struct {
+	context.Context
+	checks, calls int
+}
+
+func (c *countdownContext) Err() error {
+	c.calls++
+	if c.calls > c.checks {
+		return context.Canceled
+	}
+	return nil
+}
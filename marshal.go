@@ -0,0 +1,399 @@
+package calendar
+
+import (
+	"database/sql/driver"
+	_ "embed" // for the JSON Schemas
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RecurrenceJSONSchema is the JSON Schema (draft 2020-12) of the JSON a Recurrence marshals to
+//
+//go:embed recurrence.schema.json
+var RecurrenceJSONSchema []byte
+
+// SeriesJSONSchema is the JSON Schema (draft 2020-12) of the JSON a Series marshals to
+//
+//go:embed series.schema.json
+var SeriesJSONSchema []byte
+
+// recurrenceJSON is the JSON form of a Recurrence. Times are RFC 3339 in the time zone of StartDate, which is also
+// named by TimeZone unless it is UTC or a fixed offset
+type recurrenceJSON struct {
+	StartDate             time.Time  `json:"startDate"`
+	TimeZone              string     `json:"timeZone,omitempty"`
+	RecurrencePatternCode Frequency  `json:"recurrencePatternCode"`
+	RecurEvery            int16      `json:"recurEvery"`
+	YearlyMonth           *int16     `json:"yearlyMonth,omitempty"`
+	MonthlyWeekOfMonth    *int16     `json:"monthlyWeekOfMonth,omitempty"`
+	MonthlyDayOfWeek      *int16     `json:"monthlyDayOfWeek,omitempty"`
+	MonthlyDay            *int16     `json:"monthlyDay,omitempty"`
+	MonthlyNearestWeekday *bool      `json:"monthlyNearestWeekday,omitempty"`
+	WeeklyDaysIncluded    *Weekdays  `json:"weeklyDaysIncluded,omitempty"`
+	DailyIsOnlyWeekday    *bool      `json:"dailyIsOnlyWeekday,omitempty"`
+	EndByDate             *time.Time `json:"endByDate,omitempty"`
+	NumberOfOccurrences   *int16     `json:"numberOfOccurrences,omitempty"`
+}
+
+// seriesJSON is the JSON form of a Series: the fields of its Recurrence plus its own
+type seriesJSON struct {
+	recurrenceJSON
+	AllDay         bool        `json:"allDay,omitempty"`
+	Duration       string      `json:"duration,omitempty"` // ISO 8601 duration such as PT1H30M
+	ExceptionDates []time.Time `json:"exceptionDates,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler with the camel case field names and formats of RecurrenceJSONSchema.
+// WeeklyDaysIncluded is written as day names such as "MO,WE,FR". A struct embedding a Recurrence needs its own
+// MarshalJSON, Value and the methods reading them back, as Series has, or only the Recurrence fields are written
+func (r Recurrence) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newRecurrenceJSON(&r))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, restoring the time zone of StartDate from timeZone
+func (r *Recurrence) UnmarshalJSON(data []byte) error {
+	var j recurrenceJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	recurrence, err := j.recurrence()
+	if err != nil {
+		return err
+	}
+	*r = *recurrence
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler as RFC 5545 DTSTART and RRULE lines, e.g.
+// "DTSTART:20260105T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO". UNTIL is always a UTC DATE-TIME. Recurrences RRULE cannot
+// express return ErrNotRepresentable, and NumberOfOccurrences wins over EndByDate when both are set
+func (r Recurrence) MarshalText() ([]byte, error) {
+	rule, err := r.rrule(false)
+	if err != nil {
+		return nil, err
+	}
+	properties := []icalProperty{
+		newICalTimeProperty("DTSTART", false, r.StartDate),
+		{name: "RRULE", valueType: "RECUR", values: []string{rule.String()}},
+	}
+	return []byte(joinContentLines(properties)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, reading the DTSTART and RRULE lines MarshalText writes
+func (r *Recurrence) UnmarshalText(text []byte) error {
+	properties, err := parseContentLines(string(text))
+	if err != nil {
+		return err
+	}
+	var start, rule *icalProperty
+	for i := range properties {
+		switch properties[i].name {
+		case "DTSTART":
+			if start != nil {
+				return fmt.Errorf("recurrence text has more than one DTSTART")
+			}
+			start = &properties[i]
+		case "RRULE":
+			if rule != nil {
+				return fmt.Errorf("%w: recurrence text has more than one RRULE", ErrNotRepresentable)
+			}
+			rule = &properties[i]
+		default:
+			return fmt.Errorf("unexpected %s in recurrence text", properties[i].name)
+		}
+	}
+	if start == nil || rule == nil || len(rule.values) != 1 {
+		return fmt.Errorf("recurrence text must have a DTSTART and a single RRULE")
+	}
+	startTimes, _, err := start.times(time.Local)
+	if err != nil {
+		return err
+	}
+	if len(startTimes) != 1 {
+		return fmt.Errorf("recurrence DTSTART must have a single value")
+	}
+	recurrence, err := ParseRRule(rule.values[0], startTimes[0])
+	if err != nil {
+		return err
+	}
+	*r = *recurrence
+	return nil
+}
+
+// Scan implements sql.Scanner, reading a column written by Value or holding the text of MarshalText. NULL scans
+// as the zero Recurrence
+func (r *Recurrence) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*r = Recurrence{}
+		return nil
+	case []byte:
+		return r.scanString(string(value))
+	case string:
+		return r.scanString(value)
+	}
+	return fmt.Errorf("cannot scan %T into Recurrence", src)
+}
+
+func (r *Recurrence) scanString(value string) error {
+	if strings.HasPrefix(strings.TrimSpace(value), "{") {
+		return r.UnmarshalJSON([]byte(value))
+	}
+	return r.UnmarshalText([]byte(value))
+}
+
+// Value implements driver.Valuer, storing the recurrence as its JSON so that every field survives the round trip
+func (r Recurrence) Value() (driver.Value, error) {
+	data, err := r.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// MarshalJSON implements json.Marshaler with the fields of SeriesJSONSchema. Duration is an ISO 8601 duration of
+// hours, minutes and seconds
+func (s Series) MarshalJSON() ([]byte, error) {
+	if s.Duration < 0 || s.Duration%time.Second != 0 {
+		return nil, fmt.Errorf("duration %s is not a whole number of seconds", s.Duration)
+	}
+	j := seriesJSON{recurrenceJSON: *newRecurrenceJSON(&s.Recurrence), AllDay: s.AllDay, ExceptionDates: s.ExceptionDates}
+	if s.Duration != 0 {
+		seconds := int(s.Duration / time.Second)
+		j.Duration = ISODuration{Hours: seconds / 3600, Minutes: seconds / 60 % 60, Seconds: seconds % 60}.String()
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. ExceptionDates are placed in the time zone of StartDate
+func (s *Series) UnmarshalJSON(data []byte) error {
+	var j seriesJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	recurrence, err := j.recurrence()
+	if err != nil {
+		return err
+	}
+	series := Series{Recurrence: *recurrence, AllDay: j.AllDay}
+	if j.Duration != "" {
+		d, err := ParseISODuration(j.Duration)
+		if err != nil {
+			return fmt.Errorf("invalid duration: %w", err)
+		}
+		if d.Years != 0 || d.Months != 0 {
+			return fmt.Errorf("duration %s must not use years or months", j.Duration)
+		}
+		series.Duration = time.Duration((d.Weeks*7+d.Days)*24+d.Hours)*time.Hour + time.Duration(d.Minutes)*time.Minute + time.Duration(d.Seconds)*time.Second
+	}
+	for _, exceptionDate := range j.ExceptionDates {
+		series.ExceptionDates = append(series.ExceptionDates, exceptionDate.In(recurrence.StartDate.Location()))
+	}
+	*s = series
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler as the RFC 5545 DTSTART, DTEND, RRULE and EXDATE lines of the series
+func (s Series) MarshalText() ([]byte, error) {
+	properties, err := s.icalProperties()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(joinContentLines(properties)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, reading the lines MarshalText writes
+func (s *Series) UnmarshalText(text []byte) error {
+	properties, err := parseContentLines(string(text))
+	if err != nil {
+		return err
+	}
+	series, err := seriesFromICalProperties(properties)
+	if err != nil {
+		return err
+	}
+	*s = *series
+	return nil
+}
+
+// Scan implements sql.Scanner, reading a column written by Value or holding the text of MarshalText. NULL scans
+// as the zero Series
+func (s *Series) Scan(src interface{}) error {
+	var value string
+	switch src := src.(type) {
+	case nil:
+		*s = Series{}
+		return nil
+	case []byte:
+		value = string(src)
+	case string:
+		value = src
+	default:
+		return fmt.Errorf("cannot scan %T into Series", src)
+	}
+	if strings.HasPrefix(strings.TrimSpace(value), "{") {
+		return s.UnmarshalJSON([]byte(value))
+	}
+	return s.UnmarshalText([]byte(value))
+}
+
+// Value implements driver.Valuer, storing the series as its JSON so that every field survives the round trip
+func (s Series) Value() (driver.Value, error) {
+	data, err := s.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// MarshalJSON implements json.Marshaler as a string holding the text of MarshalText, so that the fields other than
+// the Recurrence survive the round trip
+func (p AppointmentRecurrencePattern) MarshalJSON() ([]byte, error) {
+	text, err := p.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading the string MarshalJSON writes
+func (p *AppointmentRecurrencePattern) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return p.UnmarshalText([]byte(text))
+}
+
+// MarshalText implements encoding.TextMarshaler as the base64 of the PidLidAppointmentRecur value of MarshalBinary.
+// Like the binary structure it has no time zone, so StartDate is read back in UTC
+func (p AppointmentRecurrencePattern) MarshalText() ([]byte, error) {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, reading the text MarshalText writes
+func (p *AppointmentRecurrencePattern) UnmarshalText(text []byte) error {
+	data, err := base64.StdEncoding.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid appointment recurrence pattern text: %w", err)
+	}
+	return p.UnmarshalBinary(data)
+}
+
+// Scan implements sql.Scanner, reading the PidLidAppointmentRecur value written by Value or the text of
+// MarshalText. NULL scans as the zero AppointmentRecurrencePattern
+func (p *AppointmentRecurrencePattern) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*p = AppointmentRecurrencePattern{}
+		return nil
+	case []byte:
+		return p.UnmarshalBinary(value)
+	case string:
+		return p.UnmarshalText([]byte(value))
+	}
+	return fmt.Errorf("cannot scan %T into AppointmentRecurrencePattern", src)
+}
+
+// Value implements driver.Valuer, storing the pattern as its PidLidAppointmentRecur value
+func (p AppointmentRecurrencePattern) Value() (driver.Value, error) {
+	return p.MarshalBinary()
+}
+
+func newRecurrenceJSON(r *Recurrence) *recurrenceJSON {
+	j := &recurrenceJSON{
+		StartDate:             r.StartDate,
+		RecurrencePatternCode: r.RecurrencePatternCode,
+		RecurEvery:            r.RecurEvery,
+		YearlyMonth:           r.YearlyMonth,
+		MonthlyWeekOfMonth:    r.MonthlyWeekOfMonth,
+		MonthlyDayOfWeek:      r.MonthlyDayOfWeek,
+		MonthlyDay:            r.MonthlyDay,
+		MonthlyNearestWeekday: r.MonthlyNearestWeekday,
+		DailyIsOnlyWeekday:    r.DailyIsOnlyWeekday,
+		EndByDate:             r.EndByDate,
+		NumberOfOccurrences:   r.NumberOfOccurrences,
+	}
+	if loc := r.StartDate.Location(); loc != time.UTC && loc.String() != "" {
+		if _, err := time.LoadLocation(loc.String()); err == nil {
+			j.TimeZone = loc.String()
+		}
+	}
+	if r.WeeklyDaysIncluded != nil {
+		weekdays := r.Weekdays()
+		j.WeeklyDaysIncluded = &weekdays
+	}
+	return j
+}
+
+func (j *recurrenceJSON) recurrence() (*Recurrence, error) {
+	if j.StartDate.IsZero() {
+		return nil, fmt.Errorf("recurrence has no startDate")
+	}
+	if !j.RecurrencePatternCode.Valid() {
+		return nil, fmt.Errorf("unknown recurrence pattern code %q", j.RecurrencePatternCode)
+	}
+	if j.RecurEvery < 1 {
+		return nil, fmt.Errorf("interval must be at least 1, got %d", j.RecurEvery)
+	}
+	r := &Recurrence{
+		StartDate:             j.StartDate,
+		RecurrencePatternCode: j.RecurrencePatternCode,
+		RecurEvery:            j.RecurEvery,
+		YearlyMonth:           j.YearlyMonth,
+		MonthlyWeekOfMonth:    j.MonthlyWeekOfMonth,
+		MonthlyDayOfWeek:      j.MonthlyDayOfWeek,
+		MonthlyDay:            j.MonthlyDay,
+		MonthlyNearestWeekday: j.MonthlyNearestWeekday,
+		DailyIsOnlyWeekday:    j.DailyIsOnlyWeekday,
+		EndByDate:             j.EndByDate,
+		NumberOfOccurrences:   j.NumberOfOccurrences,
+	}
+	if j.TimeZone != "" {
+		loc, err := time.LoadLocation(j.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeZone: %w", err)
+		}
+		r.StartDate = r.StartDate.In(loc)
+		if r.EndByDate != nil {
+			endByDate := r.EndByDate.In(loc)
+			r.EndByDate = &endByDate
+		}
+	}
+	if j.WeeklyDaysIncluded != nil {
+		r.SetWeekdays(*j.WeeklyDaysIncluded)
+	}
+	return r, nil
+}
+
+// joinContentLines formats properties as unfolded content lines separated by newlines
+func joinContentLines(properties []icalProperty) string {
+	lines := make([]string, len(properties))
+	for i := range properties {
+		lines[i] = properties[i].contentLine()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseContentLines parses unfolded content lines separated by newlines or CRLF, skipping blank lines
+func parseContentLines(text string) ([]icalProperty, error) {
+	var properties []icalProperty
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		property, err := parseICSProperty(line)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, property)
+	}
+	return properties, nil
+}
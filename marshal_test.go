@@ -0,0 +1,329 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func marshalRecurrences(t *testing.T) []Recurrence {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	return []Recurrence{
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 3, DailyIsOnlyWeekday: boolPtr(true), NumberOfOccurrences: int16Ptr(10)},
+		{StartDate: time.Date(2026, 1, 5, 9, 0, 0, 0, newYork), RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(32 + 8 + 2),
+			EndByDate: timePtr(time.Date(2026, 6, 30, 0, 0, 0, 0, newYork))},
+		{StartDate: time.Date(2026, 1, 5, 9, 0, 0, 0, time.FixedZone("", 5*3600+1800)), RecurrencePatternCode: "W", RecurEvery: 1},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-1), MonthlyNearestWeekday: boolPtr(true)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 3, MonthlyDayOfWeek: int16Ptr(5), MonthlyWeekOfMonth: int16Ptr(54),
+			NumberOfOccurrences: int16Ptr(4), EndByDate: timePtr(time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC))},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 4, YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(29)},
+	}
+}
+
+func TestRecurrenceJSON(t *testing.T) {
+	for i, r := range marshalRecurrences(t) {
+		data, err := json.Marshal(r)
+		if err != nil {
+			t.Error(i, err)
+			continue
+		}
+		var actual Recurrence
+		if err := json.Unmarshal(data, &actual); err != nil {
+			t.Error(i, err)
+			continue
+		}
+		compareRecurrences(t, &r, &actual, string(data))
+		if actual.StartDate.Location().String() != r.StartDate.Location().String() {
+			t.Errorf("%s: expected location %s vs actual %s", data, r.StartDate.Location(), actual.StartDate.Location())
+		}
+	}
+
+	r := marshalRecurrences(t)[2]
+	data, err := json.Marshal(&r)
+	expected := `{"startDate":"2026-01-05T09:00:00-05:00","timeZone":"America/New_York","recurrencePatternCode":"W","recurEvery":2,` +
+		`"weeklyDaysIncluded":"MO,WE,FR","endByDate":"2026-06-30T00:00:00-04:00"}`
+	if err != nil || string(data) != expected {
+		t.Errorf("expected %s vs actual %s: %v", expected, data, err)
+	}
+
+	invalid := []string{
+		`{"recurrencePatternCode":"D","recurEvery":1}`,
+		`{"startDate":"2026-01-05T09:00:00Z","recurrencePatternCode":"X","recurEvery":1}`,
+		`{"startDate":"2026-01-05T09:00:00Z","recurrencePatternCode":"D","recurEvery":0}`,
+		`{"startDate":"2026-01-05T09:00:00Z","timeZone":"Nowhere/Special","recurrencePatternCode":"D","recurEvery":1}`,
+		`{"startDate":"2026-01-05T09:00:00Z","recurrencePatternCode":"W","recurEvery":1,"weeklyDaysIncluded":"XX"}`,
+		`{"startDate":"2026-01-05","recurrencePatternCode":"D","recurEvery":1}`,
+		`[]`,
+	}
+	for _, value := range invalid {
+		var r Recurrence
+		if err := json.Unmarshal([]byte(value), &r); err == nil {
+			t.Errorf("expected %s to be invalid", value)
+		}
+	}
+}
+
+func TestRecurrenceText(t *testing.T) {
+	for i, r := range marshalRecurrences(t) {
+		if i == 1 || i == 4 { // every 3 weekdays and the nearest weekday have no RRULE
+			if _, err := r.MarshalText(); !errors.Is(err, ErrNotRepresentable) {
+				t.Errorf("expected recurrence %d to be unrepresentable: %v", i, err)
+			}
+			continue
+		}
+		text, err := r.MarshalText()
+		if err != nil {
+			t.Error(i, err)
+			continue
+		}
+		var actual Recurrence
+		if err := actual.UnmarshalText(text); err != nil {
+			t.Error(string(text), err)
+			continue
+		}
+		switch i {
+		case 3: // iCalendar has no fixed offsets, so the start is written in UTC with every day
+			r.StartDate, r.WeeklyDaysIncluded = r.StartDate.UTC(), int16Ptr(127)
+		case 5: // COUNT wins over UNTIL, and the EndByDate is the last of the occurrences
+			r.EndByDate = timePtr(time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC))
+		}
+		compareRecurrences(t, &r, &actual, string(text))
+	}
+
+	r := marshalRecurrences(t)[2]
+	text, err := r.MarshalText()
+	expected := "DTSTART;TZID=America/New_York:20260105T090000\nRRULE:FREQ=WEEKLY;INTERVAL=2;UNTIL=20260701T035959Z;BYDAY=MO,WE,FR;WKST=SU"
+	if err != nil || string(text) != expected {
+		t.Errorf("expected %q vs actual %q: %v", expected, text, err)
+	}
+
+	var date Recurrence
+	if err := date.UnmarshalText([]byte("DTSTART;VALUE=DATE:20260105\r\nRRULE:FREQ=DAILY;COUNT=3\r\n")); err != nil {
+		t.Error(err)
+	} else {
+		compareRecurrences(t, &Recurrence{StartDate: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "D", RecurEvery: 1,
+			NumberOfOccurrences: int16Ptr(3), EndByDate: timePtr(time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC))}, &date, "DATE")
+	}
+
+	invalid := []string{"", "RRULE:FREQ=DAILY", "DTSTART:20260105T090000Z", "DTSTART:20260105T090000Z\nRRULE:FREQ=DAILY\nRRULE:FREQ=WEEKLY",
+		"DTSTART:20260105T090000Z\nSUMMARY:Hi\nRRULE:FREQ=DAILY", "DTSTART:2026\nRRULE:FREQ=DAILY", "DTSTART:20260105T090000Z\nRRULE:FREQ=HOURLY", "nonsense"}
+	for _, value := range invalid {
+		var r Recurrence
+		if err := r.UnmarshalText([]byte(value)); err == nil {
+			t.Errorf("expected %q to be invalid", value)
+		}
+	}
+}
+
+func TestRecurrenceSQL(t *testing.T) {
+	for i, r := range marshalRecurrences(t) {
+		value, err := r.Value()
+		if err != nil {
+			t.Error(i, err)
+			continue
+		}
+		var actual Recurrence
+		if err := actual.Scan([]byte(value.(string))); err != nil {
+			t.Error(value, err)
+			continue
+		}
+		compareRecurrences(t, &r, &actual, value.(string))
+	}
+
+	var text Recurrence
+	if err := text.Scan("DTSTART:20260105T090000Z\nRRULE:FREQ=MONTHLY;BYDAY=2TU"); err != nil {
+		t.Error(err)
+	} else {
+		compareRecurrences(t, &Recurrence{StartDate: time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 1,
+			MonthlyDayOfWeek: int16Ptr(2), MonthlyWeekOfMonth: int16Ptr(2)}, &text, "text")
+	}
+
+	null := Recurrence{RecurrencePatternCode: "D"}
+	if err := null.Scan(nil); err != nil || null.RecurrencePatternCode != "" {
+		t.Error("expected NULL to scan as the zero Recurrence", err)
+	}
+	var r Recurrence
+	if err := r.Scan(42); err == nil {
+		t.Error("expected an int to be rejected")
+	}
+}
+
+func TestSeriesMarshalling(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	series := []Series{
+		{Recurrence: Recurrence{StartDate: time.Date(2026, 1, 5, 9, 0, 0, 0, newYork), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(32)},
+			Duration: 90 * time.Minute, ExceptionDates: []time.Time{time.Date(2026, 1, 19, 9, 0, 0, 0, newYork)}},
+		{Recurrence: Recurrence{StartDate: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(1),
+			MonthlyDay: int16Ptr(5), NumberOfOccurrences: int16Ptr(3), EndByDate: timePtr(time.Date(2028, 1, 5, 0, 0, 0, 0, time.UTC))}, AllDay: true, Duration: 24 * time.Hour},
+	}
+	for i, s := range series {
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.Error(i, err)
+			continue
+		}
+		var actual Series
+		if err := json.Unmarshal(data, &actual); err != nil {
+			t.Error(string(data), err)
+		} else {
+			compareSeries(t, &s, &actual, string(data))
+		}
+
+		text, err := s.MarshalText()
+		if err != nil {
+			t.Error(i, err)
+			continue
+		}
+		actual = Series{}
+		if err := actual.UnmarshalText(text); err != nil {
+			t.Error(string(text), err)
+		} else {
+			compareSeries(t, &s, &actual, string(text))
+		}
+
+		value, err := s.Value()
+		if err != nil {
+			t.Error(i, err)
+			continue
+		}
+		actual = Series{}
+		if err := actual.Scan(value); err != nil {
+			t.Error(value, err)
+		} else {
+			compareSeries(t, &s, &actual, value.(string))
+		}
+	}
+
+	data, err := json.Marshal(series[0])
+	expected := `{"startDate":"2026-01-05T09:00:00-05:00","timeZone":"America/New_York","recurrencePatternCode":"W","recurEvery":1,` +
+		`"weeklyDaysIncluded":"MO","duration":"PT1H30M","exceptionDates":["2026-01-19T09:00:00-05:00"]}`
+	if err != nil || string(data) != expected {
+		t.Errorf("expected %s vs actual %s: %v", expected, data, err)
+	}
+	if _, err := json.Marshal(Series{Recurrence: series[0].Recurrence, Duration: 1500 * time.Millisecond}); err == nil {
+		t.Error("expected a fractional second duration to be rejected")
+	}
+	var s Series
+	if err := json.Unmarshal([]byte(`{"startDate":"2026-01-05T09:00:00Z","recurrencePatternCode":"D","recurEvery":1,"duration":"P1D"}`), &s); err != nil || s.Duration != 24*time.Hour {
+		t.Error("expected a day to be 24 hours", s.Duration, err)
+	}
+	if err := json.Unmarshal([]byte(`{"startDate":"2026-01-05T09:00:00Z","recurrencePatternCode":"D","recurEvery":1,"duration":"P1M"}`), &s); err == nil {
+		t.Error("expected a duration in months to be rejected")
+	}
+}
+
+// TestAppointmentRecurrencePatternMarshalling checks that the fields an AppointmentRecurrencePattern adds to its
+// embedded Recurrence are not lost to the methods of the Recurrence
+func TestAppointmentRecurrencePatternMarshalling(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "oxocal", "exceptions.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p AppointmentRecurrencePattern
+	if err := p.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if p.Duration != time.Hour || len(p.Exceptions) != 1 || len(p.DeletedInstanceDates) != 2 {
+		t.Fatalf("unexpected pattern %+v", p)
+	}
+	compare := func(actual *AppointmentRecurrencePattern, label string) {
+		t.Helper()
+		if actualData, err := actual.MarshalBinary(); err != nil || !bytes.Equal(data, actualData) {
+			t.Errorf("%s: expected the pattern to survive the round trip: %v", label, err)
+		}
+	}
+
+	value, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON AppointmentRecurrencePattern
+	if err := json.Unmarshal(value, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	compare(&fromJSON, "JSON")
+
+	text, err := p.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromText AppointmentRecurrencePattern
+	if err := fromText.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	compare(&fromText, "text")
+
+	column, err := p.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromColumn, fromTextColumn AppointmentRecurrencePattern
+	if err := fromColumn.Scan(column); err != nil {
+		t.Fatal(err)
+	}
+	compare(&fromColumn, "SQL")
+	if err := fromTextColumn.Scan(string(text)); err != nil {
+		t.Fatal(err)
+	}
+	compare(&fromTextColumn, "SQL text")
+	if err := fromColumn.Scan(nil); err != nil || fromColumn.Duration != 0 {
+		t.Error("expected NULL to scan as the zero AppointmentRecurrencePattern", err)
+	}
+	if err := fromColumn.Scan(42); err == nil {
+		t.Error("expected an int to be rejected")
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	var recurrenceSchema, seriesSchema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+	}
+	if err := json.Unmarshal(RecurrenceJSONSchema, &recurrenceSchema); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(SeriesJSONSchema, &seriesSchema); err != nil {
+		t.Fatal(err)
+	}
+
+	// every field the encoders can write is described by the schema
+	r := Recurrence{StartDate: time.Date(2026, 1, 5, 9, 0, 0, 0, time.Local), RecurrencePatternCode: "M", RecurEvery: 2, YearlyMonth: int16Ptr(1),
+		MonthlyWeekOfMonth: int16Ptr(1), MonthlyDayOfWeek: int16Ptr(1), MonthlyDay: int16Ptr(1), MonthlyNearestWeekday: boolPtr(true),
+		WeeklyDaysIncluded: int16Ptr(1), DailyIsOnlyWeekday: boolPtr(true), EndByDate: timePtr(time.Now()), NumberOfOccurrences: int16Ptr(1)}
+	s := Series{Recurrence: r, AllDay: true, Duration: time.Hour, ExceptionDates: []time.Time{time.Now()}}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 16 {
+		t.Error("expected every field to be written", string(data))
+	}
+	for name := range fields {
+		_, inRecurrence := recurrenceSchema.Properties[name]
+		_, inSeries := seriesSchema.Properties[name]
+		if !inRecurrence && !inSeries {
+			t.Errorf("%s is not in the schema", name)
+		}
+	}
+	for _, name := range recurrenceSchema.Required {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("required %s is not written", name)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package calendar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var workWeek = NewWeekdays(time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+
+// Normalize returns the canonical form of the recurrence, which has the same occurrences at the same time of day.
+// Recurrences with the same occurrences have the same canonical form, as far as the field combinations allow:
+//   - the StartDate is the first occurrence and the EndByDate the last one, and NumberOfOccurrences is not set
+//   - every day or every weekday weekly is daily, and every 7 days or every 5 weekdays is weekly
+//   - every 12 months is yearly, and a day of a month other than February counted from its end is counted from its start
+//   - RecurEvery is 1 when every occurrence is in the first week, month or year, and a single occurrence is daily
+//   - false DailyIsOnlyWeekday and MonthlyNearestWeekday are not set, and a weekly WeeklyDaysIncluded always is
+//
+// It is an error when the recurrence is invalid or has no occurrences
+func (r *Recurrence) Normalize() (*Recurrence, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	first, ok := r.OccurrenceAt(0)
+	if !ok {
+		return nil, fmt.Errorf("recurrence has no occurrences")
+	}
+	n := *r
+	n.StartDate = time.Date(first.Year(), first.Month(), first.Day(), r.StartDate.Hour(), r.StartDate.Minute(), r.StartDate.Second(), r.StartDate.Nanosecond(), r.StartDate.Location())
+	n.NumberOfOccurrences = nil
+	last := time.Time{}
+	if endDate := r.endDate(); endDate != nil {
+		last, _ = r.OccurrenceAt(r.Count(first, *endDate) - 1)
+		endByDate := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, r.StartDate.Location())
+		n.EndByDate = &endByDate
+	}
+	if n.DailyIsOnlyWeekday != nil && !*n.DailyIsOnlyWeekday {
+		n.DailyIsOnlyWeekday = nil
+	}
+	if n.MonthlyNearestWeekday != nil && !*n.MonthlyNearestWeekday {
+		n.MonthlyNearestWeekday = nil
+	}
+	if !last.IsZero() && n.inFirstPeriod(first, last) {
+		n.RecurEvery = 1
+	}
+
+	switch n.RecurrencePatternCode {
+	case FrequencyDaily:
+		switch {
+		case n.DailyIsOnlyWeekday != nil && isWeekday(first) && n.RecurEvery%5 == 0:
+			n.RecurrencePatternCode, n.RecurEvery, n.DailyIsOnlyWeekday = FrequencyWeekly, n.RecurEvery/5, nil
+			n.SetWeekdays(NewWeekdays(first.Weekday()))
+		case n.DailyIsOnlyWeekday == nil && n.RecurEvery%7 == 0:
+			n.RecurrencePatternCode, n.RecurEvery = FrequencyWeekly, n.RecurEvery/7
+			n.SetWeekdays(NewWeekdays(first.Weekday()))
+		}
+	case FrequencyWeekly:
+		switch weekdays := n.Weekdays(); {
+		case n.RecurEvery == 1 && weekdays == AllWeekdays:
+			n.RecurrencePatternCode, n.WeeklyDaysIncluded = FrequencyDaily, nil
+		case n.RecurEvery == 1 && weekdays == workWeek:
+			n.RecurrencePatternCode, n.WeeklyDaysIncluded, n.DailyIsOnlyWeekday = FrequencyDaily, nil, boolPointer(true)
+		default:
+			n.SetWeekdays(weekdays)
+		}
+	case FrequencyMonthly:
+		if n.RecurEvery%12 == 0 {
+			n.RecurrencePatternCode, n.RecurEvery, n.YearlyMonth = FrequencyYearly, n.RecurEvery/12, int16Pointer(int(first.Month()))
+		}
+	}
+	if n.RecurrencePatternCode == FrequencyYearly && n.MonthlyDay != nil && *n.MonthlyDay < 0 && *n.YearlyMonth != int16(time.February) {
+		n.MonthlyDay = int16Pointer(daysIn(time.Month(*n.YearlyMonth), first.Year()) + 1 + int(*n.MonthlyDay))
+	}
+	if !last.IsZero() && last.Equal(first) {
+		n = Recurrence{StartDate: n.StartDate, RecurrencePatternCode: FrequencyDaily, RecurEvery: 1, EndByDate: n.EndByDate}
+	}
+	return &n, nil
+}
+
+// inFirstPeriod returns whether the last occurrence is in the same week, month or year as the first
+func (r *Recurrence) inFirstPeriod(first, last time.Time) bool {
+	switch r.RecurrencePatternCode {
+	case FrequencyWeekly:
+		return getWeeks(first.AddDate(0, 0, -1*int(first.Weekday())), last.AddDate(0, 0, -1*int(last.Weekday()))) == 0
+	case FrequencyMonthly:
+		return first.Year() == last.Year() && first.Month() == last.Month()
+	case FrequencyYearly:
+		return first.Year() == last.Year()
+	}
+	return false
+}
+
+// Equivalent returns whether two recurrences have the same occurrences at the same time of day in the same time
+// zone, whatever fields they use. Recurrences that are invalid or have no occurrences are not equivalent to any
+func Equivalent(a, b *Recurrence) bool {
+	aKey, err := a.canonicalKey()
+	if err != nil {
+		return false
+	}
+	bKey, err := b.canonicalKey()
+	return err == nil && aKey == bKey
+}
+
+// Fingerprint returns a hash of the canonical form of the recurrence that is the same for equivalent recurrences,
+// e.g. to find duplicates. It is an error when the recurrence is invalid or has no occurrences
+func (r *Recurrence) Fingerprint() (string, error) {
+	key, err := r.canonicalKey()
+	if err != nil {
+		return "", err
+	}
+	return fingerprint(key), nil
+}
+
+// Fingerprint is Recurrence.Fingerprint including AllDay, the Duration and the ExceptionDates that remove
+// occurrences. The time of day of an all day series is ignored
+func (s *Series) Fingerprint() (string, error) {
+	r := s.Recurrence
+	if s.AllDay {
+		r.StartDate = time.Date(r.StartDate.Year(), r.StartDate.Month(), r.StartDate.Day(), 0, 0, 0, 0, r.StartDate.Location())
+	}
+	key, err := r.canonicalKey()
+	if err != nil {
+		return "", err
+	}
+	var exceptionDates []string
+	for _, exceptionDate := range s.ExceptionDates {
+		date := time.Date(exceptionDate.Year(), exceptionDate.Month(), exceptionDate.Day(), 0, 0, 0, 0, time.UTC)
+		if r.IsValidOccurrenceDate(date) {
+			exceptionDates = append(exceptionDates, date.Format("20060102"))
+		}
+	}
+	slices.Sort(exceptionDates)
+	exceptionDates = slices.Compact(exceptionDates)
+	return fingerprint(fmt.Sprintf("%s allday=%t duration=%s exdate=%s", key, s.AllDay, s.Duration, strings.Join(exceptionDates, ","))), nil
+}
+
+// canonicalKey writes every field of the canonical form of the recurrence
+func (r *Recurrence) canonicalKey() (string, error) {
+	n, err := r.Normalize()
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("%s every=%d start=%s tz=%s", n.RecurrencePatternCode, n.RecurEvery, n.StartDate.Format("20060102T150405.999999999"), n.StartDate.Location())
+	if n.EndByDate != nil {
+		key += " until=" + n.EndByDate.Format("20060102")
+	}
+	fields := []struct {
+		name  string
+		value *int16
+	}{
+		{"weekly", n.WeeklyDaysIncluded}, {"month", n.YearlyMonth}, {"day", n.MonthlyDay},
+		{"weekday", n.MonthlyDayOfWeek}, {"week", n.MonthlyWeekOfMonth},
+	}
+	for _, field := range fields {
+		if field.value != nil {
+			key += fmt.Sprintf(" %s=%d", field.name, *field.value)
+		}
+	}
+	if n.DailyIsOnlyWeekday != nil {
+		key += " onlyweekday"
+	}
+	if n.MonthlyNearestWeekday != nil {
+		key += " nearestweekday"
+	}
+	return key, nil
+}
+
+func fingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
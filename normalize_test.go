@@ -0,0 +1,90 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEquivalent(t *testing.T) {
+	monday := time.Date(2016, 1, 4, 9, 30, 0, 0, time.UTC)
+	end := timePtr(time.Date(2016, 12, 31, 0, 0, 0, 0, time.UTC))
+	equivalent := [][2]Recurrence{
+		{{StartDate: monday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(127)}, {StartDate: monday, RecurrencePatternCode: "D", RecurEvery: 1}},
+		{{StartDate: monday, RecurrencePatternCode: "W", RecurEvery: 1}, {StartDate: monday, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(false)}},
+		{{StartDate: monday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(62)}, {StartDate: monday, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)}},
+		{{StartDate: monday, RecurrencePatternCode: "D", RecurEvery: 14}, {StartDate: monday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(32)}},
+		{{StartDate: monday, RecurrencePatternCode: "D", RecurEvery: 10, DailyIsOnlyWeekday: boolPtr(true)}, {StartDate: monday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(32)}},
+		{{StartDate: monday, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(1), MonthlyDay: int16Ptr(15)}, {StartDate: monday, RecurrencePatternCode: "M", RecurEvery: 12, MonthlyDay: int16Ptr(15)}},
+		{{StartDate: monday, RecurrencePatternCode: "Y", RecurEvery: 2, YearlyMonth: int16Ptr(4), MonthlyDay: int16Ptr(30)}, {StartDate: monday.AddDate(0, 3, 0), RecurrencePatternCode: "M", RecurEvery: 24, MonthlyDay: int16Ptr(-1), MonthlyNearestWeekday: boolPtr(false)}},
+		{{StartDate: monday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(42)}, {StartDate: monday.AddDate(0, 0, -1), RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(42)}},
+		{{StartDate: monday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(42), EndByDate: end}, {StartDate: monday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(42), NumberOfOccurrences: int16Ptr(157), EndByDate: timePtr(time.Date(2016, 12, 30, 0, 0, 0, 0, time.UTC))}},
+		{{StartDate: monday, RecurrencePatternCode: "W", RecurEvery: 3, WeeklyDaysIncluded: int16Ptr(42), EndByDate: timePtr(monday.AddDate(0, 0, 6))}, {StartDate: monday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(42), EndByDate: timePtr(monday.AddDate(0, 0, 4))}},
+		{{StartDate: monday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(4), EndByDate: timePtr(monday)}, {StartDate: monday, RecurrencePatternCode: "D", RecurEvery: 3, EndByDate: timePtr(monday.AddDate(0, 0, 2))}},
+	}
+	for i, pair := range equivalent {
+		if !Equivalent(&pair[0], &pair[1]) {
+			t.Errorf("expected %d to be equivalent", i)
+		}
+		compareTimes(t, pair[0].GetOccurrences(monday.AddDate(0, 0, -7), monday.AddDate(5, 0, 0)), pair[1].GetOccurrences(monday.AddDate(0, 0, -7), monday.AddDate(5, 0, 0)), "TestEquivalent")
+		a, errA := pair[0].Fingerprint()
+		b, errB := pair[1].Fingerprint()
+		if errA != nil || errB != nil || a != b || len(a) != 64 {
+			t.Errorf("expected %d to have the same fingerprint: %s %s %v %v", i, a, b, errA, errB)
+		}
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	daily := Recurrence{StartDate: monday, RecurrencePatternCode: "D", RecurEvery: 1}
+	for i, r := range []Recurrence{
+		{StartDate: monday.Add(time.Hour), RecurrencePatternCode: "D", RecurEvery: 1},
+		{StartDate: monday.In(newYork), RecurrencePatternCode: "D", RecurEvery: 1},
+		{StartDate: monday, RecurrencePatternCode: "D", RecurEvery: 2},
+		{StartDate: monday, RecurrencePatternCode: "D", RecurEvery: 1, EndByDate: end},
+		{StartDate: monday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(127)},
+		{StartDate: monday, RecurrencePatternCode: "D"},
+	} {
+		if Equivalent(&daily, &r) {
+			t.Errorf("expected %d not to be equivalent", i)
+		}
+	}
+
+	s := Series{Recurrence: daily, Duration: time.Hour, ExceptionDates: []time.Time{time.Date(2016, 1, 5, 0, 0, 0, 0, time.UTC), time.Date(2015, 1, 5, 0, 0, 0, 0, time.UTC)}}
+	other := Series{Recurrence: equivalent[0][0], Duration: time.Hour, ExceptionDates: []time.Time{time.Date(2016, 1, 5, 14, 0, 0, 0, time.UTC), time.Date(2016, 1, 5, 0, 0, 0, 0, time.UTC)}}
+	a, _ := s.Fingerprint()
+	b, _ := other.Fingerprint()
+	if a != b {
+		t.Error("expected series with the same occurrences and exceptions to have the same fingerprint")
+	}
+	other.Duration = 2 * time.Hour
+	if b, _ = other.Fingerprint(); a == b {
+		t.Error("expected the duration to change the fingerprint")
+	}
+}
+
+func TestNormalizeVariedRecurrences(t *testing.T) {
+	for i, r := range variedRecurrences() {
+		n, err := r.Normalize()
+		if err != nil {
+			if _, ok := r.OccurrenceAt(0); ok {
+				t.Fatalf("recurrence %d %+v: %s", i, r, err)
+			}
+			continue
+		}
+		if err := n.Validate(); err != nil {
+			t.Fatalf("recurrence %d %+v: invalid canonical form %+v: %s", i, r, n, err)
+		}
+		end := r.startDate().AddDate(30, 0, 0)
+		compareTimes(t, r.GetOccurrences(r.startDate(), end), n.GetOccurrences(r.startDate(), end), "TestNormalizeVariedRecurrences")
+		again, err := n.Normalize()
+		if err != nil || !Equivalent(&r, n) {
+			t.Fatalf("recurrence %d %+v: expected the canonical form %+v to be equivalent", i, r, n)
+		}
+		key, _ := n.canonicalKey()
+		if againKey, _ := again.canonicalKey(); key != againKey {
+			t.Fatalf("recurrence %d: expected normalizing again to keep %s, got %s", i, key, againKey)
+		}
+	}
+}
@@ -0,0 +1,655 @@
+package calendar
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf16"
+)
+
+// MS-OXOCAL RecurFrequency values
+const (
+	oxocalFrequencyDaily   = 0x200A
+	oxocalFrequencyWeekly  = 0x200B
+	oxocalFrequencyMonthly = 0x200C
+	oxocalFrequencyYearly  = 0x200D
+)
+
+// MS-OXOCAL PatternType values. Hijri calendar pattern types are not supported
+const (
+	oxocalPatternDay      = 0x0000
+	oxocalPatternWeek     = 0x0001
+	oxocalPatternMonth    = 0x0002
+	oxocalPatternMonthNth = 0x0003
+	oxocalPatternMonthEnd = 0x0004
+)
+
+// MS-OXOCAL EndType values
+const (
+	oxocalEndAfterDate        = 0x2021
+	oxocalEndAfterOccurrences = 0x2022
+	oxocalNeverEnd            = 0x2023
+	oxocalNeverEndLegacy      = 0xFFFFFFFF
+)
+
+// MS-OXOCAL OverrideFlags values identifying which fields an exception overrides
+const (
+	AROSubject          = 0x0001
+	AROMeetingType      = 0x0002
+	AROReminderDelta    = 0x0004
+	AROReminder         = 0x0008
+	AROLocation         = 0x0010
+	AROBusyStatus       = 0x0020
+	AROAttachment       = 0x0040
+	AROSubType          = 0x0080
+	AROAppointmentColor = 0x0100
+	AROExceptionalBody  = 0x0200
+)
+
+const (
+	oxocalVersion         = 0x3004
+	oxocalReaderVersion2  = 0x3006
+	oxocalWriterVersion2  = 0x3009
+	oxocalNoEndDate       = 0x5AE980DF // 4500-08-31 23:59, written as the EndDate of recurrences that never end
+	oxocalDefaultCount    = 10         // OccurrenceCount written when EndType is not END_AFTER_N_OCCURRENCES
+	oxocalMinutesPerDay   = 24 * 60
+	oxocalMinutesPerWeek  = 7 * oxocalMinutesPerDay
+	oxocalNthLastInstance = 5
+)
+
+var oxocalEpoch = time.Date(1601, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// errShortPattern is returned when the binary structure ends before all of its fields have been read
+var errShortPattern = errors.New("appointment recurrence pattern is truncated")
+
+// AppointmentRecurrencePattern is the MS-OXOCAL PidLidAppointmentRecur binary structure stored by Outlook. The
+// embedded Recurrence holds the pattern and range, with the appointment start time of day kept in StartDate
+type AppointmentRecurrencePattern struct {
+	Recurrence
+	CalendarType          uint16        // CAL_* calendar type. Only Gregorian calendars are supported
+	FirstDayOfWeek        time.Weekday  // first day of the week for the user who created the pattern
+	Duration              time.Duration // length of each occurrence (EndTimeOffset - StartTimeOffset)
+	DeletedInstanceDates  []time.Time   // original dates of deleted and modified occurrences, at midnight
+	ModifiedInstanceDates []time.Time   // dates of modified occurrences, at midnight
+	Exceptions            []AppointmentException
+	WriterVersion2        uint32 // 0x3008 or 0x3009. Zero writes 0x3009
+	ReservedBlock1        []byte
+	ReservedBlock2        []byte
+}
+
+// AppointmentException is a modified occurrence from the ExceptionInfo and ExtendedException structures. Only the
+// fields flagged in OverrideFlags are stored
+type AppointmentException struct {
+	StartDateTime           time.Time
+	EndDateTime             time.Time
+	OriginalStartDate       time.Time
+	OverrideFlags           uint16
+	Subject                 string
+	MeetingType             uint32
+	ReminderDelta           uint32
+	ReminderSet             bool
+	Location                string
+	BusyStatus              uint32
+	Attachment              bool
+	SubType                 bool
+	AppointmentColor        uint32
+	ChangeHighlight         uint32 // only stored when WriterVersion2 is 0x3009 or later
+	ChangeHighlightReserved []byte
+	ReservedBlockEE1        []byte
+	ReservedBlockEE2        []byte
+}
+
+// UnmarshalBinary decodes a PidLidAppointmentRecur value. Patterns the Recurrence struct cannot express, such as
+// Hijri calendars or monthly patterns on more than one day of the week, return ErrNotRepresentable
+func (p *AppointmentRecurrencePattern) UnmarshalBinary(data []byte) error {
+	d := &oxocalDecoder{data: data}
+	if readerVersion, writerVersion := d.uint16(), d.uint16(); d.err == nil && (readerVersion != oxocalVersion || writerVersion != oxocalVersion) {
+		return fmt.Errorf("unsupported recurrence pattern version %#x/%#x", readerVersion, writerVersion)
+	}
+	frequency, patternType := d.uint16(), d.uint16()
+	*p = AppointmentRecurrencePattern{CalendarType: d.uint16()}
+	firstDateTime, period := d.uint32(), d.uint32()
+	d.uint32() // SlidingFlag is only used by tasks
+	var dayMask, dayOfMonth, nth uint32
+	switch patternType {
+	case oxocalPatternWeek:
+		dayMask = d.uint32()
+	case oxocalPatternMonth, oxocalPatternMonthEnd:
+		dayOfMonth = d.uint32()
+	case oxocalPatternMonthNth:
+		dayMask, nth = d.uint32(), d.uint32()
+	case oxocalPatternDay:
+	default:
+		return fmt.Errorf("%w: unsupported recurrence pattern type %#x", ErrNotRepresentable, patternType)
+	}
+	endType, occurrenceCount := d.uint32(), d.uint32()
+	p.FirstDayOfWeek = time.Weekday(d.uint32() % 7)
+	p.DeletedInstanceDates = d.dates(d.uint32())
+	p.ModifiedInstanceDates = d.dates(d.uint32())
+	startDate, endDate := d.uint32(), d.uint32()
+
+	if readerVersion2 := d.uint32(); d.err == nil && readerVersion2 != oxocalReaderVersion2 {
+		return fmt.Errorf("unsupported appointment recurrence pattern version %#x", readerVersion2)
+	}
+	p.WriterVersion2 = d.uint32()
+	startTimeOffset, endTimeOffset := d.uint32(), d.uint32()
+	p.Duration = time.Duration(int64(endTimeOffset)-int64(startTimeOffset)) * time.Minute
+	if count := int(d.uint16()); d.err == nil && count > 0 {
+		p.Exceptions = make([]AppointmentException, count)
+	}
+	for i := range p.Exceptions {
+		d.exceptionInfo(&p.Exceptions[i])
+	}
+	p.ReservedBlock1 = d.bytes(d.uint32())
+	for i := range p.Exceptions {
+		d.extendedException(&p.Exceptions[i], p.WriterVersion2)
+	}
+	p.ReservedBlock2 = d.bytes(d.uint32())
+	if d.err != nil {
+		return d.err
+	}
+
+	if !oxocalGregorian(p.CalendarType) {
+		return fmt.Errorf("%w: unsupported calendar type %#x", ErrNotRepresentable, p.CalendarType)
+	}
+	r := &p.Recurrence
+	r.StartDate = oxocalTime(startDate).Add(time.Duration(startTimeOffset) * time.Minute)
+	switch {
+	case frequency == oxocalFrequencyDaily && patternType == oxocalPatternDay:
+		if period == 0 || period%oxocalMinutesPerDay != 0 {
+			return fmt.Errorf("%w: daily period of %d minutes is not a whole number of days", ErrNotRepresentable, period)
+		}
+		r.RecurrencePatternCode = FrequencyDaily
+		period /= oxocalMinutesPerDay
+	case frequency == oxocalFrequencyDaily && patternType == oxocalPatternWeek:
+		if period != 1 || dayMask != 0x3E {
+			return fmt.Errorf("%w: daily recurrence must be every weekday", ErrNotRepresentable)
+		}
+		dailyIsOnlyWeekday := true
+		r.RecurrencePatternCode = FrequencyDaily
+		r.DailyIsOnlyWeekday = &dailyIsOnlyWeekday
+	case frequency == oxocalFrequencyWeekly && patternType == oxocalPatternWeek:
+		weeklyDaysIncluded := oxocalWeeklyDaysIncluded(dayMask)
+		if weeklyDaysIncluded == 0 {
+			return fmt.Errorf("%w: weekly recurrence has no days of the week", ErrNotRepresentable)
+		}
+		if period > 1 && !sundayWeeksMatch(weeklyDaysIncluded, p.FirstDayOfWeek) {
+			return fmt.Errorf("%w: weeks starting on %s group these days differently than weeks starting on Sunday", ErrNotRepresentable, p.FirstDayOfWeek)
+		}
+		r.RecurrencePatternCode = FrequencyWeekly
+		r.WeeklyDaysIncluded = &weeklyDaysIncluded
+	case frequency == oxocalFrequencyMonthly || frequency == oxocalFrequencyYearly:
+		switch patternType {
+		case oxocalPatternMonth, oxocalPatternMonthEnd:
+			monthlyDay := int16(dayOfMonth)
+			if patternType == oxocalPatternMonthEnd {
+				monthlyDay = -1
+			} else if dayOfMonth < 1 || dayOfMonth > 31 {
+				return fmt.Errorf("%w: day of month %d is out of range", ErrNotRepresentable, dayOfMonth)
+			}
+			r.MonthlyDay = &monthlyDay
+		case oxocalPatternMonthNth:
+			days := getIncludedWeeklyDays(oxocalWeeklyDaysIncluded(dayMask))
+			if len(days) != 1 {
+				return fmt.Errorf("%w: monthly recurrence must be on exactly one day of the week, got %d", ErrNotRepresentable, len(days))
+			}
+			if nth < 1 || nth > oxocalNthLastInstance {
+				return fmt.Errorf("%w: week of month %d is out of range", ErrNotRepresentable, nth)
+			}
+			monthlyDayOfWeek, monthlyWeekOfMonth := int16(days[0]), int16(nth)
+			if nth == oxocalNthLastInstance {
+				monthlyWeekOfMonth = 54
+			}
+			r.MonthlyDayOfWeek = &monthlyDayOfWeek
+			r.MonthlyWeekOfMonth = &monthlyWeekOfMonth
+		default:
+			return fmt.Errorf("%w: unsupported monthly pattern type %#x", ErrNotRepresentable, patternType)
+		}
+		r.RecurrencePatternCode = FrequencyMonthly
+		if frequency == oxocalFrequencyYearly {
+			if period%12 != 0 {
+				return fmt.Errorf("%w: yearly period of %d months is not a whole number of years", ErrNotRepresentable, period)
+			}
+			// the month of the year is the month FirstDateTime falls in
+			yearlyMonth := int16(oxocalTime(firstDateTime).Month())
+			r.RecurrencePatternCode = FrequencyYearly
+			r.YearlyMonth = &yearlyMonth
+			period /= 12
+		}
+	default:
+		return fmt.Errorf("%w: unsupported recurrence frequency %#x with pattern type %#x", ErrNotRepresentable, frequency, patternType)
+	}
+	if period < 1 || period > 32767 {
+		return fmt.Errorf("%w: period %d is out of range", ErrNotRepresentable, period)
+	}
+	r.RecurEvery = int16(period)
+
+	switch endType {
+	case oxocalEndAfterDate:
+		end := oxocalTime(endDate)
+		r.EndByDate = &end
+	case oxocalEndAfterOccurrences:
+		if occurrenceCount < 1 || occurrenceCount > 32767 {
+			return fmt.Errorf("%w: occurrence count %d is out of range", ErrNotRepresentable, occurrenceCount)
+		}
+		end, count := oxocalTime(endDate), int16(occurrenceCount)
+		r.EndByDate = &end
+		r.NumberOfOccurrences = &count
+	case oxocalNeverEnd, oxocalNeverEndLegacy:
+	default:
+		return fmt.Errorf("unknown recurrence end type %#x", endType)
+	}
+	return nil
+}
+
+// MarshalBinary encodes the pattern as a PidLidAppointmentRecur value. A recurrence that ends after a number of
+// occurrences must also have its EndByDate set, see SetNumberOfOccurrences, since Outlook stores the date of the
+// last occurrence
+func (p *AppointmentRecurrencePattern) MarshalBinary() ([]byte, error) {
+	r := &p.Recurrence
+	if r.RecurEvery < 1 {
+		return nil, fmt.Errorf("%w: period must be at least 1, got %d", ErrNotRepresentable, r.RecurEvery)
+	}
+	if !oxocalGregorian(p.CalendarType) {
+		return nil, fmt.Errorf("%w: unsupported calendar type %#x", ErrNotRepresentable, p.CalendarType)
+	}
+	startDate := time.Date(r.StartDate.Year(), r.StartDate.Month(), r.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	startMinutes := oxocalMinutes(startDate)
+	period := uint32(r.RecurEvery)
+	var frequency, patternType uint16
+	var firstDateTime uint32
+	var patternTypeSpecific []uint32
+	switch r.RecurrencePatternCode {
+	case FrequencyDaily:
+		frequency = oxocalFrequencyDaily
+		if r.DailyIsOnlyWeekday != nil && *r.DailyIsOnlyWeekday {
+			if r.RecurEvery != 1 {
+				return nil, fmt.Errorf("%w: outlook cannot recur every %d weekdays", ErrNotRepresentable, r.RecurEvery)
+			}
+			patternType = oxocalPatternWeek
+			firstDateTime = oxocalWeekStartMinutes(startDate, p.FirstDayOfWeek) % oxocalMinutesPerWeek
+			patternTypeSpecific = []uint32{0x3E}
+		} else {
+			patternType = oxocalPatternDay
+			period *= oxocalMinutesPerDay
+			firstDateTime = startMinutes % period
+		}
+	case FrequencyWeekly:
+		weeklyDaysIncluded := r.Weekdays().Bitmask()
+		frequency, patternType = oxocalFrequencyWeekly, oxocalPatternWeek
+		firstDateTime = oxocalWeekStartMinutes(startDate, p.FirstDayOfWeek) % (period * oxocalMinutesPerWeek)
+		patternTypeSpecific = []uint32{oxocalDayMask(weeklyDaysIncluded)}
+	case FrequencyMonthly, FrequencyYearly:
+		frequency = oxocalFrequencyMonthly
+		monthStart := time.Date(startDate.Year(), startDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if r.RecurrencePatternCode == FrequencyYearly {
+			if r.YearlyMonth == nil || *r.YearlyMonth < 1 || *r.YearlyMonth > 12 {
+				return nil, fmt.Errorf("%w: yearly recurrence has no valid YearlyMonth", ErrNotRepresentable)
+			}
+			frequency = oxocalFrequencyYearly
+			period *= 12
+			monthStart = time.Date(startDate.Year(), time.Month(*r.YearlyMonth), 1, 0, 0, 0, 0, time.UTC)
+		}
+		months := uint32((monthStart.Year()-oxocalEpoch.Year())*12 + int(monthStart.Month()-1))
+		firstDateTime = oxocalMinutes(oxocalEpoch.AddDate(0, int(months%period), 0))
+		switch {
+		case r.MonthlyDay != nil && *r.MonthlyDay == -1 && (r.MonthlyNearestWeekday == nil || !*r.MonthlyNearestWeekday):
+			patternType = oxocalPatternMonthEnd
+			patternTypeSpecific = []uint32{31}
+		case r.MonthlyDay != nil:
+			if err := r.plainMonthlyDay(); err != nil {
+				return nil, err
+			}
+			patternType = oxocalPatternMonth
+			patternTypeSpecific = []uint32{uint32(*r.MonthlyDay)}
+		case r.MonthlyDayOfWeek != nil && r.MonthlyWeekOfMonth != nil:
+			nth := uint32(*r.MonthlyWeekOfMonth)
+			if *r.MonthlyWeekOfMonth == 54 {
+				nth = oxocalNthLastInstance
+			} else if nth < 1 || nth > 4 {
+				return nil, fmt.Errorf("%w: outlook has no instance for week %d of the month", ErrNotRepresentable, *r.MonthlyWeekOfMonth)
+			}
+			patternType = oxocalPatternMonthNth
+			patternTypeSpecific = []uint32{oxocalDayMask(weekdayBit(time.Weekday(*r.MonthlyDayOfWeek))), nth}
+		default:
+			return nil, fmt.Errorf("%w: recurrence has neither MonthlyDay nor MonthlyDayOfWeek and MonthlyWeekOfMonth", ErrNotRepresentable)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown recurrence pattern code %q", ErrNotRepresentable, r.RecurrencePatternCode)
+	}
+
+	endType, occurrenceCount, endDate := uint32(oxocalNeverEnd), uint32(oxocalDefaultCount), uint32(oxocalNoEndDate)
+	switch {
+	case r.NumberOfOccurrences != nil:
+		if r.EndByDate == nil {
+			return nil, errors.New("EndByDate must be calculated for a recurrence that ends after a number of occurrences, e.g. by SetNumberOfOccurrences")
+		}
+		endType, occurrenceCount, endDate = oxocalEndAfterOccurrences, uint32(*r.NumberOfOccurrences), oxocalMinutes(*r.EndByDate)
+	case r.EndByDate != nil:
+		endType, endDate = oxocalEndAfterDate, oxocalMinutes(*r.EndByDate)
+	}
+	writerVersion2 := p.WriterVersion2
+	if writerVersion2 == 0 {
+		writerVersion2 = oxocalWriterVersion2
+	}
+	startTimeOffset := uint32(r.StartDate.Hour()*60 + r.StartDate.Minute())
+
+	e := &oxocalEncoder{}
+	e.uint16(oxocalVersion)
+	e.uint16(oxocalVersion)
+	e.uint16(frequency)
+	e.uint16(patternType)
+	e.uint16(p.CalendarType)
+	e.uint32(firstDateTime)
+	e.uint32(period)
+	e.uint32(0) // SlidingFlag
+	for _, value := range patternTypeSpecific {
+		e.uint32(value)
+	}
+	e.uint32(endType)
+	e.uint32(occurrenceCount)
+	e.uint32(uint32(p.FirstDayOfWeek))
+	e.dates(p.DeletedInstanceDates)
+	e.dates(p.ModifiedInstanceDates)
+	e.uint32(startMinutes)
+	e.uint32(endDate)
+	e.uint32(oxocalReaderVersion2)
+	e.uint32(writerVersion2)
+	e.uint32(startTimeOffset)
+	e.uint32(startTimeOffset + uint32(p.Duration/time.Minute))
+	e.uint16(uint16(len(p.Exceptions)))
+	for i := range p.Exceptions {
+		e.exceptionInfo(&p.Exceptions[i])
+	}
+	e.block(p.ReservedBlock1)
+	for i := range p.Exceptions {
+		e.extendedException(&p.Exceptions[i], writerVersion2)
+	}
+	e.block(p.ReservedBlock2)
+	return e.data, nil
+}
+
+func oxocalGregorian(calendarType uint16) bool {
+	switch calendarType {
+	case 0x0, 0x1, 0x2, 0x9, 0xA, 0xB, 0xC: // CAL_DEFAULT and the CAL_GREGORIAN variants
+		return true
+	}
+	return false
+}
+
+// oxocalWeeklyDaysIncluded converts an MS-OXOCAL day mask (Sunday = 0x01 through Saturday = 0x40) to WeeklyDaysIncluded
+func oxocalWeeklyDaysIncluded(dayMask uint32) int16 {
+	var weeklyDaysIncluded int16
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if dayMask&(1<<uint(day)) != 0 {
+			weeklyDaysIncluded |= weekdayBit(day)
+		}
+	}
+	return weeklyDaysIncluded
+}
+
+// oxocalDayMask converts WeeklyDaysIncluded to an MS-OXOCAL day mask (Sunday = 0x01 through Saturday = 0x40)
+func oxocalDayMask(weeklyDaysIncluded int16) uint32 {
+	var dayMask uint32
+	for _, day := range getIncludedWeeklyDays(weeklyDaysIncluded) {
+		dayMask |= 1 << uint(day)
+	}
+	return dayMask
+}
+
+// oxocalTime converts minutes since 1601-01-01. The span is longer than a time.Duration can hold, so Unix seconds are used
+func oxocalTime(minutes uint32) time.Time {
+	return time.Unix(oxocalEpoch.Unix()+int64(minutes)*60, 0).UTC()
+}
+
+func oxocalMinutes(date time.Time) uint32 {
+	date = time.Date(date.Year(), date.Month(), date.Day(), date.Hour(), date.Minute(), 0, 0, time.UTC)
+	return uint32((date.Unix() - oxocalEpoch.Unix()) / 60)
+}
+
+// oxocalWeekStartMinutes returns the minutes from the epoch to the first day of the week containing date
+func oxocalWeekStartMinutes(date time.Time, firstDayOfWeek time.Weekday) uint32 {
+	offset := (int(date.Weekday()) - int(firstDayOfWeek) + 7) % 7
+	return oxocalMinutes(date.AddDate(0, 0, -offset))
+}
+
+type oxocalDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *oxocalDecoder) bytes(n uint32) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if uint64(len(d.data)) < uint64(n) {
+		d.err = errShortPattern
+		return nil
+	}
+	value := d.data[:n:n]
+	d.data = d.data[n:]
+	if n == 0 {
+		return nil
+	}
+	return value
+}
+
+func (d *oxocalDecoder) uint16() uint16 {
+	if value := d.bytes(2); value != nil {
+		return binary.LittleEndian.Uint16(value)
+	}
+	return 0
+}
+
+func (d *oxocalDecoder) uint32() uint32 {
+	if value := d.bytes(4); value != nil {
+		return binary.LittleEndian.Uint32(value)
+	}
+	return 0
+}
+
+func (d *oxocalDecoder) dates(count uint32) []time.Time {
+	if d.err == nil && uint64(count)*4 > uint64(len(d.data)) {
+		d.err = errShortPattern
+	}
+	if d.err != nil || count == 0 {
+		return nil
+	}
+	dates := make([]time.Time, count)
+	for i := range dates {
+		dates[i] = oxocalTime(d.uint32())
+	}
+	return dates
+}
+
+// ansiString reads a length-prefixed 8-bit string, which is decoded as Latin-1
+func (d *oxocalDecoder) ansiString() string {
+	d.uint16() // the first length includes a terminator which is not present
+	value := d.bytes(uint32(d.uint16()))
+	runes := make([]rune, len(value))
+	for i, b := range value {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+func (d *oxocalDecoder) wideString() string {
+	value := d.bytes(uint32(d.uint16()) * 2)
+	units := make([]uint16, len(value)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(value[i*2:])
+	}
+	return string(utf16.Decode(units))
+}
+
+func (d *oxocalDecoder) exceptionInfo(exception *AppointmentException) {
+	exception.StartDateTime = oxocalTime(d.uint32())
+	exception.EndDateTime = oxocalTime(d.uint32())
+	exception.OriginalStartDate = oxocalTime(d.uint32())
+	exception.OverrideFlags = d.uint16()
+	flags := exception.OverrideFlags
+	if flags&AROSubject != 0 {
+		exception.Subject = d.ansiString()
+	}
+	if flags&AROMeetingType != 0 {
+		exception.MeetingType = d.uint32()
+	}
+	if flags&AROReminderDelta != 0 {
+		exception.ReminderDelta = d.uint32()
+	}
+	if flags&AROReminder != 0 {
+		exception.ReminderSet = d.uint32() != 0
+	}
+	if flags&AROLocation != 0 {
+		exception.Location = d.ansiString()
+	}
+	if flags&AROBusyStatus != 0 {
+		exception.BusyStatus = d.uint32()
+	}
+	if flags&AROAttachment != 0 {
+		exception.Attachment = d.uint32() != 0
+	}
+	if flags&AROSubType != 0 {
+		exception.SubType = d.uint32() != 0
+	}
+	if flags&AROAppointmentColor != 0 {
+		exception.AppointmentColor = d.uint32()
+	}
+}
+
+// extendedException reads the ExtendedException structure, whose wide character subject and location replace
+// the 8-bit copies read from ExceptionInfo
+func (d *oxocalDecoder) extendedException(exception *AppointmentException, writerVersion2 uint32) {
+	if writerVersion2 >= oxocalWriterVersion2 {
+		size := d.uint32()
+		if d.err == nil && size < 4 {
+			d.err = errors.New("change highlight size is smaller than its value")
+			return
+		}
+		exception.ChangeHighlight = d.uint32()
+		exception.ChangeHighlightReserved = d.bytes(size - 4)
+	}
+	exception.ReservedBlockEE1 = d.bytes(d.uint32())
+	if exception.OverrideFlags&(AROSubject|AROLocation) == 0 {
+		return
+	}
+	d.bytes(12) // StartDateTime, EndDateTime and OriginalStartDate repeat the ExceptionInfo values
+	if exception.OverrideFlags&AROSubject != 0 {
+		exception.Subject = d.wideString()
+	}
+	if exception.OverrideFlags&AROLocation != 0 {
+		exception.Location = d.wideString()
+	}
+	exception.ReservedBlockEE2 = d.bytes(d.uint32())
+}
+
+type oxocalEncoder struct {
+	data []byte
+}
+
+func (e *oxocalEncoder) uint16(value uint16) {
+	e.data = binary.LittleEndian.AppendUint16(e.data, value)
+}
+
+func (e *oxocalEncoder) uint32(value uint32) {
+	e.data = binary.LittleEndian.AppendUint32(e.data, value)
+}
+
+func (e *oxocalEncoder) block(value []byte) {
+	e.uint32(uint32(len(value)))
+	e.data = append(e.data, value...)
+}
+
+func (e *oxocalEncoder) dates(dates []time.Time) {
+	e.uint32(uint32(len(dates)))
+	for _, date := range dates {
+		e.uint32(oxocalMinutes(date))
+	}
+}
+
+// ansiString writes a length-prefixed 8-bit string. Characters outside Latin-1 are written as '?'
+func (e *oxocalEncoder) ansiString(value string) {
+	var encoded []byte
+	for _, r := range value {
+		if r > 0xFF {
+			r = '?'
+		}
+		encoded = append(encoded, byte(r))
+	}
+	e.uint16(uint16(len(encoded) + 1))
+	e.uint16(uint16(len(encoded)))
+	e.data = append(e.data, encoded...)
+}
+
+func (e *oxocalEncoder) wideString(value string) {
+	units := utf16.Encode([]rune(value))
+	e.uint16(uint16(len(units)))
+	for _, unit := range units {
+		e.uint16(unit)
+	}
+}
+
+func (e *oxocalEncoder) exceptionInfo(exception *AppointmentException) {
+	e.uint32(oxocalMinutes(exception.StartDateTime))
+	e.uint32(oxocalMinutes(exception.EndDateTime))
+	e.uint32(oxocalMinutes(exception.OriginalStartDate))
+	e.uint16(exception.OverrideFlags)
+	flags := exception.OverrideFlags
+	if flags&AROSubject != 0 {
+		e.ansiString(exception.Subject)
+	}
+	if flags&AROMeetingType != 0 {
+		e.uint32(exception.MeetingType)
+	}
+	if flags&AROReminderDelta != 0 {
+		e.uint32(exception.ReminderDelta)
+	}
+	if flags&AROReminder != 0 {
+		e.uint32(oxocalBool(exception.ReminderSet))
+	}
+	if flags&AROLocation != 0 {
+		e.ansiString(exception.Location)
+	}
+	if flags&AROBusyStatus != 0 {
+		e.uint32(exception.BusyStatus)
+	}
+	if flags&AROAttachment != 0 {
+		e.uint32(oxocalBool(exception.Attachment))
+	}
+	if flags&AROSubType != 0 {
+		e.uint32(oxocalBool(exception.SubType))
+	}
+	if flags&AROAppointmentColor != 0 {
+		e.uint32(exception.AppointmentColor)
+	}
+}
+
+func (e *oxocalEncoder) extendedException(exception *AppointmentException, writerVersion2 uint32) {
+	if writerVersion2 >= oxocalWriterVersion2 {
+		e.uint32(uint32(4 + len(exception.ChangeHighlightReserved)))
+		e.uint32(exception.ChangeHighlight)
+		e.data = append(e.data, exception.ChangeHighlightReserved...)
+	}
+	e.block(exception.ReservedBlockEE1)
+	if exception.OverrideFlags&(AROSubject|AROLocation) == 0 {
+		return
+	}
+	e.uint32(oxocalMinutes(exception.StartDateTime))
+	e.uint32(oxocalMinutes(exception.EndDateTime))
+	e.uint32(oxocalMinutes(exception.OriginalStartDate))
+	if exception.OverrideFlags&AROSubject != 0 {
+		e.wideString(exception.Subject)
+	}
+	if exception.OverrideFlags&AROLocation != 0 {
+		e.wideString(exception.Location)
+	}
+	e.block(exception.ReservedBlockEE2)
+}
+
+func oxocalBool(value bool) uint32 {
+	if value {
+		return 1
+	}
+	return 0
+}
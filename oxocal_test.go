@@ -0,0 +1,345 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Fixtures are laid out field by field following the AppointmentRecurrencePattern structure in MS-OXOCAL 2.2.1.44.
+// All values are little-endian and dates are minutes since 1601-01-01
+var oxocalFixtures = []struct {
+	name     string
+	data     []string
+	expected AppointmentRecurrencePattern
+}{
+	{
+		name: "every 2 days for 10 occurrences",
+		data: []string{
+			"0430 0430",         // ReaderVersion, WriterVersion
+			"0A20 0000 0000",    // RecurFrequency daily, PatternType day, CalendarType default
+			"A0050000",          // FirstDateTime: StartDate % Period
+			"400B0000",          // Period: 2880 minutes
+			"00000000",          // SlidingFlag
+			"22200000 0A000000", // EndType END_AFTER_N_OCCURRENCES, OccurrenceCount 10
+			"00000000",          // FirstDOW Sunday
+			"00000000 00000000", // DeletedInstanceCount, ModifiedInstanceCount
+			"6081020D A0E6020D", // StartDate 2016-01-01, EndDate 2016-01-19
+			"06300000 08300000", // ReaderVersion2, WriterVersion2
+			"3A020000 58020000", // StartTimeOffset 09:30, EndTimeOffset 10:00
+			"0000",              // ExceptionCount
+			"00000000 00000000", // ReservedBlock1Size, ReservedBlock2Size
+		},
+		expected: AppointmentRecurrencePattern{
+			Recurrence: Recurrence{StartDate: time.Date(2016, 1, 1, 9, 30, 0, 0, time.UTC), RecurrencePatternCode: "D", RecurEvery: 2,
+				EndByDate: timePtr(time.Date(2016, 1, 19, 0, 0, 0, 0, time.UTC)), NumberOfOccurrences: int16Ptr(10)},
+			Duration: 30 * time.Minute, WriterVersion2: 0x3008,
+		},
+	},
+	{
+		name: "every 2 weeks on Monday, Wednesday and Friday with a deleted and a modified instance",
+		data: []string{
+			"0430 0430",
+			"0B20 0100 0000",                 // RecurFrequency weekly, PatternType week, CalendarType default
+			"20490000",                       // FirstDateTime: Sunday 2016-01-03 % (Period * 10080)
+			"02000000",                       // Period: 2 weeks
+			"00000000",                       // SlidingFlag
+			"2A000000",                       // PatternTypeSpecific: Monday | Wednesday | Friday
+			"21200000 0A000000",              // EndType END_AFTER_DATE, OccurrenceCount
+			"00000000",                       // FirstDOW Sunday
+			"02000000",                       // DeletedInstanceCount
+			"809D020D C0A8020D",              // 2016-01-06, 2016-01-08
+			"01000000",                       // ModifiedInstanceCount
+			"C0A8020D",                       // 2016-01-08
+			"4092020D 807B060D",              // StartDate 2016-01-04, EndDate 2016-06-30
+			"06300000 09300000",              // ReaderVersion2, WriterVersion2
+			"58020000 76020000",              // StartTimeOffset 10:00, EndTimeOffset 10:30
+			"0100",                           // ExceptionCount
+			"08AC020D 26AC020D 18AB020D",     // ExceptionInfo StartDateTime, EndDateTime, OriginalStartDate
+			"1100",                           // OverrideFlags ARO_SUBJECT | ARO_LOCATION
+			"0600 0500 4D6F766564",           // SubjectLength, SubjectLength2, "Moved"
+			"0700 0600 526F6F6D2032",         // LocationLength, LocationLength2, "Room 2"
+			"00000000",                       // ReservedBlock1Size
+			"04000000 00000000",              // ExtendedException ChangeHighlightSize, ChangeHighlightValue
+			"00000000",                       // ReservedBlockEE1Size
+			"08AC020D 26AC020D 18AB020D",     // StartDateTime, EndDateTime, OriginalStartDate
+			"0500 4D006F0076006500 6400",     // WideCharSubjectLength, "Moved"
+			"0600 52006F006F006D00 20003200", // WideCharLocationLength, "Room 2"
+			"00000000",                       // ReservedBlockEE2Size
+			"00000000",                       // ReservedBlock2Size
+		},
+		expected: AppointmentRecurrencePattern{
+			Recurrence: Recurrence{StartDate: time.Date(2016, 1, 4, 10, 0, 0, 0, time.UTC), RecurrencePatternCode: "W", RecurEvery: 2,
+				WeeklyDaysIncluded: int16Ptr(32 + 8 + 2), EndByDate: timePtr(time.Date(2016, 6, 30, 0, 0, 0, 0, time.UTC))},
+			Duration:              30 * time.Minute,
+			DeletedInstanceDates:  []time.Time{time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC)},
+			ModifiedInstanceDates: []time.Time{time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC)},
+			Exceptions: []AppointmentException{{
+				StartDateTime:     time.Date(2016, 1, 8, 14, 0, 0, 0, time.UTC),
+				EndDateTime:       time.Date(2016, 1, 8, 14, 30, 0, 0, time.UTC),
+				OriginalStartDate: time.Date(2016, 1, 8, 10, 0, 0, 0, time.UTC),
+				OverrideFlags:     AROSubject | AROLocation,
+				Subject:           "Moved",
+				Location:          "Room 2",
+			}},
+			WriterVersion2: 0x3009,
+		},
+	},
+	{
+		name: "last Thursday of every month",
+		data: []string{
+			"0430 0430",
+			"0C20 0300 0000",    // RecurFrequency monthly, PatternType MonthNth, CalendarType default
+			"00000000",          // FirstDateTime: months since 1601-01 % Period
+			"01000000",          // Period: 1 month
+			"00000000",          // SlidingFlag
+			"10000000 05000000", // PatternTypeSpecific: Thursday, last instance
+			"23200000 0A000000", // EndType NEVER_END, OccurrenceCount
+			"00000000",          // FirstDOW Sunday
+			"00000000 00000000", // DeletedInstanceCount, ModifiedInstanceCount
+			"4019030D DF80E95A", // StartDate 2016-01-28, EndDate 4500-08-31 23:59
+			"06300000 09300000", // ReaderVersion2, WriterVersion2
+			"84030000 C0030000", // StartTimeOffset 15:00, EndTimeOffset 16:00
+			"0000",              // ExceptionCount
+			"00000000 00000000", // ReservedBlock1Size, ReservedBlock2Size
+		},
+		expected: AppointmentRecurrencePattern{
+			Recurrence: Recurrence{StartDate: time.Date(2016, 1, 28, 15, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 1,
+				MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54)},
+			Duration: time.Hour, WriterVersion2: 0x3009,
+		},
+	},
+	{
+		name: "February 14 every year",
+		data: []string{
+			"0430 0430",
+			"0D20 0200 0000",    // RecurFrequency yearly, PatternType month, CalendarType default
+			"60AE0000",          // FirstDateTime: 1601-02-01, giving the month of the year
+			"0C000000",          // Period: 12 months
+			"00000000",          // SlidingFlag
+			"0E000000",          // PatternTypeSpecific: day 14
+			"21200000 0A000000", // EndType END_AFTER_DATE, OccurrenceCount
+			"00000000",          // FirstDOW Sunday
+			"00000000 00000000", // DeletedInstanceCount, ModifiedInstanceCount
+			"8054D30C C073FB0C", // StartDate 2010-02-14, EndDate 2015-02-14
+			"06300000 08300000", // ReaderVersion2, WriterVersion2
+			"00000000 A0050000", // StartTimeOffset 00:00, EndTimeOffset 24:00
+			"0000",              // ExceptionCount
+			"00000000 00000000", // ReservedBlock1Size, ReservedBlock2Size
+		},
+		expected: AppointmentRecurrencePattern{
+			Recurrence: Recurrence{StartDate: time.Date(2010, 2, 14, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "Y", RecurEvery: 1,
+				YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(14), EndByDate: timePtr(time.Date(2015, 2, 14, 0, 0, 0, 0, time.UTC))},
+			Duration: 24 * time.Hour, WriterVersion2: 0x3008,
+		},
+	},
+	{
+		name: "every weekday",
+		data: []string{
+			"0430 0430",
+			"0A20 0100 0000",    // RecurFrequency daily, PatternType week, CalendarType default
+			"C0210000",          // FirstDateTime: Sunday 2016-01-03 % 10080
+			"01000000",          // Period: 1 week
+			"00000000",          // SlidingFlag
+			"3E000000",          // PatternTypeSpecific: Monday through Friday
+			"23200000 0A000000", // EndType NEVER_END, OccurrenceCount
+			"00000000",          // FirstDOW Sunday
+			"00000000 00000000", // DeletedInstanceCount, ModifiedInstanceCount
+			"4092020D DF80E95A", // StartDate 2016-01-04, EndDate 4500-08-31 23:59
+			"06300000 08300000", // ReaderVersion2, WriterVersion2
+			"E0010000 1C020000", // StartTimeOffset 08:00, EndTimeOffset 09:00
+			"0000",              // ExceptionCount
+			"00000000 00000000", // ReservedBlock1Size, ReservedBlock2Size
+		},
+		expected: AppointmentRecurrencePattern{
+			Recurrence: Recurrence{StartDate: time.Date(2016, 1, 4, 8, 0, 0, 0, time.UTC), RecurrencePatternCode: "D", RecurEvery: 1,
+				DailyIsOnlyWeekday: boolPtr(true)},
+			Duration: time.Hour, WriterVersion2: 0x3008,
+		},
+	},
+}
+
+func TestAppointmentRecurrencePatternFixtures(t *testing.T) {
+	for _, fixture := range oxocalFixtures {
+		data := oxocalBytes(t, fixture.data...)
+		var actual AppointmentRecurrencePattern
+		if err := actual.UnmarshalBinary(data); err != nil {
+			t.Error(fixture.name, err)
+			continue
+		}
+		compareRecurrences(t, &fixture.expected.Recurrence, &actual.Recurrence, fixture.name)
+		actualExtras, expectedExtras := actual, fixture.expected
+		actualExtras.Recurrence, expectedExtras.Recurrence = Recurrence{}, Recurrence{}
+		if !reflect.DeepEqual(expectedExtras, actualExtras) {
+			t.Errorf("%s: expected %+v vs actual %+v", fixture.name, expectedExtras, actualExtras)
+		}
+
+		encoded, err := fixture.expected.MarshalBinary()
+		if err != nil {
+			t.Error(fixture.name, err)
+			continue
+		}
+		if !bytes.Equal(data, encoded) {
+			t.Errorf("%s: expected\n%X\nactual\n%X", fixture.name, data, encoded)
+		}
+	}
+}
+
+// testdata/oxocal holds complete PidLidAppointmentRecur values encoded independently of MarshalBinary
+func TestAppointmentRecurrencePatternTestdata(t *testing.T) {
+	testdata := []struct {
+		file        string
+		expected    AppointmentRecurrencePattern
+		occurrences []time.Time
+	}{
+		{"daily.bin", AppointmentRecurrencePattern{
+			Recurrence: Recurrence{StartDate: time.Date(2008, 10, 1, 9, 0, 0, 0, time.UTC), RecurrencePatternCode: "D", RecurEvery: 1,
+				EndByDate: timePtr(time.Date(2008, 10, 5, 0, 0, 0, 0, time.UTC)), NumberOfOccurrences: int16Ptr(5)},
+			Duration: 30 * time.Minute, WriterVersion2: 0x3008,
+		}, []time.Time{time.Date(2008, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2008, 10, 2, 0, 0, 0, 0, time.UTC), time.Date(2008, 10, 3, 0, 0, 0, 0, time.UTC),
+			time.Date(2008, 10, 4, 0, 0, 0, 0, time.UTC), time.Date(2008, 10, 5, 0, 0, 0, 0, time.UTC)}},
+		{"weekly.bin", AppointmentRecurrencePattern{
+			Recurrence: Recurrence{StartDate: time.Date(2008, 10, 7, 13, 0, 0, 0, time.UTC), RecurrencePatternCode: "W", RecurEvery: 1,
+				WeeklyDaysIncluded: int16Ptr(16 + 4), EndByDate: timePtr(time.Date(2008, 11, 27, 0, 0, 0, 0, time.UTC))},
+			Duration: time.Hour, WriterVersion2: 0x3009,
+		}, []time.Time{time.Date(2008, 10, 7, 0, 0, 0, 0, time.UTC), time.Date(2008, 10, 9, 0, 0, 0, 0, time.UTC), time.Date(2008, 10, 14, 0, 0, 0, 0, time.UTC),
+			time.Date(2008, 10, 16, 0, 0, 0, 0, time.UTC), time.Date(2008, 10, 21, 0, 0, 0, 0, time.UTC), time.Date(2008, 10, 23, 0, 0, 0, 0, time.UTC),
+			time.Date(2008, 10, 28, 0, 0, 0, 0, time.UTC), time.Date(2008, 10, 30, 0, 0, 0, 0, time.UTC), time.Date(2008, 11, 4, 0, 0, 0, 0, time.UTC),
+			time.Date(2008, 11, 6, 0, 0, 0, 0, time.UTC), time.Date(2008, 11, 11, 0, 0, 0, 0, time.UTC), time.Date(2008, 11, 13, 0, 0, 0, 0, time.UTC),
+			time.Date(2008, 11, 18, 0, 0, 0, 0, time.UTC), time.Date(2008, 11, 20, 0, 0, 0, 0, time.UTC), time.Date(2008, 11, 25, 0, 0, 0, 0, time.UTC),
+			time.Date(2008, 11, 27, 0, 0, 0, 0, time.UTC)}},
+		{"monthly_nth.bin", AppointmentRecurrencePattern{
+			Recurrence: Recurrence{StartDate: time.Date(2008, 10, 14, 10, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 2,
+				MonthlyDayOfWeek: int16Ptr(2), MonthlyWeekOfMonth: int16Ptr(2)},
+			Duration: time.Hour, WriterVersion2: 0x3009,
+		}, []time.Time{time.Date(2008, 10, 14, 0, 0, 0, 0, time.UTC), time.Date(2008, 12, 9, 0, 0, 0, 0, time.UTC), time.Date(2009, 2, 10, 0, 0, 0, 0, time.UTC),
+			time.Date(2009, 4, 14, 0, 0, 0, 0, time.UTC)}},
+		{"yearly.bin", AppointmentRecurrencePattern{
+			Recurrence: Recurrence{StartDate: time.Date(2009, 7, 4, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "Y", RecurEvery: 1,
+				YearlyMonth: int16Ptr(7), MonthlyDay: int16Ptr(4), EndByDate: timePtr(time.Date(2011, 7, 4, 0, 0, 0, 0, time.UTC)), NumberOfOccurrences: int16Ptr(3)},
+			Duration: 24 * time.Hour, WriterVersion2: 0x3008,
+		}, []time.Time{time.Date(2009, 7, 4, 0, 0, 0, 0, time.UTC), time.Date(2010, 7, 4, 0, 0, 0, 0, time.UTC), time.Date(2011, 7, 4, 0, 0, 0, 0, time.UTC)}},
+		{"exceptions.bin", AppointmentRecurrencePattern{
+			Recurrence: Recurrence{StartDate: time.Date(2008, 10, 6, 9, 0, 0, 0, time.UTC), RecurrencePatternCode: "W", RecurEvery: 1,
+				WeeklyDaysIncluded: int16Ptr(32), EndByDate: timePtr(time.Date(2008, 10, 27, 0, 0, 0, 0, time.UTC)), NumberOfOccurrences: int16Ptr(4)},
+			Duration:              time.Hour,
+			DeletedInstanceDates:  []time.Time{time.Date(2008, 10, 13, 0, 0, 0, 0, time.UTC), time.Date(2008, 10, 20, 0, 0, 0, 0, time.UTC)},
+			ModifiedInstanceDates: []time.Time{time.Date(2008, 10, 21, 0, 0, 0, 0, time.UTC)},
+			Exceptions: []AppointmentException{{
+				StartDateTime:     time.Date(2008, 10, 21, 11, 0, 0, 0, time.UTC),
+				EndDateTime:       time.Date(2008, 10, 21, 12, 0, 0, 0, time.UTC),
+				OriginalStartDate: time.Date(2008, 10, 20, 9, 0, 0, 0, time.UTC),
+				OverrideFlags:     AROSubject,
+				Subject:           "Moved",
+			}},
+			WriterVersion2: 0x3009,
+		}, []time.Time{time.Date(2008, 10, 6, 0, 0, 0, 0, time.UTC), time.Date(2008, 10, 13, 0, 0, 0, 0, time.UTC), time.Date(2008, 10, 20, 0, 0, 0, 0, time.UTC),
+			time.Date(2008, 10, 27, 0, 0, 0, 0, time.UTC)}},
+	}
+	for _, test := range testdata {
+		data, err := os.ReadFile(filepath.Join("testdata", "oxocal", test.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual AppointmentRecurrencePattern
+		if err := actual.UnmarshalBinary(data); err != nil {
+			t.Error(test.file, err)
+			continue
+		}
+		compareRecurrences(t, &test.expected.Recurrence, &actual.Recurrence, test.file)
+		actualExtras, expectedExtras := actual, test.expected
+		actualExtras.Recurrence, expectedExtras.Recurrence = Recurrence{}, Recurrence{}
+		if !reflect.DeepEqual(expectedExtras, actualExtras) {
+			t.Errorf("%s: expected %+v vs actual %+v", test.file, expectedExtras, actualExtras)
+		}
+		start, end := time.Date(actual.StartDate.Year(), actual.StartDate.Month(), 1, 0, 0, 0, 0, time.UTC), test.occurrences[len(test.occurrences)-1]
+		if actual.EndByDate != nil {
+			end = start.AddDate(10, 0, 0)
+		}
+		compareTimes(t, test.occurrences, actual.GetOccurrences(start, end), test.file)
+
+		encoded, err := test.expected.MarshalBinary()
+		if err != nil {
+			t.Error(test.file, err)
+			continue
+		}
+		if !bytes.Equal(data, encoded) {
+			t.Errorf("%s: expected\n%X\nactual\n%X", test.file, data, encoded)
+		}
+	}
+}
+
+func TestAppointmentRecurrencePatternOccurrences(t *testing.T) {
+	var p AppointmentRecurrencePattern
+	if err := p.UnmarshalBinary(oxocalBytes(t, oxocalFixtures[2].data...)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []time.Time{time.Date(2016, 2, 25, 0, 0, 0, 0, time.UTC), time.Date(2016, 3, 31, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, p.GetOccurrences(time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC)), "TestAppointmentRecurrencePatternOccurrences")
+}
+
+func TestAppointmentRecurrencePatternErrors(t *testing.T) {
+	daily := oxocalFixtures[0].data
+	var p AppointmentRecurrencePattern
+	if err := p.UnmarshalBinary(oxocalBytes(t, daily[:len(daily)-2]...)); err != errShortPattern {
+		t.Error("expected truncated pattern error", err)
+	}
+
+	hijri := append([]string{"0430 0430", "0C20 0A00 0000"}, daily[2:]...) // HjMonth pattern type
+	if err := p.UnmarshalBinary(oxocalBytes(t, hijri...)); !errors.Is(err, ErrNotRepresentable) {
+		t.Error("expected hijri pattern to be unrepresentable", err)
+	}
+
+	monthly := append([]string(nil), oxocalFixtures[2].data...)
+	monthly[5] = "3E000000 01000000" // first weekday of the month
+	if err := p.UnmarshalBinary(oxocalBytes(t, monthly...)); !errors.Is(err, ErrNotRepresentable) {
+		t.Error("expected first weekday to be unrepresentable", err)
+	}
+
+	p = AppointmentRecurrencePattern{Recurrence: Recurrence{StartDate: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "D", RecurEvery: 1, NumberOfOccurrences: int16Ptr(3)}}
+	if _, err := p.MarshalBinary(); err == nil {
+		t.Error("expected error for numbered recurrence without EndByDate")
+	}
+	p.NumberOfOccurrences = nil
+	p.RecurrencePatternCode = "M"
+	p.MonthlyDayOfWeek, p.MonthlyWeekOfMonth = int16Ptr(1), int16Ptr(5)
+	if _, err := p.MarshalBinary(); !errors.Is(err, ErrNotRepresentable) {
+		t.Error("expected 5th week to be unrepresentable", err)
+	}
+	p.MonthlyDayOfWeek, p.MonthlyWeekOfMonth = nil, nil
+	p.MonthlyDay, p.MonthlyNearestWeekday = int16Ptr(-1), boolPtr(true)
+	if _, err := p.MarshalBinary(); !errors.Is(err, ErrNotRepresentable) {
+		t.Error("expected nearest weekday to be unrepresentable", err)
+	}
+	p.MonthlyDay, p.MonthlyNearestWeekday = int16Ptr(-2), nil
+	if _, err := p.MarshalBinary(); !errors.Is(err, ErrNotRepresentable) {
+		t.Error("expected second to last day to be unrepresentable", err)
+	}
+}
+
+func TestAppointmentRecurrencePatternMonthEnd(t *testing.T) {
+	p := AppointmentRecurrencePattern{Recurrence: Recurrence{StartDate: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-1)}}
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual AppointmentRecurrencePattern
+	if err := actual.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	compareRecurrences(t, &p.Recurrence, &actual.Recurrence, "TestAppointmentRecurrencePatternMonthEnd")
+}
+
+func oxocalBytes(t *testing.T, fields ...string) []byte {
+	data, err := hex.DecodeString(strings.ReplaceAll(strings.Join(fields, ""), " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
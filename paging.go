@@ -0,0 +1,150 @@
+package calendar
+
+import (
+	"encoding/base64"
+	"fmt"
+	"iter"
+	"time"
+)
+
+const cursorDateLayout = "20060102"
+
+// OccurrencePage is one page of the occurrences of a recurrence, returned by Page
+type OccurrencePage struct {
+	Occurrences []time.Time // in chronological order
+	Next        string      // cursor of the page after this one, empty when there are no later occurrences
+	Previous    string      // cursor of the page before this one, empty when there are no earlier occurrences
+}
+
+// CursorAt returns a cursor whose page starts with the first occurrence on or after date, e.g. to list the
+// occurrences from today
+func CursorAt(date time.Time) string {
+	return encodeCursor('a', time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1))
+}
+
+// Page returns up to size occurrences from the StartDate after the Next cursor or before the Previous cursor of
+// another page, or from the first occurrence when cursor is empty. Cursors hold only the date of an occurrence, so
+// they stay valid as long as the recurrence is not changed and can be handed to clients as they are
+func (r *Recurrence) Page(cursor string, size int) (*OccurrencePage, error) {
+	return r.page(cursor, size, func(time.Time) bool { return false })
+}
+
+// Page is Recurrence.Page without the ExceptionDates
+func (s *Series) Page(cursor string, size int) (*OccurrencePage, error) {
+	return s.Recurrence.page(cursor, size, s.isExceptionDate)
+}
+
+func (r *Recurrence) page(cursor string, size int, excluded func(time.Time) bool) (*OccurrencePage, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("page size must be at least 1, got %d", size)
+	}
+	direction, date := byte('a'), r.startDate().AddDate(0, 0, -1)
+	if cursor != "" {
+		var err error
+		if direction, date, err = decodeCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+	page := &OccurrencePage{}
+	var occurrences iter.Seq[time.Time]
+	if direction == 'a' {
+		occurrences = r.occurrencesFrom(date.AddDate(0, 0, 1))
+	} else {
+		occurrences = r.occurrencesBefore(date)
+	}
+	more := false
+	for occurrence := range occurrences {
+		if excluded(occurrence) {
+			continue
+		}
+		if len(page.Occurrences) == size {
+			more = true
+			break
+		}
+		page.Occurrences = append(page.Occurrences, occurrence)
+	}
+	if len(page.Occurrences) == 0 {
+		return page, nil
+	}
+	if direction == 'b' {
+		for i, j := 0, len(page.Occurrences)-1; i < j; i, j = i+1, j-1 {
+			page.Occurrences[i], page.Occurrences[j] = page.Occurrences[j], page.Occurrences[i]
+		}
+	}
+	first, last := page.Occurrences[0], page.Occurrences[len(page.Occurrences)-1]
+	if direction == 'b' && more || direction == 'a' && r.hasOccurrenceBefore(first, excluded) {
+		page.Previous = encodeCursor('b', first)
+	}
+	if direction == 'a' && more || direction == 'b' && r.hasOccurrenceAfter(last, excluded) {
+		page.Next = encodeCursor('a', last)
+	}
+	return page, nil
+}
+
+// occurrencesBefore yields the occurrences from the StartDate that are before date, latest first. Daily and
+// weekly occurrences are found by position; monthly and yearly ones are collected from the StartDate
+func (r *Recurrence) occurrencesBefore(date time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		startDate := r.startDate()
+		if !date.After(startDate) || r.RecurEvery < 1 {
+			return
+		}
+		switch r.RecurrencePatternCode {
+		case FrequencyDaily, FrequencyWeekly:
+			for n := r.Count(startDate, date.AddDate(0, 0, -1)) - 1; n >= 0; n-- {
+				occurrence, ok := r.OccurrenceAt(n)
+				if !ok || !yield(occurrence) {
+					return
+				}
+			}
+			return
+		}
+		var occurrences []time.Time
+		for occurrence := range r.occurrencesFrom(startDate) {
+			if !occurrence.Before(date) {
+				break
+			}
+			occurrences = append(occurrences, occurrence)
+		}
+		for i := len(occurrences) - 1; i >= 0; i-- {
+			if !yield(occurrences[i]) {
+				return
+			}
+		}
+	}
+}
+
+func (r *Recurrence) hasOccurrenceBefore(date time.Time, excluded func(time.Time) bool) bool {
+	for occurrence := range r.occurrencesBefore(date) {
+		if !excluded(occurrence) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recurrence) hasOccurrenceAfter(date time.Time, excluded func(time.Time) bool) bool {
+	for occurrence := range r.occurrencesFrom(date.AddDate(0, 0, 1)) {
+		if !excluded(occurrence) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeCursor writes the direction, a for after or b for before, and the date
+func encodeCursor(direction byte, date time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(string(direction) + date.Format(cursorDateLayout)))
+}
+
+func decodeCursor(cursor string) (byte, time.Time, error) {
+	value, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(value) != 1+len(cursorDateLayout) || value[0] != 'a' && value[0] != 'b' {
+		return 0, time.Time{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	date, err := time.Parse(cursorDateLayout, string(value[1:]))
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return value[0], date, nil
+}
@@ -0,0 +1,100 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPage(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 30, 0, 0, time.UTC)
+	r := Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(42)} // MWF every 2 weeks
+	page, err := r.Page("", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []time.Time{time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 11, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 13, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, page.Occurrences, "TestPage, first page")
+	if page.Previous != "" || page.Next == "" {
+		t.Error("expected only a next page", page)
+	}
+	page, err = r.Page(page.Next, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []time.Time{time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 25, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 27, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, page.Occurrences, "TestPage, second page")
+	page, err = r.Page(page.Previous, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []time.Time{time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 11, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 13, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, page.Occurrences, "TestPage, back to the first page")
+	if page.Previous != "" {
+		t.Error("expected no page before the first", page.Previous)
+	}
+
+	page, err = r.Page(CursorAt(time.Date(2116, 1, 4, 9, 0, 0, 0, time.Local)), 2) // a Saturday in a week without occurrences
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []time.Time{time.Date(2116, 1, 13, 0, 0, 0, 0, time.UTC), time.Date(2116, 1, 15, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, page.Occurrences, "TestPage, from a date 100 years on")
+
+	for _, cursor := range []string{"x", encodeCursor('c', start), "YTIwMTYxMzAx"} {
+		if _, err := r.Page(cursor, 3); err == nil {
+			t.Error("expected an invalid cursor", cursor)
+		}
+	}
+	if _, err := r.Page("", 0); err == nil {
+		t.Error("expected an invalid page size")
+	}
+}
+
+func TestPageThroughVariedRecurrences(t *testing.T) {
+	for i, r := range variedRecurrences() {
+		if r.EndByDate == nil {
+			continue
+		}
+		s := Series{Recurrence: r}
+		if occurrence, ok := r.OccurrenceAt(2); ok {
+			s.ExceptionDates = []time.Time{occurrence}
+		}
+		var expected []time.Time
+		for n := 0; ; n++ {
+			occurrence, ok := r.OccurrenceAt(n)
+			if !ok {
+				break
+			}
+			if !s.isExceptionDate(occurrence) {
+				expected = append(expected, occurrence)
+			}
+		}
+
+		var forward, backward []time.Time
+		var pages []*OccurrencePage
+		for cursor := ""; ; {
+			page, err := s.Page(cursor, 4)
+			if err != nil {
+				t.Fatal(i, err)
+			}
+			forward = append(forward, page.Occurrences...)
+			pages = append(pages, page)
+			if cursor = page.Next; cursor == "" {
+				break
+			}
+		}
+		compareTimes(t, expected, forward, "TestPageThroughVariedRecurrences, forward")
+		for cursor := pages[len(pages)-1].Previous; cursor != ""; {
+			page, err := s.Page(cursor, 4)
+			if err != nil {
+				t.Fatal(i, err)
+			}
+			backward = append(page.Occurrences, backward...)
+			cursor = page.Previous
+		}
+		if len(pages) > 0 {
+			backward = append(backward, pages[len(pages)-1].Occurrences...)
+		}
+		compareTimes(t, expected, backward, "TestPageThroughVariedRecurrences, backward")
+	}
+}
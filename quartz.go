@@ -0,0 +1,148 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// quartzDayNames are the Quartz names of the days of the week, which number them from 1 for Sunday
+var quartzDayNames = append([]string{""}, cronDayNames...)
+
+// ParseQuartz converts a Quartz cron expression (second minute hour day-of-month month day-of-week [year]) to the
+// recurrences that fire at the same times, starting on the date of start in its time zone. Exactly one of the day
+// fields must be ?. The L, L-n, LW and nW day of the month selectors and the n#k and nL day of the week selectors are
+// supported. As with ParseCron, one Recurrence is returned per time of day and per day or month that a single
+// Recurrence cannot combine. Days of the week limited to some months and restricted years return ErrNotRepresentable
+func ParseQuartz(expr string, start time.Time) ([]Recurrence, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 6 && len(fields) != 7 {
+		return nil, fmt.Errorf("quartz expression %q must have 6 or 7 fields", expr)
+	}
+	seconds, err := parseCronField(fields[0], 0, 59, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quartz second %q: %w", fields[0], err)
+	}
+	minutes, err := parseCronField(fields[1], 0, 59, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quartz minute %q: %w", fields[1], err)
+	}
+	hours, err := parseCronField(fields[2], 0, 23, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quartz hour %q: %w", fields[2], err)
+	}
+	months, err := parseCronField(fields[4], 1, 12, cronMonthNames)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quartz month %q: %w", fields[4], err)
+	}
+	if len(fields) == 7 && fields[6] != "*" {
+		return nil, fmt.Errorf("%w: quartz expression runs in some years", ErrNotRepresentable)
+	}
+	if (fields[3] == "?") == (fields[5] == "?") {
+		return nil, fmt.Errorf("quartz expression %q must have exactly one day field of ?", expr)
+	}
+
+	var dates []Recurrence
+	if fields[5] == "?" {
+		selectors, err := parseQuartzMonthDays(fields[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid quartz day of month %q: %w", fields[3], err)
+		}
+		if len(selectors) == 31 {
+			if len(months) != 12 {
+				return nil, fmt.Errorf("%w: quartz expression runs every day of some months", ErrNotRepresentable)
+			}
+			dates = append(dates, Recurrence{StartDate: start, RecurrencePatternCode: FrequencyDaily, RecurEvery: 1})
+		} else if dates, err = cronMonthlyDates(selectors, months, start); err != nil {
+			return nil, err
+		}
+		return cronTimes(dates, hours, minutes, seconds), nil
+	}
+
+	selector, weekdays, err := parseQuartzWeekdays(fields[5])
+	if err != nil {
+		return nil, fmt.Errorf("invalid quartz day of week %q: %w", fields[5], err)
+	}
+	switch {
+	case selector != nil:
+		if dates, err = cronMonthlyDates([]Recurrence{*selector}, months, start); err != nil {
+			return nil, err
+		}
+	case len(months) != 12:
+		return nil, fmt.Errorf("%w: quartz expression runs on days of the week in some months", ErrNotRepresentable)
+	case len(weekdays) == 7:
+		dates = append(dates, Recurrence{StartDate: start, RecurrencePatternCode: FrequencyDaily, RecurEvery: 1})
+	default:
+		var weeklyDaysIncluded int16
+		for _, weekday := range weekdays {
+			weeklyDaysIncluded |= weekdayBit(time.Weekday(weekday - 1))
+		}
+		dates = append(dates, Recurrence{StartDate: start, RecurrencePatternCode: FrequencyWeekly, RecurEvery: 1, WeeklyDaysIncluded: &weeklyDaysIncluded})
+	}
+	return cronTimes(dates, hours, minutes, seconds), nil
+}
+
+// parseQuartzMonthDays returns a day selector for each day of a day of the month field
+func parseQuartzMonthDays(field string) ([]Recurrence, error) {
+	nearest := strings.HasSuffix(field, "W")
+	day := strings.TrimSuffix(field, "W")
+	var monthlyDay int16
+	switch {
+	case day == "L":
+		monthlyDay = -1
+	case strings.HasPrefix(day, "L-"):
+		offset, err := strconv.Atoi(day[2:])
+		if err != nil || offset < 0 || offset > 30 {
+			return nil, fmt.Errorf("invalid offset %q", day[2:])
+		}
+		monthlyDay = int16(-offset - 1)
+	case nearest:
+		value, err := parseCronValue(day, 1, 31, nil)
+		if err != nil {
+			return nil, err
+		}
+		monthlyDay = int16(value)
+	default:
+		days, err := parseCronField(field, 1, 31, nil)
+		if err != nil {
+			return nil, err
+		}
+		return cronMonthlyDays(days), nil
+	}
+	selector := Recurrence{MonthlyDay: &monthlyDay}
+	if nearest {
+		selector.MonthlyNearestWeekday = &nearest
+	}
+	return []Recurrence{selector}, nil
+}
+
+// parseQuartzWeekdays returns either an nth day of the week selector for the n#k and nL forms or the days of the week
+// numbered from 1 for Sunday
+func parseQuartzWeekdays(field string) (*Recurrence, []int, error) {
+	if field == "L" { // L alone is the last day of the week
+		return nil, []int{7}, nil
+	}
+	day, week, isNth := strings.Cut(field, "#")
+	isLast := !isNth && len(field) > 1 && strings.HasSuffix(field, "L")
+	if !isNth && !isLast {
+		weekdays, err := parseCronField(field, 1, 7, quartzDayNames)
+		return nil, weekdays, err
+	}
+	weekOfMonth := int16(54)
+	if isLast {
+		day = strings.TrimSuffix(field, "L")
+	} else {
+		value, err := strconv.Atoi(week)
+		if err != nil || value < 1 || value > 5 {
+			return nil, nil, fmt.Errorf("invalid week %q", week)
+		}
+		weekOfMonth = int16(value)
+	}
+	value, err := parseCronValue(day, 1, 7, quartzDayNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	dayOfWeek := int16(value - 1)
+	return &Recurrence{MonthlyDayOfWeek: &dayOfWeek, MonthlyWeekOfMonth: &weekOfMonth}, nil, nil
+}
@@ -0,0 +1,87 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseQuartz(t *testing.T) {
+	start := time.Date(2016, 2, 10, 0, 0, 0, 0, time.UTC) // Wednesday
+	at := func(month time.Month, day, hour, minute, second int) time.Time {
+		return time.Date(2016, month, day, hour, minute, second, 0, time.UTC)
+	}
+	expressions := []struct {
+		expr     string
+		expected []Recurrence
+	}{
+		{"0 30 9 * * ?", []Recurrence{{StartDate: at(2, 10, 9, 30, 0), RecurrencePatternCode: "D", RecurEvery: 1}}},
+		{"15 30 9 ? * * *", []Recurrence{{StartDate: at(2, 10, 9, 30, 15), RecurrencePatternCode: "D", RecurEvery: 1}}},
+		{"0,30 0 8 ? * MON-FRI", []Recurrence{
+			{StartDate: at(2, 10, 8, 0, 0), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(62)},
+			{StartDate: at(2, 10, 8, 0, 30), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(62)},
+		}},
+		{"0 0 12 ? * 1,7", []Recurrence{{StartDate: at(2, 10, 12, 0, 0), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(64 + 1)}}},
+		{"0 0 12 ? * L", []Recurrence{{StartDate: at(2, 10, 12, 0, 0), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(1)}}},
+		{"0 0 12 1,15 * ?", []Recurrence{
+			{StartDate: at(2, 10, 12, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(1)},
+			{StartDate: at(2, 10, 12, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15)},
+		}},
+		{"0 0 12 15W * ?", []Recurrence{{StartDate: at(2, 10, 12, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15), MonthlyNearestWeekday: boolPtr(true)}}},
+		{"0 0 12 L * ?", []Recurrence{{StartDate: at(2, 10, 12, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-1)}}},
+		{"0 0 12 L-3 * ?", []Recurrence{{StartDate: at(2, 10, 12, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-4)}}},
+		{"0 0 12 LW */3 ?", []Recurrence{{StartDate: at(4, 1, 12, 0, 0), RecurrencePatternCode: "M", RecurEvery: 3, MonthlyDay: int16Ptr(-1), MonthlyNearestWeekday: boolPtr(true)}}},
+		{"0 0 12 ? * 6#3", []Recurrence{{StartDate: at(2, 10, 12, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(5), MonthlyWeekOfMonth: int16Ptr(3)}}},
+		{"0 0 12 ? * 5L", []Recurrence{{StartDate: at(2, 10, 12, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54)}}},
+		{"0 0 12 ? NOV THU#4", []Recurrence{{StartDate: at(2, 10, 12, 0, 0), RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(4)}}},
+		{"0 0 0 L-29 1,2 ?", []Recurrence{ // February has no day 30 from the end
+			{StartDate: at(2, 10, 0, 0, 0), RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(1), MonthlyDay: int16Ptr(-30)},
+		}},
+	}
+	for _, expression := range expressions {
+		actual, err := ParseQuartz(expression.expr, start)
+		if err != nil {
+			t.Error(expression.expr, err)
+			continue
+		}
+		if len(actual) != len(expression.expected) {
+			t.Errorf("%s: expected %d recurrences vs actual %d", expression.expr, len(expression.expected), len(actual))
+			continue
+		}
+		for i := range actual {
+			compareRecurrences(t, &expression.expected[i], &actual[i], fmt.Sprintf("%s[%d]", expression.expr, i))
+		}
+	}
+
+	// October 15 2016 is a Saturday
+	r, err := ParseQuartz("0 0 0 15W * ?", time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(r) != 1 {
+		t.Fatal(err)
+	}
+	expected := []time.Time{time.Date(2016, 10, 14, 0, 0, 0, 0, time.UTC), time.Date(2016, 11, 15, 0, 0, 0, 0, time.UTC), time.Date(2016, 12, 15, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, r[0].GetOccurrences(r[0].StartDate, time.Date(2016, 12, 31, 0, 0, 0, 0, time.UTC)), "15W")
+}
+
+func TestParseQuartzErrors(t *testing.T) {
+	start := time.Date(2016, 2, 10, 0, 0, 0, 0, time.UTC)
+	unrepresentable := []string{
+		"0 0 0 * 6-8 ?",
+		"0 0 0 ? 12 MON",
+		"0 0 0 1 * ? 2017",
+	}
+	for _, expr := range unrepresentable {
+		if _, err := ParseQuartz(expr, start); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be unrepresentable: %v", expr, err)
+		}
+	}
+
+	invalid := []string{"", "0 0 * * ?", "0 0 0 * * ? * *", "0 0 0 * * *", "0 0 0 ? * ?", "60 0 0 * * ?", "0 0 0 32 * ?",
+		"0 0 0 ? * 0", "0 0 0 ? * 8", "0 0 0 L-31 * ?", "0 0 0 32W * ?", "0 0 0 ? * 6#6", "0 0 0 ? * 6#", "0 0 0 ? * 8L", "0 0 0 31 2 ?",
+		"0 0 0 1W,15W * ?"}
+	for _, expr := range invalid {
+		if _, err := ParseQuartz(expr, start); err == nil || errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be invalid: %v", expr, err)
+		}
+	}
+}
@@ -1,60 +1,164 @@
 package calendar
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math"
 	"time"
 )
 
+// ErrNotRepresentable is returned when a recurrence cannot be converted to or from another format without losing meaning
+var ErrNotRepresentable = errors.New("recurrence is not representable")
+
 type Recurrence struct {
 	StartDate             time.Time  // Date to start Recurrence. Note that time and time zone information is NOT used in calculations
-	RecurrencePatternCode string     // D for daily, W for weekly, M for monthly or Y for yearly
+	RecurrencePatternCode Frequency  // D for daily, W for weekly, M for monthly or Y for yearly. see the Frequency constants
 	RecurEvery            int16      // number of days, weeks, months or years between occurrences
 	YearlyMonth           *int16     // month of the year to recur (applies only to RecurrencePatternCode: Y)
 	MonthlyWeekOfMonth    *int16     // week of the month to recur. used together with MonthlyDayOfWeek (applies only to RecurrencePatternCode: M or Y)
 	MonthlyDayOfWeek      *int16     // day of the week to recur. used together with MonthlyWeekOfMonth (applies only to RecurrencePatternCode: M or Y)
-	MonthlyDay            *int16     // day of the month to recur (applies only to RecurrencePatternCode: M or Y)
-	WeeklyDaysIncluded    *int16     // integer representing binary values AND'd together for 1000000-64 (Sun), 0100000-32 (Mon), 0010000-16 (Tu), 0001000-8 (W), 0000100-4 (Th), 0000010-2 (F), 0000001-1 (Sat). (applies only to RecurrencePatternCode: M or Y)
+	MonthlyDay            *int16     // day of the month to recur. negative counts back from the end of the month, -1 being the last day (applies only to RecurrencePatternCode: M or Y)
+	MonthlyNearestWeekday *bool      // indicator that MonthlyDay moves to the nearest weekday in the same month when it falls on a weekend (applies only to RecurrencePatternCode: M or Y)
+	WeeklyDaysIncluded    *int16     // integer representing binary values AND'd together for 1000000-64 (Sun), 0100000-32 (Mon), 0010000-16 (Tu), 0001000-8 (W), 0000100-4 (Th), 0000010-2 (F), 0000001-1 (Sat). Weekdays and SetWeekdays convert it to a Weekdays set (applies only to RecurrencePatternCode: W)
 	DailyIsOnlyWeekday    *bool      // indicator that daily recurrences should only be on weekdays (applies only to RecurrencePatternCode: D)
-	EndByDate             *time.Time // date by which all occurrences must end by. Note that time and time zone information is NOT used in calculations
+	EndByDate             *time.Time // date by which all occurrences must end by, an occurrence on it included. Note that time and time zone information is NOT used in calculations
+	NumberOfOccurrences   *int16     // number of occurrences the recurrence was created with. Data for UI and format conversions only; EndByDate must be calculated from it, see SetNumberOfOccurrences
 }
 
+// GetOccurrences returns the dates of the occurrences from timePeriodStart through timePeriodEnd, at midnight UTC.
+// Callers must Validate a recurrence they did not build: an invalid one, e.g. with an unknown RecurrencePatternCode,
+// has no occurrences here, while GetOccurrencesContext returns the error
 func (r *Recurrence) GetOccurrences(timePeriodStart, timePeriodEnd time.Time) []time.Time {
+	occurrences, err := r.occurrences(context.Background(), timePeriodStart, timePeriodEnd)
+	if err != nil {
+		return []time.Time{}
+	}
+	return occurrences
+}
+
+// contextCheckPeriods is the number of days, weeks, months or years expanded between checks of the context
+const contextCheckPeriods = 1024
+
+// occurrences is GetOccurrences returning an error for a recurrence it cannot expand, and the error of ctx once it
+// is done
+func (r *Recurrence) occurrences(ctx context.Context, timePeriodStart, timePeriodEnd time.Time) ([]time.Time, error) {
+	if r.RecurEvery < 1 {
+		return nil, fmt.Errorf("interval must be at least 1, got %d", r.RecurEvery) // an interval of 0 never gets past the start
+	}
 	// Remove all time and time zone information from the recurrence start and end dates
 	startDate := time.Date(r.StartDate.Year(), r.StartDate.Month(), r.StartDate.Day(), 0, 0, 0, 0, time.UTC)
 	var endDate *time.Time
 	if r.EndByDate != nil {
 		end := time.Date(r.EndByDate.Year(), r.EndByDate.Month(), r.EndByDate.Day(), 0, 0, 0, 0, time.UTC)
 		endDate = &end
+		if end.Before(timePeriodEnd) {
+			timePeriodEnd = end // occurrences on the EndByDate are included, later ones in its week or month are not
+		}
 	}
-	switch {
-	case r.RecurrencePatternCode == "D":
+	var occurrences []time.Time
+	switch r.RecurrencePatternCode {
+	case FrequencyDaily:
 		dailyIsOnlyWeekday := false
 		if r.DailyIsOnlyWeekday != nil {
 			dailyIsOnlyWeekday = *r.DailyIsOnlyWeekday
 		}
-		return getDailyOccurrences(startDate, int(r.RecurEvery), dailyIsOnlyWeekday, endDate, timePeriodStart, timePeriodEnd)
-	case r.RecurrencePatternCode == "W":
-		var weeklyDaysIncluded int16 = 127 // all days
-		if r.WeeklyDaysIncluded != nil {
-			weeklyDaysIncluded = *r.WeeklyDaysIncluded
+		occurrences = getDailyOccurrences(ctx, startDate, int(r.RecurEvery), dailyIsOnlyWeekday, endDate, timePeriodStart, timePeriodEnd)
+	case FrequencyWeekly:
+		occurrences = getWeeklyOccurrences(ctx, startDate, int(r.RecurEvery), r.Weekdays().Days(), endDate, timePeriodStart, timePeriodEnd)
+	case FrequencyMonthly:
+		occurrences = getMonthlyOccurrences(ctx, startDate, int(r.RecurEvery), r.MonthlyDay, r.MonthlyDayOfWeek, r.MonthlyWeekOfMonth, endDate, timePeriodStart, timePeriodEnd, r.MonthlyNearestWeekday)
+	case FrequencyYearly:
+		if r.YearlyMonth == nil {
+			return nil, fmt.Errorf("yearly recurrence has no YearlyMonth")
 		}
-		return getWeeklyOccurrences(startDate, int(r.RecurEvery), getIncludedWeeklyDays(weeklyDaysIncluded), endDate, timePeriodStart, timePeriodEnd)
-	case r.RecurrencePatternCode == "M":
-		return getMonthlyOccurrences(startDate, int(r.RecurEvery), r.MonthlyDay, r.MonthlyDayOfWeek, r.MonthlyWeekOfMonth, endDate, timePeriodStart, timePeriodEnd)
-	case r.RecurrencePatternCode == "Y":
-		return getYearlyOccurrences(startDate, int(r.RecurEvery), r.YearlyMonth, r.MonthlyDay, r.MonthlyDayOfWeek, r.MonthlyWeekOfMonth, endDate, timePeriodStart, timePeriodEnd)
+		occurrences = getYearlyOccurrences(ctx, startDate, int(r.RecurEvery), r.YearlyMonth, r.MonthlyDay, r.MonthlyDayOfWeek, r.MonthlyWeekOfMonth, endDate, timePeriodStart, timePeriodEnd, r.MonthlyNearestWeekday)
+	default:
+		return nil, fmt.Errorf("unknown recurrence pattern code %q", r.RecurrencePatternCode)
 	}
-	return []time.Time{}
+	if err := ctx.Err(); err != nil {
+		return nil, err // the expansion stopped early
+	}
+	return occurrences, nil
+}
+
+// isDone reports whether ctx is done, checking it every contextCheckPeriods periods
+func isDone(ctx context.Context, periods int) bool {
+	return periods%contextCheckPeriods == 0 && ctx.Err() != nil
 }
 
+// IsValidOccurrenceDate reports whether the date of occurrenceDate is an occurrence. Like GetOccurrences, it is
+// false for every date of an invalid recurrence
 func (r *Recurrence) IsValidOccurrenceDate(occurrenceDate time.Time) bool {
 	// Remove all time and time zone information from the occurrenceDate
 	date := time.Date(occurrenceDate.Year(), occurrenceDate.Month(), occurrenceDate.Day(), 0, 0, 0, 0, time.UTC)
-	occurrences := r.GetOccurrences(date, date)
-	return len(occurrences) == 1 && occurrences[0] == date
+	if r.RecurEvery < 1 {
+		return false // an interval of 0 never gets past the start
+	}
+	if endDate := r.endDate(); endDate != nil && date.After(*endDate) {
+		return false
+	}
+	startDate := r.startDate()
+	switch {
+	case r.RecurrencePatternCode == FrequencyDaily:
+		return isDailyOccurrence(startDate, int(r.RecurEvery), r.DailyIsOnlyWeekday != nil && *r.DailyIsOnlyWeekday, date)
+	case r.RecurrencePatternCode == FrequencyWeekly:
+		return isWeeklyOccurrence(startDate, int(r.RecurEvery), r.Weekdays(), date)
+	case r.RecurrencePatternCode == FrequencyMonthly:
+		return isMonthlyOccurrence(startDate, int(r.RecurEvery), r.MonthlyDay, r.MonthlyDayOfWeek, r.MonthlyWeekOfMonth, r.MonthlyNearestWeekday, date)
+	case r.RecurrencePatternCode == FrequencyYearly:
+		return isYearlyOccurrence(startDate, int(r.RecurEvery), r.YearlyMonth, r.MonthlyDay, r.MonthlyDayOfWeek, r.MonthlyWeekOfMonth, r.MonthlyNearestWeekday, date)
+	}
+	return false
 }
 
-func getDailyOccurrences(recurrenceStartDate time.Time, recurEvery int, dailyIsOnlyWeekday bool, recurrenceEndByDate *time.Time, timePeriodStart, timePeriodEnd time.Time) []time.Time {
+/**********************************************************************************************************
+The is*Occurrence functions decide whether a single date is an occurrence with arithmetic rather than by
+   expanding the recurrence, so that checking a date far from the start or with a large RecurEvery takes
+   the same time as checking one next to it. They give the same answer as GetOccurrences(date, date)
+************************************************************************************************************/
+func isDailyOccurrence(startDate time.Time, recurEvery int, dailyIsOnlyWeekday bool, date time.Time) bool {
+	days := getDays(startDate, date)
+	switch {
+	case days < 0:
+		return false
+	case days == 0:
+		return true // the start date is an occurrence even when it is on a weekend
+	case dailyIsOnlyWeekday:
+		return isWeekday(date) && getWeekdays(days, startDate)%recurEvery == 0
+	}
+	return days%recurEvery == 0
+}
+
+// isWeeklyOccurrence counts whole weeks from the beginning of the week of the start
+func isWeeklyOccurrence(startDate time.Time, recurEvery int, weekdays Weekdays, date time.Time) bool {
+	weekStartDate := date.AddDate(0, 0, -1*int(date.Weekday()))
+	weeks := getWeeks(startDate.AddDate(0, 0, -1*int(startDate.Weekday())), weekStartDate)
+	return !date.Before(startDate) && weeks%recurEvery == 0 && weekdays.Has(date.Weekday())
+}
+
+// isMonthlyOccurrence finds the occurrence in the month of date from the first of the month
+func isMonthlyOccurrence(startDate time.Time, recurEvery int, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth *int16, monthlyNearestWeekday *bool, date time.Time) bool {
+	monthStartDate := date.AddDate(0, 0, 1-date.Day())
+	if date.Before(startDate) || getMonths(startDate.AddDate(0, 0, 1-startDate.Day()), monthStartDate)%recurEvery != 0 {
+		return false
+	}
+	return isMonthOccurrence(monthStartDate, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth, monthlyNearestWeekday, date)
+}
+
+// isYearlyOccurrence finds the occurrence in yearlyMonth from the first of the month
+func isYearlyOccurrence(startDate time.Time, recurEvery int, yearlyMonth, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth *int16, monthlyNearestWeekday *bool, date time.Time) bool {
+	if yearlyMonth == nil || date.Month() != time.Month(*yearlyMonth) || date.Before(startDate) || (date.Year()-startDate.Year())%recurEvery != 0 {
+		return false
+	}
+	return isMonthOccurrence(date.AddDate(0, 0, 1-date.Day()), monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth, monthlyNearestWeekday, date)
+}
+
+func isMonthOccurrence(startDate time.Time, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth *int16, monthlyNearestWeekday *bool, date time.Time) bool {
+	return len(getMonthOccurrence(startDate, date, date, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth, monthlyNearestWeekday)) == 1
+}
+
+func getDailyOccurrences(ctx context.Context, recurrenceStartDate time.Time, recurEvery int, dailyIsOnlyWeekday bool, recurrenceEndByDate *time.Time, timePeriodStart, timePeriodEnd time.Time) []time.Time {
 	recurrences := []time.Time{}
 	currentDate := recurrenceStartDate
 	if currentDate.Before(timePeriodStart) {
@@ -64,7 +168,7 @@ func getDailyOccurrences(recurrenceStartDate time.Time, recurEvery int, dailyIsO
 			currentDate = getDailyStartTime(recurrenceStartDate, recurEvery, timePeriodStart)
 		}
 	}
-	for currentDate.Before(timePeriodEnd) || currentDate.Equal(timePeriodEnd) {
+	for periods := 0; (currentDate.Before(timePeriodEnd) || currentDate.Equal(timePeriodEnd)) && !isDone(ctx, periods); periods++ {
 		recurrences = append(recurrences, currentDate)
 		if dailyIsOnlyWeekday {
 			currentDate = addWeekdays(int(recurEvery), currentDate)
@@ -102,12 +206,12 @@ From https://www.ietf.org/rfc/rfc2445.txt
 ************************************************************************************************************/
 func getWeekdayStartTime(recurrenceStartDate time.Time, recurEvery int, timePeriodStart time.Time) time.Time {
 	days := getDays(recurrenceStartDate, timePeriodStart)
-	weekdays := getWeekdays(days, recurrenceStartDate)
-	startDateTime := recurrenceStartDate.AddDate(0, 0, getStartAdder(weekdays, recurEvery)+days)
-	if startDateTime.Weekday() == time.Sunday || startDateTime.Weekday() == time.Saturday {
-		startDateTime = startDateTime.AddDate(0, 0, 2) // add 2 days either way.  if Saturday, we need two to get to Monday, if Sunday then 2 to make up for Saturday which is also not a weekday
+	startDateTime := recurrenceStartDate.AddDate(0, 0, days)
+	for !isWeekday(startDateTime) {
+		startDateTime = startDateTime.AddDate(0, 0, 1) // a Saturday or Sunday time period start moves to the Monday
+		days++
 	}
-	return startDateTime
+	return addWeekdays(getStartAdder(getWeekdays(days, recurrenceStartDate), recurEvery), startDateTime)
 }
 
 func getDays(recurrenceStartDate, timePeriodStart time.Time) int {
@@ -115,29 +219,35 @@ func getDays(recurrenceStartDate, timePeriodStart time.Time) int {
 }
 
 func getWeekdays(days int, firstOccurrence time.Time) int {
-	weeks := days / 7
-	weekdays := weeks * 5
-	extradays := days - weeks*7 // number of days past the full weeks (add extra weekdays below)
-	for i := 1; i <= extradays; i++ {
-		tmpDate := firstOccurrence.AddDate(0, 0, i)
-		if tmpDate.Weekday() != time.Sunday && tmpDate.Weekday() != time.Saturday {
-			weekdays++ // date is a weekday so add it to the total weekdays
-		}
-	}
-	return weekdays
+	// count the weekdays in the days after firstOccurrence as the weekdays before its end less those before its start
+	from := int(firstOccurrence.Weekday()) + 1
+	return weekdaysBefore(from+days) - weekdaysBefore(from)
+}
+
+// weekdaysBefore returns the number of weekdays in the first days of a calendar that begins on a Sunday
+func weekdaysBefore(days int) int {
+	return days/7*5 + max(days%7-1, 0)
 }
 
 func addWeekdays(weekdays int, startDate time.Time) time.Time {
-	endTime := startDate
-	for i := 1; weekdays > 0; i++ {
-		endTime = startDate.AddDate(0, 0, i)
-		if endTime.Weekday() != time.Sunday && endTime.Weekday() != time.Saturday {
+	if weekdays < 1 {
+		return startDate
+	}
+	weeks := (weekdays - 1) / 5 // whole weeks always hold 5 weekdays, leaving 1 to 5 to count day by day
+	endTime := startDate.AddDate(0, 0, 7*weeks)
+	for weekdays -= 5 * weeks; weekdays > 0; {
+		endTime = endTime.AddDate(0, 0, 1)
+		if isWeekday(endTime) {
 			weekdays-- // date is a weekday so add it to the total weekdays
 		}
 	}
 	return endTime
 }
 
+func isWeekday(date time.Time) bool {
+	return date.Weekday() != time.Sunday && date.Weekday() != time.Saturday
+}
+
 // Recurrence makes it so that we skip days in the calendar and may not start
 // at the beginning of the time period we're looking at, so calculate how
 // many we need to add to get to our first recurrence after the start
@@ -149,41 +259,39 @@ func getStartAdder(days, recurEvery int) int {
 	return startAdder
 }
 
-func getIncludedWeeklyDays(weeklyDaysIncluded int16) []time.Weekday {
-	var days []time.Weekday
-	if weeklyDaysIncluded&64 != 0 {
-		days = append(days, time.Sunday)
-	}
-	if weeklyDaysIncluded&32 != 0 {
-		days = append(days, time.Monday)
-	}
-	if weeklyDaysIncluded&16 != 0 {
-		days = append(days, time.Tuesday)
-	}
-	if weeklyDaysIncluded&8 != 0 {
-		days = append(days, time.Wednesday)
-	}
-	if weeklyDaysIncluded&4 != 0 {
-		days = append(days, time.Thursday)
-	}
-	if weeklyDaysIncluded&2 != 0 {
-		days = append(days, time.Friday)
+// weekdayBit returns the WeeklyDaysIncluded bit for the given day (Sunday = 64 through Saturday = 1)
+func weekdayBit(day time.Weekday) int16 {
+	return 64 >> uint(day)
+}
+
+// plainMonthlyDay returns ErrNotRepresentable when MonthlyDay counts back from the end of the month or moves to
+// the nearest weekday, for formats that have neither
+func (r *Recurrence) plainMonthlyDay() error {
+	if r.MonthlyDay != nil && *r.MonthlyDay < 1 {
+		return fmt.Errorf("%w: day of month %d counts back from the end of the month", ErrNotRepresentable, *r.MonthlyDay)
 	}
-	if weeklyDaysIncluded&1 != 0 {
-		days = append(days, time.Saturday)
+	if r.MonthlyNearestWeekday != nil && *r.MonthlyNearestWeekday {
+		return fmt.Errorf("%w: day of month moves to the nearest weekday", ErrNotRepresentable)
 	}
-	return days
+	return nil
 }
 
-func getWeeklyOccurrences(recurrenceStartDate time.Time, recurEvery int, daysIncluded []time.Weekday, recurrenceEndByDate *time.Time, timePeriodStart, timePeriodEnd time.Time) []time.Time {
+func getIncludedWeeklyDays(weeklyDaysIncluded int16) []time.Weekday {
+	return WeekdaysFromBitmask(weeklyDaysIncluded).Days()
+}
+
+func getWeeklyOccurrences(ctx context.Context, recurrenceStartDate time.Time, recurEvery int, daysIncluded []time.Weekday, recurrenceEndByDate *time.Time, timePeriodStart, timePeriodEnd time.Time) []time.Time {
 	recurrences := []time.Time{}
+	if timePeriodStart.Before(recurrenceStartDate) {
+		timePeriodStart = recurrenceStartDate // days of the first week before the start are not occurrences
+	}
 	currentDate := recurrenceStartDate
 	if currentDate.Before(timePeriodStart) {
 		currentDate = getWeeklyStartTime(recurrenceStartDate, recurEvery, timePeriodStart)
 	} else {
 		currentDate = currentDate.AddDate(0, 0, -1*int(currentDate.Weekday())) // turn into beginning of week
 	}
-	for (currentDate.Before(timePeriodEnd) || currentDate.Equal(timePeriodEnd)) && (recurrenceEndByDate == nil || currentDate.Before(*recurrenceEndByDate)) {
+	for periods := 0; (currentDate.Before(timePeriodEnd) || currentDate.Equal(timePeriodEnd)) && (recurrenceEndByDate == nil || !currentDate.After(*recurrenceEndByDate)) && !isDone(ctx, periods); periods++ {
 		recurrences = append(recurrences, getIncludedDays(daysIncluded, currentDate, timePeriodStart, timePeriodEnd)...)
 		currentDate = currentDate.AddDate(0, 0, 7*(recurEvery))
 	}
@@ -215,27 +323,40 @@ func getWeeks(fromDate, toDate time.Time) int {
 	return int(math.Floor(toDate.Sub(fromDate).Hours() / 24 / 7)) // include toDate even though it is midnight
 }
 
-func getMonthlyOccurrences(recurrenceStartDate time.Time, recurEvery int, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth *int16, recurrenceEndByDate *time.Time, timePeriodStart, timePeriodEnd time.Time) []time.Time {
+func getMonthlyOccurrences(ctx context.Context, recurrenceStartDate time.Time, recurEvery int, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth *int16, recurrenceEndByDate *time.Time, timePeriodStart, timePeriodEnd time.Time, monthlyNearestWeekday *bool) []time.Time {
 	recurrences := []time.Time{}
-	currentDate := recurrenceStartDate
+	currentDate := recurrenceStartDate.AddDate(0, 0, 1-recurrenceStartDate.Day()) // the occurrence is found from the beginning of the month
 	if currentDate.Before(timePeriodStart) {
 		currentDate = getMonthlyStartTime(recurrenceStartDate, recurEvery, timePeriodStart)
 	}
-	for (currentDate.Before(timePeriodEnd) || currentDate.Equal(timePeriodEnd)) && (recurrenceEndByDate == nil || currentDate.Before(*recurrenceEndByDate)) {
-		recurrences = append(recurrences, getMonthOccurrence(currentDate, timePeriodStart, timePeriodEnd, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth)...)
+	if timePeriodStart.Before(recurrenceStartDate) {
+		timePeriodStart = recurrenceStartDate // an occurrence earlier in the month of the start is not an occurrence
+	}
+	for periods := 0; (currentDate.Before(timePeriodEnd) || currentDate.Equal(timePeriodEnd)) && (recurrenceEndByDate == nil || !currentDate.After(*recurrenceEndByDate)) && !isDone(ctx, periods); periods++ {
+		recurrences = append(recurrences, getMonthOccurrence(currentDate, timePeriodStart, timePeriodEnd, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth, monthlyNearestWeekday)...)
 		currentDate = currentDate.AddDate(0, recurEvery, 0)
 	}
 	return recurrences
 }
 
-func getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd time.Time, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth *int16) []time.Time {
+func getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd time.Time, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth *int16, monthlyNearestWeekday *bool) []time.Time {
 	var occurrence time.Time
 	if monthlyDay != nil {
-		occurrence = time.Date(startDate.Year(), startDate.Month(), int(*monthlyDay), startDate.Hour(), startDate.Minute(), startDate.Second(), startDate.Nanosecond(), startDate.Location())
+		day := int(*monthlyDay)
+		if day < 0 { // counted back from the last day of the month
+			day += daysIn(startDate.Month(), startDate.Year()) + 1
+			if day < 1 {
+				return []time.Time{}
+			}
+		}
+		occurrence = time.Date(startDate.Year(), startDate.Month(), day, startDate.Hour(), startDate.Minute(), startDate.Second(), startDate.Nanosecond(), startDate.Location())
+		if monthlyNearestWeekday != nil && *monthlyNearestWeekday {
+			occurrence = getNearestWeekday(occurrence)
+		}
 	} else if monthlyDayOfWeek != nil && monthlyWeekOfMonth != nil {
 		weekAdder := *monthlyWeekOfMonth
 		if *monthlyWeekOfMonth == 54 { // last week of month (try 5th week, then 4th)
-			if startDate.AddDate(0, 0, int(28+*monthlyDayOfWeek)-int(startDate.Weekday())).Month() == startDate.Month() {
+			if startDate.AddDate(0, 0, 28+(int(*monthlyDayOfWeek)-int(startDate.Weekday())+7)%7).Month() == startDate.Month() {
 				weekAdder = 5
 			} else {
 				weekAdder = 4
@@ -246,12 +367,34 @@ func getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd time.Time, mon
 		}
 		occurrence = startDate.AddDate(0, 0, int(7*weekAdder+*monthlyDayOfWeek)-int(startDate.Weekday()))
 	}
+	if occurrence.Month() != startDate.Month() {
+		return []time.Time{} // the month has no such day, e.g. the 31st of April or the 5th Monday of February
+	}
 	if (occurrence.Before(timePeriodEnd) || occurrence.Equal(timePeriodEnd)) && (occurrence.After(timePeriodStart) || occurrence.Equal(timePeriodStart)) {
 		return []time.Time{occurrence}
 	}
 	return []time.Time{}
 }
 
+// getNearestWeekday moves a Saturday or Sunday to the closest weekday without leaving its month
+func getNearestWeekday(date time.Time) time.Time {
+	switch {
+	case date.Weekday() == time.Saturday && date.Day() == 1:
+		return date.AddDate(0, 0, 2)
+	case date.Weekday() == time.Saturday:
+		return date.AddDate(0, 0, -1)
+	case date.Weekday() == time.Sunday && date.Day() == daysIn(date.Month(), date.Year()):
+		return date.AddDate(0, 0, -2)
+	case date.Weekday() == time.Sunday:
+		return date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
 func getMonthlyStartTime(recurrenceStartDate time.Time, recurEvery int, timePeriodStart time.Time) time.Time {
 	monthStartDate := recurrenceStartDate.AddDate(0, 0, -1*int(recurrenceStartDate.Day()-1)) // turn into beginning of month
 	months := getMonths(monthStartDate, timePeriodStart)
@@ -270,14 +413,17 @@ func getMonths(fromDate, toDate time.Time) int {
 	return years*12 + months
 }
 
-func getYearlyOccurrences(recurrenceStartDate time.Time, recurEvery int, yearlyMonth, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth *int16, recurrenceEndByDate *time.Time, timePeriodStart, timePeriodEnd time.Time) []time.Time {
+func getYearlyOccurrences(ctx context.Context, recurrenceStartDate time.Time, recurEvery int, yearlyMonth, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth *int16, recurrenceEndByDate *time.Time, timePeriodStart, timePeriodEnd time.Time, monthlyNearestWeekday *bool) []time.Time {
 	recurrences := []time.Time{}
-	currentDate := recurrenceStartDate
+	currentDate := time.Date(recurrenceStartDate.Year(), time.Month(*yearlyMonth), 1, recurrenceStartDate.Hour(), recurrenceStartDate.Minute(), recurrenceStartDate.Second(), recurrenceStartDate.Nanosecond(), recurrenceStartDate.Location())
 	if currentDate.Before(timePeriodStart) {
 		currentDate = getYearlyStartTime(recurrenceStartDate, yearlyMonth, recurEvery, timePeriodStart)
 	}
-	for (currentDate.Before(timePeriodEnd) || currentDate.Equal(timePeriodEnd)) && (recurrenceEndByDate == nil || currentDate.Before(*recurrenceEndByDate)) {
-		recurrences = append(recurrences, getMonthOccurrence(currentDate, timePeriodStart, timePeriodEnd, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth)...)
+	if timePeriodStart.Before(recurrenceStartDate) {
+		timePeriodStart = recurrenceStartDate // an occurrence earlier in the year of the start is not an occurrence
+	}
+	for periods := 0; (currentDate.Before(timePeriodEnd) || currentDate.Equal(timePeriodEnd)) && (recurrenceEndByDate == nil || !currentDate.After(*recurrenceEndByDate)) && !isDone(ctx, periods); periods++ {
+		recurrences = append(recurrences, getMonthOccurrence(currentDate, timePeriodStart, timePeriodEnd, monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth, monthlyNearestWeekday)...)
 		currentDate = time.Date(currentDate.Year()+recurEvery, time.Month(*yearlyMonth), 1, currentDate.Hour(), currentDate.Minute(), currentDate.Second(), currentDate.Nanosecond(), currentDate.Location())
 	}
 	return recurrences
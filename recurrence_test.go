@@ -1,6 +1,8 @@
 package calendar
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 )
@@ -92,13 +94,178 @@ func TestIsValidOccurrence(t *testing.T) {
 	}
 }
 
+// TestIsValidOccurrenceMatchesGetOccurrences checks every date around the start of variedRecurrences against the
+// occurrence GetOccurrences expands for that date
+func TestIsValidOccurrenceMatchesGetOccurrences(t *testing.T) {
+	for i, r := range variedRecurrences() {
+		start := time.Date(r.StartDate.Year(), r.StartDate.Month(), r.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+		for date := start.AddDate(0, 0, -40); date.Before(start.AddDate(8, 0, 0)); date = date.AddDate(0, 0, 1) {
+			if expected, actual := isOccurrenceByExpansion(&r, date), r.IsValidOccurrenceDate(date); expected != actual {
+				t.Fatalf("recurrence %d %+v on %s: expected %t vs actual %t", i, r, date.Format("2006-01-02"), expected, actual)
+			}
+		}
+	}
+
+	if (&Recurrence{StartDate: startOf2016, RecurrencePatternCode: "D"}).IsValidOccurrenceDate(startOf2016) {
+		t.Error("expected no occurrences without an interval")
+	}
+	if (&Recurrence{StartDate: startOf2016, RecurrencePatternCode: "Y", RecurEvery: 1, MonthlyDay: int16Ptr(1)}).IsValidOccurrenceDate(startOf2016) {
+		t.Error("expected no occurrences for a yearly recurrence without a month")
+	}
+}
+
+// TestIsValidOccurrenceBoundaries pins the dates around the start and the days a month does not have, which
+// TestIsValidOccurrenceMatchesGetOccurrences only compares with the expansion
+func TestIsValidOccurrenceBoundaries(t *testing.T) {
+	weekly := Recurrence{StartDate: time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: FrequencyWeekly, RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(34)}       // a Wednesday, every other Monday and Friday
+	weeklySunday := Recurrence{StartDate: time.Date(2016, 1, 9, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: FrequencyWeekly, RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(64)} // a Saturday, every other Sunday
+	monthly31 := Recurrence{StartDate: time.Date(2016, 1, 31, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: FrequencyMonthly, RecurEvery: 1, MonthlyDay: int16Ptr(31)}
+	monthly15 := Recurrence{StartDate: time.Date(2016, 1, 20, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: FrequencyMonthly, RecurEvery: 2, MonthlyDay: int16Ptr(15)}
+	fifthMonday := Recurrence{StartDate: startOf2016, RecurrencePatternCode: FrequencyMonthly, RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(1), MonthlyWeekOfMonth: int16Ptr(5)}
+	lastThursday := Recurrence{StartDate: startOf2016, RecurrencePatternCode: FrequencyMonthly, RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54)}
+	yearlyMarch := Recurrence{StartDate: time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: FrequencyYearly, RecurEvery: 2, YearlyMonth: int16Ptr(3), MonthlyDay: int16Ptr(10)}
+	leapDay := Recurrence{StartDate: startOf2016, RecurrencePatternCode: FrequencyYearly, RecurEvery: 1, YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(29)}
+
+	tests := []struct {
+		name       string
+		recurrence Recurrence
+		date       time.Time
+		expected   bool
+	}{
+		{"weekly, start week before the start", weekly, time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), false},
+		{"weekly, start week after the start", weekly, time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC), true},
+		{"weekly, skipped week", weekly, time.Date(2016, 1, 11, 0, 0, 0, 0, time.UTC), false},
+		{"weekly, second week", weekly, time.Date(2016, 1, 18, 0, 0, 0, 0, time.UTC), true},
+		{"weekly, day not included", weekly, time.Date(2016, 1, 19, 0, 0, 0, 0, time.UTC), false},
+		{"weekly, Sunday of the start week", weeklySunday, time.Date(2016, 1, 3, 0, 0, 0, 0, time.UTC), false},
+		{"weekly, Sunday after the start is the next week", weeklySunday, time.Date(2016, 1, 10, 0, 0, 0, 0, time.UTC), false},
+		{"weekly, Sunday two weeks on", weeklySunday, time.Date(2016, 1, 17, 0, 0, 0, 0, time.UTC), true},
+		{"monthly, 31st at the start", monthly31, time.Date(2016, 1, 31, 0, 0, 0, 0, time.UTC), true},
+		{"monthly, February has no 31st", monthly31, time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC), false},
+		{"monthly, 31st after a short month", monthly31, time.Date(2016, 3, 31, 0, 0, 0, 0, time.UTC), true},
+		{"monthly, April has no 31st", monthly31, time.Date(2016, 4, 30, 0, 0, 0, 0, time.UTC), false},
+		{"monthly, start month before the start", monthly15, time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC), false},
+		{"monthly, skipped month", monthly15, time.Date(2016, 2, 15, 0, 0, 0, 0, time.UTC), false},
+		{"monthly, second month", monthly15, time.Date(2016, 3, 15, 0, 0, 0, 0, time.UTC), true},
+		{"monthly, January has no 5th Monday", fifthMonday, time.Date(2016, 1, 25, 0, 0, 0, 0, time.UTC), false},
+		{"monthly, 5th Monday of February", fifthMonday, time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC), true},
+		{"monthly, March has no 5th Monday", fifthMonday, time.Date(2016, 3, 28, 0, 0, 0, 0, time.UTC), false},
+		{"monthly, 5th Monday of May", fifthMonday, time.Date(2016, 5, 30, 0, 0, 0, 0, time.UTC), true},
+		{"monthly, last Thursday of a month starting on a Friday", lastThursday, time.Date(2016, 1, 28, 0, 0, 0, 0, time.UTC), true},
+		{"monthly, 4th Thursday that is not the last", lastThursday, time.Date(2016, 3, 24, 0, 0, 0, 0, time.UTC), false},
+		{"monthly, last Thursday of a month with five", lastThursday, time.Date(2016, 3, 31, 0, 0, 0, 0, time.UTC), true},
+		{"yearly, month before the start", yearlyMarch, time.Date(2016, 3, 10, 0, 0, 0, 0, time.UTC), false},
+		{"yearly, skipped year", yearlyMarch, time.Date(2017, 3, 10, 0, 0, 0, 0, time.UTC), false},
+		{"yearly, second year", yearlyMarch, time.Date(2018, 3, 10, 0, 0, 0, 0, time.UTC), true},
+		{"yearly, other month", yearlyMarch, time.Date(2018, 4, 10, 0, 0, 0, 0, time.UTC), false},
+		{"yearly, February 29", leapDay, time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC), true},
+		{"yearly, no February 29", leapDay, time.Date(2017, 2, 28, 0, 0, 0, 0, time.UTC), false},
+		{"yearly, March 1 after no February 29", leapDay, time.Date(2017, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"yearly, next February 29", leapDay, time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC), true},
+	}
+	for _, test := range tests {
+		if actual := test.recurrence.IsValidOccurrenceDate(test.date); actual != test.expected {
+			t.Errorf("%s: expected %t vs actual %t", test.name, test.expected, actual)
+		}
+		if actual := isOccurrenceByExpansion(&test.recurrence, test.date); actual != test.expected {
+			t.Errorf("%s: expected the expansion to give %t, got %t", test.name, test.expected, actual)
+		}
+	}
+}
+
+var startOf2016 = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// isOccurrenceByExpansion is how IsValidOccurrenceDate used to decide, which the benchmarks compare against
+func isOccurrenceByExpansion(r *Recurrence, date time.Time) bool {
+	occurrences := r.GetOccurrences(date, date)
+	return len(occurrences) == 1 && occurrences[0] == date
+}
+
+// longRunningRecurrences are checked 100 years after they start
+var longRunningRecurrences = []struct {
+	name       string
+	recurrence Recurrence
+}{
+	{"DailyWeekdays", Recurrence{StartDate: startOf2016, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)}},
+	{"DailyWeekdaysLargeInterval", Recurrence{StartDate: startOf2016, RecurrencePatternCode: "D", RecurEvery: 5000, DailyIsOnlyWeekday: boolPtr(true)}},
+	{"Weekly", Recurrence{StartDate: startOf2016, RecurrencePatternCode: "W", RecurEvery: 3, WeeklyDaysIncluded: int16Ptr(42)}},
+	{"Monthly", Recurrence{StartDate: startOf2016, RecurrencePatternCode: "M", RecurEvery: 2, MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54)}},
+	{"Yearly", Recurrence{StartDate: startOf2016, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(6), MonthlyDay: int16Ptr(15)}},
+}
+
+func BenchmarkIsValidOccurrenceDate(b *testing.B) {
+	date := startOf2016.AddDate(100, 0, 0)
+	for _, test := range longRunningRecurrences {
+		b.Run(test.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				test.recurrence.IsValidOccurrenceDate(date.AddDate(0, 0, i%7))
+			}
+		})
+	}
+}
+
+func BenchmarkIsOccurrenceByExpansion(b *testing.B) {
+	date := startOf2016.AddDate(100, 0, 0)
+	for _, test := range longRunningRecurrences {
+		b.Run(test.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				isOccurrenceByExpansion(&test.recurrence, date.AddDate(0, 0, i%7))
+			}
+		})
+	}
+}
+
+func TestGetOccurrencesEndByDate(t *testing.T) {
+	start := time.Date(2016, 1, 4, 12, 30, 0, 0, time.UTC) // a Monday
+	timePeriodStart, timePeriodEnd := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		r        Recurrence
+		expected []time.Time
+		excluded time.Time // returned before the EndByDate was included
+	}{
+		// the rest of the week of the EndByDate used to be returned
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(42), EndByDate: timePtr(time.Date(2016, 1, 11, 0, 0, 0, 0, time.UTC))}, // MWF
+			[]time.Time{time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 11, 0, 0, 0, 0, time.UTC)},
+			time.Date(2016, 1, 13, 0, 0, 0, 0, time.UTC)},
+		// daily recurrences used to ignore the EndByDate, whose time of day is not used
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 2, EndByDate: timePtr(time.Date(2016, 1, 8, 23, 0, 0, 0, time.UTC))},
+			[]time.Time{time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC)},
+			time.Date(2016, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true), EndByDate: timePtr(time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC))},
+			[]time.Time{time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 5, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC)},
+			time.Date(2016, 1, 7, 0, 0, 0, 0, time.UTC)},
+		// the occurrence of a month starting on the EndByDate used to be dropped
+		{Recurrence{StartDate: time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(1), EndByDate: timePtr(time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC))},
+			[]time.Time{time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC)},
+			time.Date(2016, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15), EndByDate: timePtr(time.Date(2016, 3, 15, 0, 0, 0, 0, time.UTC))},
+			[]time.Time{time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2016, 2, 15, 0, 0, 0, 0, time.UTC), time.Date(2016, 3, 15, 0, 0, 0, 0, time.UTC)},
+			time.Date(2016, 4, 15, 0, 0, 0, 0, time.UTC)},
+		// the occurrence later in the month of the EndByDate used to be returned
+		{Recurrence{StartDate: time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(6), MonthlyDay: int16Ptr(15), EndByDate: timePtr(time.Date(2018, 6, 10, 0, 0, 0, 0, time.UTC))},
+			[]time.Time{time.Date(2016, 6, 15, 0, 0, 0, 0, time.UTC), time.Date(2017, 6, 15, 0, 0, 0, 0, time.UTC)},
+			time.Date(2018, 6, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		compareTimes(t, test.expected, test.r.GetOccurrences(timePeriodStart, timePeriodEnd), "TestGetOccurrencesEndByDate")
+		for _, date := range test.expected {
+			if !test.r.IsValidOccurrenceDate(date) {
+				t.Errorf("expected %s to be an occurrence of %+v", date.Format("2006-01-02"), test.r)
+			}
+		}
+		if test.r.IsValidOccurrenceDate(test.excluded) {
+			t.Errorf("expected %s after the EndByDate not to be an occurrence of %+v", test.excluded.Format("2006-01-02"), test.r)
+		}
+	}
+}
+
 func TestGetDailyOccurrencesWeekdays(t *testing.T) {
 	expected := []time.Time{time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC),
 		time.Date(2016, 4, 4, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 5, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 6, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 7, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 8, 0, 0, 0, 0, time.UTC),
 		time.Date(2016, 4, 11, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 12, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 13, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 14, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 15, 0, 0, 0, 0, time.UTC),
 		time.Date(2016, 4, 18, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 19, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 20, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 21, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 22, 0, 0, 0, 0, time.UTC),
 		time.Date(2016, 4, 25, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 26, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 27, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 28, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 29, 0, 0, 0, 0, time.UTC)}
-	actual := getDailyOccurrences(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), 1, true, nil, time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 5, 1, 0, 0, 0, 0, time.UTC))
+	actual := getDailyOccurrences(context.Background(), time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), 1, true, nil, time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 5, 1, 0, 0, 0, 0, time.UTC))
 	compareTimes(t, expected, actual, "TestGetDailyOccurrencesWeekdays")
 }
 
@@ -106,7 +273,7 @@ func TestGetDailyOccurrencesAllDays(t *testing.T) {
 	expected := []time.Time{time.Date(2016, 4, 2, 0, 0, 0, 0, time.UTC),
 		time.Date(2016, 4, 5, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 8, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 11, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 14, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 17, 0, 0, 0, 0, time.UTC),
 		time.Date(2016, 4, 20, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 23, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 26, 0, 0, 0, 0, time.UTC), time.Date(2016, 4, 29, 0, 0, 0, 0, time.UTC)}
-	actual := getDailyOccurrences(time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), 3, false, nil, time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 5, 1, 0, 0, 0, 0, time.UTC))
+	actual := getDailyOccurrences(context.Background(), time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), 3, false, nil, time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 5, 1, 0, 0, 0, 0, time.UTC))
 	compareTimes(t, expected, actual, "TestGetDailyOccurrencesAllDays")
 }
 
@@ -140,6 +307,12 @@ func TestGetWeekdayStartTime(t *testing.T) {
 	if actual != time.Date(2016, 4, 5, 12, 30, 0, 0, time.UTC) {
 		t.Error("expected correct start date:", actual)
 	}
+
+	// from a Saturday: 5/16 is 55 weekdays after Monday 2/29, so every 3rd weekday falls on 5/18
+	actual = getWeekdayStartTime(time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC), recurEvery, time.Date(2016, 5, 14, 0, 0, 0, 0, time.UTC))
+	if actual != time.Date(2016, 5, 18, 0, 0, 0, 0, time.UTC) {
+		t.Error("expected correct start date from a Saturday:", actual)
+	}
 }
 
 func TestGetWeekdays(t *testing.T) {
@@ -159,6 +332,24 @@ func TestGetWeekdays(t *testing.T) {
 	}
 }
 
+func TestAddWeekdays(t *testing.T) {
+	for start := startOf2016; start.Before(startOf2016.AddDate(0, 0, 7)); start = start.AddDate(0, 0, 1) {
+		expected := start
+		for weekdays := 0; weekdays <= 30; weekdays++ {
+			if actual := addWeekdays(weekdays, start); actual != expected {
+				t.Errorf("%s plus %d weekdays: expected %s vs actual %s", start.Weekday(), weekdays, expected, actual)
+			}
+			if actual := getWeekdays(getDays(start, expected), start); actual != weekdays {
+				t.Errorf("%s to %s: expected %d weekdays vs actual %d", start, expected, weekdays, actual)
+			}
+			expected = expected.AddDate(0, 0, 1)
+			for !isWeekday(expected) {
+				expected = expected.AddDate(0, 0, 1)
+			}
+		}
+	}
+}
+
 func TestGetWeeklyOccurrences(t *testing.T) {
 	recurrenceStartDate := time.Date(2010, 1, 1, 12, 30, 0, 0, time.UTC)
 	timePeriodStart := time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC)
@@ -169,7 +360,7 @@ func TestGetWeeklyOccurrences(t *testing.T) {
 		time.Date(2016, 4, 18, 12, 30, 0, 0, time.UTC), time.Date(2016, 4, 20, 12, 30, 0, 0, time.UTC), time.Date(2016, 4, 22, 12, 30, 0, 0, time.UTC),
 		time.Date(2016, 4, 25, 12, 30, 0, 0, time.UTC), time.Date(2016, 4, 27, 12, 30, 0, 0, time.UTC), time.Date(2016, 4, 29, 12, 30, 0, 0, time.UTC)}
 	// 42 = MWF weekly meeting
-	actual := getWeeklyOccurrences(recurrenceStartDate, 1, getIncludedWeeklyDays(42), nil, timePeriodStart, timePeriodEnd)
+	actual := getWeeklyOccurrences(context.Background(), recurrenceStartDate, 1, getIncludedWeeklyDays(42), nil, timePeriodStart, timePeriodEnd)
 	compareTimes(t, expected, actual, "TestGetWeeklyOccurrences")
 }
 
@@ -181,7 +372,7 @@ func TestGetWeeklyOccurrencesEndsMidWeek(t *testing.T) {
 		time.Date(2016, 5, 8, 12, 30, 0, 0, time.UTC), time.Date(2016, 5, 15, 12, 30, 0, 0, time.UTC),
 		time.Date(2016, 5, 22, 12, 30, 0, 0, time.UTC), time.Date(2016, 5, 29, 12, 30, 0, 0, time.UTC)}
 	// 64 = SUN weekly meeting
-	actual := getWeeklyOccurrences(recurrenceStartDate, 1, getIncludedWeeklyDays(64), nil, timePeriodStart, timePeriodEnd)
+	actual := getWeeklyOccurrences(context.Background(), recurrenceStartDate, 1, getIncludedWeeklyDays(64), nil, timePeriodStart, timePeriodEnd)
 	compareTimes(t, expected, actual, "TestGetWeeklyOccurrencesEndsMidWeek")
 }
 
@@ -239,13 +430,13 @@ func TestGetMonthlyOccurrences(t *testing.T) {
 	var monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth int16
 	expected := []time.Time{time.Date(2016, 4, 15, 12, 30, 0, 0, time.UTC), time.Date(2016, 5, 15, 12, 30, 0, 0, time.UTC)}
 	monthlyDay = 15 // 15th of every month
-	actual := getMonthlyOccurrences(recurrenceStartDate, 1, &monthlyDay, nil, nil, nil, timePeriodStart, timePeriodEnd)
+	actual := getMonthlyOccurrences(context.Background(), recurrenceStartDate, 1, &monthlyDay, nil, nil, nil, timePeriodStart, timePeriodEnd, nil)
 	compareTimes(t, expected, actual, "TestGetMonthlyOccurrences, 15th of every month")
 
 	monthlyDayOfWeek = 4   // Thursday
 	monthlyWeekOfMonth = 3 // 3rd week
 	expected = []time.Time{time.Date(2016, 4, 21, 12, 30, 0, 0, time.UTC), time.Date(2016, 5, 19, 12, 30, 0, 0, time.UTC)}
-	actual = getMonthlyOccurrences(recurrenceStartDate, 1, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil, timePeriodStart, timePeriodEnd)
+	actual = getMonthlyOccurrences(context.Background(), recurrenceStartDate, 1, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil, timePeriodStart, timePeriodEnd, nil)
 	compareTimes(t, expected, actual, "TestGetMonthlyOccurrences, 3rd Thursday")
 }
 
@@ -255,53 +446,106 @@ func TestGetMonthOccurrence(t *testing.T) {
 	timePeriodEnd := time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC)
 	var monthlyDay, monthlyDayOfWeek, monthlyWeekOfMonth int16
 	monthlyDay = 15
-	date := getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd, &monthlyDay, nil, nil)
+	date := getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd, &monthlyDay, nil, nil, nil)
 	if len(date) != 1 || date[0] != time.Date(2016, 5, 15, 12, 30, 0, 0, time.UTC) {
 		t.Error("expected 5/15/2016", date)
 	}
 
 	monthlyDayOfWeek = 4   // Thursday
 	monthlyWeekOfMonth = 3 // 3rd week
-	date = getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth)
+	date = getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil)
 	if len(date) != 1 || date[0] != time.Date(2016, 5, 19, 12, 30, 0, 0, time.UTC) {
 		t.Error("expected 5/19/2016", date)
 	}
 
 	monthlyDayOfWeek = 4   // Thursday
 	monthlyWeekOfMonth = 5 // 5th week
-	date = getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth)
+	date = getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil)
 	if len(date) != 0 {
 		t.Error("expected no 5th Thursday", date)
 	}
 
 	monthlyDayOfWeek = 2    // Tuesday
 	monthlyWeekOfMonth = 54 // 54th week (last week of the month. non-intuitive, but it means grab the 5th week if it exists, otherwise use week 4)
-	date = getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth)
+	date = getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil)
 	if len(date) != 1 || date[0] != time.Date(2016, 5, 31, 12, 30, 0, 0, time.UTC) {
 		t.Error("expected last Tuesday", date)
 	}
 
 	monthlyDayOfWeek = 4    // Thursday
 	monthlyWeekOfMonth = 54 // 54th week (last week of the month. non-intuitive, but it means grab the 5th week if it exists, otherwise use week 4)
-	date = getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth)
+	date = getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil)
 	if len(date) != 1 || date[0] != time.Date(2016, 5, 26, 12, 30, 0, 0, time.UTC) {
 		t.Error("expected last Tuesday", date)
 	}
 
 	monthlyDayOfWeek = 2   // Thursday
 	monthlyWeekOfMonth = 5 // 5th week
-	date = getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth)
+	date = getMonthOccurrence(startDate, timePeriodStart, timePeriodEnd, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil)
 	if len(date) != 1 || date[0] != time.Date(2016, 5, 31, 12, 30, 0, 0, time.UTC) {
 		t.Error("expected 5/31", date)
 	}
 
+	monthlyWeekOfMonth = 54 // including months that start after the day of the week
+	for month := time.January; month <= time.December; month++ {
+		monthStart := time.Date(2019, month, 1, 0, 0, 0, 0, time.UTC)
+		for monthlyDayOfWeek = 0; monthlyDayOfWeek < 7; monthlyDayOfWeek++ {
+			last := monthStart.AddDate(0, 1, -1)
+			for last.Weekday() != time.Weekday(monthlyDayOfWeek) {
+				last = last.AddDate(0, 0, -1)
+			}
+			date = getMonthOccurrence(monthStart, monthStart, monthStart.AddDate(0, 1, 0), nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil)
+			if len(date) != 1 || date[0] != last {
+				t.Errorf("expected last %s of %s to be %s: %v", time.Weekday(monthlyDayOfWeek), month, last, date)
+			}
+		}
+	}
+
 	// no valid date.  Starts & ends on same day
-	date = getMonthOccurrence(startDate, timePeriodStart, timePeriodStart, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth)
+	date = getMonthOccurrence(startDate, timePeriodStart, timePeriodStart, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil)
 	if len(date) != 0 {
 		t.Error("expected empty list", date)
 	}
 }
 
+func TestGetMonthOccurrenceNearestWeekday(t *testing.T) {
+	tests := []struct {
+		month      time.Month
+		monthlyDay int16
+		nearest    bool
+		expected   int
+	}{
+		{5, -1, false, 31},
+		{5, -4, false, 28},
+		{2, -1, false, 29}, // leap year
+		{5, -32, false, 0},
+		{5, 14, true, 13}, // Saturday moves back to Friday
+		{5, 15, true, 16}, // Sunday moves forward to Monday
+		{5, 16, true, 16}, // weekdays stay
+		{10, 1, true, 3},  // Saturday the 1st moves forward to Monday
+		{7, -1, true, 29}, // Sunday the last day moves back to Friday
+		{4, -1, true, 29}, // Saturday the last day moves back to Friday
+		{5, 1, true, 2},   // Sunday the 1st moves forward to Monday
+	}
+	for _, test := range tests {
+		startDate := time.Date(2016, test.month, 1, 12, 30, 0, 0, time.UTC)
+		monthlyDay, nearest := test.monthlyDay, test.nearest
+		date := getMonthOccurrence(startDate, startDate.AddDate(0, -1, 0), startDate.AddDate(0, 1, 0), &monthlyDay, nil, nil, &nearest)
+		label := fmt.Sprintf("day %d of month %d nearest %v", test.monthlyDay, test.month, test.nearest)
+		if test.expected == 0 {
+			if len(date) != 0 {
+				t.Error(label, "expected no date", date)
+			}
+		} else if len(date) != 1 || date[0] != time.Date(2016, test.month, test.expected, 12, 30, 0, 0, time.UTC) {
+			t.Error(label, "expected day", test.expected, date)
+		}
+	}
+
+	r := Recurrence{StartDate: time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(-1)}
+	expected := []time.Time{time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2017, 2, 28, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, r.GetOccurrences(r.StartDate, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)), "last day of February")
+}
+
 func TestGetMonthlyStartTime(t *testing.T) {
 	recurrenceStartDate := time.Date(2010, 1, 1, 12, 30, 0, 0, time.UTC)
 	timePeriodStart := time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC)
@@ -350,26 +594,87 @@ func TestGetYearlyOccurrences(t *testing.T) {
 	expected := []time.Time{time.Date(2017, 2, 14, 0, 0, 0, 0, time.UTC), time.Date(2018, 2, 14, 0, 0, 0, 0, time.UTC)}
 	yearlyMonth = 2
 	monthlyDay = 14 // 14th of every month
-	actual := getYearlyOccurrences(recurrenceStartDate, 1, &yearlyMonth, &monthlyDay, nil, nil, nil, timePeriodStart, timePeriodEnd)
+	actual := getYearlyOccurrences(context.Background(), recurrenceStartDate, 1, &yearlyMonth, &monthlyDay, nil, nil, nil, timePeriodStart, timePeriodEnd, nil)
 	compareTimes(t, expected, actual, "TestGetYearlyOccurrences, 14th of every month")
 
 	yearlyMonth = 1
 	monthlyWeekOfMonth = 54 // last week of the month
 	monthlyDayOfWeek = 1
 	expected = []time.Time{time.Date(2017, 1, 30, 0, 0, 0, 0, time.UTC), time.Date(2018, 1, 29, 0, 0, 0, 0, time.UTC)}
-	actual = getYearlyOccurrences(recurrenceStartDate, 1, &yearlyMonth, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil, timePeriodStart, timePeriodEnd)
+	actual = getYearlyOccurrences(context.Background(), recurrenceStartDate, 1, &yearlyMonth, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil, timePeriodStart, timePeriodEnd, nil)
 	compareTimes(t, expected, actual, "TestGetYearlyOccurrences, last Monday in January")
 
 	yearlyMonth = 2
 	monthlyDayOfWeek = 4   // Thursday
 	monthlyWeekOfMonth = 3 // 3rd week
 	expected = []time.Time{time.Date(2017, 2, 16, 0, 0, 0, 0, time.UTC), time.Date(2018, 2, 15, 0, 0, 0, 0, time.UTC)}
-	actual = getYearlyOccurrences(recurrenceStartDate, 1, &yearlyMonth, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil, timePeriodStart, timePeriodEnd)
+	actual = getYearlyOccurrences(context.Background(), recurrenceStartDate, 1, &yearlyMonth, nil, &monthlyDayOfWeek, &monthlyWeekOfMonth, nil, timePeriodStart, timePeriodEnd, nil)
 	compareTimes(t, expected, actual, "TestGetYearlyOccurrences, 3rd Thursday")
 }
 
+func TestGetOccurrencesFromStartDate(t *testing.T) {
+	r := Recurrence{StartDate: time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(42)}
+	expected := []time.Time{time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, r.GetOccurrences(time.Date(2016, 1, 3, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 9, 0, 0, 0, 0, time.UTC)), "TestGetOccurrencesFromStartDate, not the Monday before")
+	if r.IsValidOccurrenceDate(time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected the Monday before the start not to be an occurrence")
+	}
+
+	r = Recurrence{StartDate: time.Date(2016, 1, 31, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(31)}
+	expected = []time.Time{time.Date(2016, 1, 31, 0, 0, 0, 0, time.UTC), time.Date(2016, 3, 31, 0, 0, 0, 0, time.UTC), time.Date(2016, 5, 31, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, r.GetOccurrences(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC)), "TestGetOccurrencesFromStartDate, only months with a 31st")
+	if r.IsValidOccurrenceDate(time.Date(2016, 3, 2, 0, 0, 0, 0, time.UTC)) || r.IsValidOccurrenceDate(time.Date(2016, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected February 31 and April 31 not to overflow into the next month")
+	}
+
+	r = Recurrence{StartDate: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyWeekOfMonth: int16Ptr(5), MonthlyDayOfWeek: int16Ptr(1)}
+	expected = []time.Time{time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2016, 5, 30, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, r.GetOccurrences(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 6, 30, 0, 0, 0, 0, time.UTC)), "TestGetOccurrencesFromStartDate, only months with a 5th Monday")
+	if r.IsValidOccurrenceDate(time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected the 5th Monday of January not to overflow into February")
+	}
+
+	r = Recurrence{StartDate: time.Date(2008, 10, 14, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 2, MonthlyWeekOfMonth: int16Ptr(2), MonthlyDayOfWeek: int16Ptr(2)}
+	expected = []time.Time{time.Date(2008, 10, 14, 0, 0, 0, 0, time.UTC), time.Date(2008, 12, 9, 0, 0, 0, 0, time.UTC), time.Date(2009, 2, 10, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, r.GetOccurrences(time.Date(2008, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2009, 3, 31, 0, 0, 0, 0, time.UTC)), "TestGetOccurrencesFromStartDate, 2nd Tuesday of the month, not after the start")
+
+	r = Recurrence{StartDate: time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(3), MonthlyDay: int16Ptr(1)}
+	expected = []time.Time{time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2017, 3, 1, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, r.GetOccurrences(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2017, 12, 31, 0, 0, 0, 0, time.UTC)), "TestGetOccurrencesFromStartDate, in the YearlyMonth")
+	if r.IsValidOccurrenceDate(time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected the start outside the YearlyMonth not to be an occurrence")
+	}
+}
+
 /*********************************************************************************************/
 
+// variedRecurrences returns recurrences of every pattern, with and without an EndByDate, starting on dates that
+// stress the month arithmetic
+func variedRecurrences() []Recurrence {
+	recurrences := []Recurrence{}
+	for _, start := range []time.Time{time.Date(2016, 1, 1, 12, 30, 0, 0, time.UTC), time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2015, 8, 31, 0, 0, 0, 0, time.UTC), time.Date(2016, 5, 14, 0, 0, 0, 0, time.UTC)} {
+		end := start.AddDate(0, 7, 10)
+		for _, recurEvery := range []int16{1, 2, 3, 7} {
+			for _, endByDate := range []*time.Time{nil, &end} {
+				recurrences = append(recurrences,
+					Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: recurEvery, EndByDate: endByDate},
+					Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: recurEvery, DailyIsOnlyWeekday: boolPtr(true)},
+					Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: recurEvery, WeeklyDaysIncluded: int16Ptr(42), EndByDate: endByDate},
+					Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: recurEvery, WeeklyDaysIncluded: int16Ptr(64 + 1)},
+					Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: recurEvery, MonthlyDay: int16Ptr(31), EndByDate: endByDate},
+					Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: recurEvery, MonthlyDay: int16Ptr(-2), MonthlyNearestWeekday: boolPtr(true)},
+					Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: recurEvery, MonthlyDayOfWeek: int16Ptr(5), MonthlyWeekOfMonth: int16Ptr(54), EndByDate: endByDate},
+					Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: recurEvery, MonthlyDayOfWeek: int16Ptr(1), MonthlyWeekOfMonth: int16Ptr(5)},
+					Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: recurEvery, YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(29), EndByDate: endByDate},
+					Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: recurEvery, YearlyMonth: int16Ptr(int16(start.Month())), MonthlyDay: int16Ptr(int16(start.Day()))},
+					Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: recurEvery, YearlyMonth: int16Ptr(12), MonthlyDayOfWeek: int16Ptr(0), MonthlyWeekOfMonth: int16Ptr(5)})
+			}
+		}
+	}
+	return recurrences
+}
+
 func compareTimes(t *testing.T, expected []time.Time, actual []time.Time, label string) {
 	if len(expected) != len(actual) {
 		t.Log("expected:", expected)
@@ -396,3 +701,78 @@ func compareWeekdays(t *testing.T, expected []time.Weekday, actual []time.Weekda
 		}
 	}
 }
+
+// compareOccurrenceCount expands a recurrence that ends, e.g. after a number of occurrences
+func compareOccurrenceCount(t *testing.T, expected int, actual *Recurrence, label string) {
+	if count := len(actual.GetOccurrences(actual.startDate(), actual.startDate().AddDate(50, 0, 0))); count != expected {
+		t.Errorf("%s: expected %d occurrences, got %d", label, expected, count)
+	}
+}
+
+func compareRecurrences(t *testing.T, expected, actual *Recurrence, label string) {
+	if !expected.StartDate.Equal(actual.StartDate) || expected.StartDate.Location().String() != actual.StartDate.Location().String() {
+		t.Errorf("%s: expected StartDate %v vs actual %v", label, expected.StartDate, actual.StartDate)
+	}
+	if expected.RecurrencePatternCode != actual.RecurrencePatternCode || expected.RecurEvery != actual.RecurEvery {
+		t.Errorf("%s: expected %s every %d vs actual %s every %d", label, expected.RecurrencePatternCode, expected.RecurEvery, actual.RecurrencePatternCode, actual.RecurEvery)
+	}
+	compareInt16s(t, expected.YearlyMonth, actual.YearlyMonth, label+" YearlyMonth")
+	compareInt16s(t, expected.MonthlyWeekOfMonth, actual.MonthlyWeekOfMonth, label+" MonthlyWeekOfMonth")
+	compareInt16s(t, expected.MonthlyDayOfWeek, actual.MonthlyDayOfWeek, label+" MonthlyDayOfWeek")
+	compareInt16s(t, expected.MonthlyDay, actual.MonthlyDay, label+" MonthlyDay")
+	if (expected.MonthlyNearestWeekday == nil) != (actual.MonthlyNearestWeekday == nil) || expected.MonthlyNearestWeekday != nil && *expected.MonthlyNearestWeekday != *actual.MonthlyNearestWeekday {
+		t.Errorf("%s: expected MonthlyNearestWeekday %v vs actual %v", label, expected.MonthlyNearestWeekday, actual.MonthlyNearestWeekday)
+	}
+	compareInt16s(t, expected.WeeklyDaysIncluded, actual.WeeklyDaysIncluded, label+" WeeklyDaysIncluded")
+	compareInt16s(t, expected.NumberOfOccurrences, actual.NumberOfOccurrences, label+" NumberOfOccurrences")
+	if (expected.DailyIsOnlyWeekday == nil) != (actual.DailyIsOnlyWeekday == nil) || expected.DailyIsOnlyWeekday != nil && *expected.DailyIsOnlyWeekday != *actual.DailyIsOnlyWeekday {
+		t.Errorf("%s: expected DailyIsOnlyWeekday %v vs actual %v", label, expected.DailyIsOnlyWeekday, actual.DailyIsOnlyWeekday)
+	}
+	if (expected.EndByDate == nil) != (actual.EndByDate == nil) || expected.EndByDate != nil && !expected.EndByDate.Equal(*actual.EndByDate) {
+		t.Errorf("%s: expected EndByDate %v vs actual %v", label, expected.EndByDate, actual.EndByDate)
+	}
+}
+
+func compareSeries(t *testing.T, expected, actual *Series, label string) {
+	compareRecurrences(t, &expected.Recurrence, &actual.Recurrence, label)
+	if expected.AllDay != actual.AllDay || expected.Duration != actual.Duration {
+		t.Errorf("%s: expected all day %v for %v vs actual all day %v for %v", label, expected.AllDay, expected.Duration, actual.AllDay, actual.Duration)
+	}
+	if len(expected.ExceptionDates) != len(actual.ExceptionDates) {
+		t.Errorf("%s: expected ExceptionDates %v vs actual %v", label, expected.ExceptionDates, actual.ExceptionDates)
+		return
+	}
+	for i, exceptionDate := range actual.ExceptionDates {
+		if !exceptionDate.Equal(expected.ExceptionDates[i]) || exceptionDate.Location().String() != expected.ExceptionDates[i].Location().String() {
+			t.Errorf("%s: expected ExceptionDates[%d] %v vs actual %v", label, i, expected.ExceptionDates[i], exceptionDate)
+		}
+	}
+}
+
+func compareInt16s(t *testing.T, expected, actual *int16, label string) {
+	if expected == nil && actual == nil {
+		return
+	}
+	if expected == nil || actual == nil || *expected != *actual {
+		t.Errorf("%s: expected %v vs actual %v", label, formatInt16(expected), formatInt16(actual))
+	}
+}
+
+func formatInt16(value *int16) string {
+	if value == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*value)
+}
+
+func int16Ptr(value int16) *int16 {
+	return &value
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func timePtr(value time.Time) *time.Time {
+	return &value
+}
@@ -0,0 +1,66 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// Instance is one occurrence of a Series, identified by its RECURRENCE-ID
+type Instance struct {
+	RecurrenceID string    // RFC 5545 RECURRENCE-ID content line, e.g. RECURRENCE-ID;TZID=America/New_York:20160104T093000
+	Start        time.Time // original start of the occurrence, on the time of day and in the time zone of the StartDate
+	End          time.Time // Start plus the Duration of the series
+}
+
+// Instances returns the occurrences of the series within the time period with their RECURRENCE-IDs
+func (s *Series) Instances(timePeriodStart, timePeriodEnd time.Time) []Instance {
+	occurrences := s.GetOccurrences(timePeriodStart, timePeriodEnd)
+	instances := make([]Instance, len(occurrences))
+	for i, occurrence := range occurrences {
+		instances[i] = s.instance(occurrence)
+	}
+	return instances
+}
+
+// RecurrenceID returns the RFC 5545 RECURRENCE-ID content line of the occurrence on occurrenceDate. It depends only
+// on the date and on the time of day, time zone and AllDay of the series, so it is the same each time the series is
+// expanded, and together with the UID of the series identifies the occurrence in iCalendar
+func (s *Series) RecurrenceID(occurrenceDate time.Time) string {
+	property := newICalTimeProperty("RECURRENCE-ID", s.AllDay, s.occurrenceStart(occurrenceDate))
+	return property.contentLine()
+}
+
+// InstanceOf returns the occurrence a RECURRENCE-ID from RecurrenceID or Instances identifies, or an error when it
+// is not an occurrence of the series, e.g. because the series has changed or the occurrence is an ExceptionDate
+func (s *Series) InstanceOf(recurrenceID string) (Instance, error) {
+	property, err := parseICSProperty(recurrenceID)
+	if err != nil {
+		return Instance{}, fmt.Errorf("invalid RECURRENCE-ID %q: %w", recurrenceID, err)
+	}
+	if property.name != "RECURRENCE-ID" || len(property.values) != 1 {
+		return Instance{}, fmt.Errorf("invalid RECURRENCE-ID %q", recurrenceID)
+	}
+	times, isDate, err := property.times(s.StartDate.Location())
+	if err != nil {
+		return Instance{}, err
+	}
+	if isDate != s.AllDay {
+		return Instance{}, fmt.Errorf("RECURRENCE-ID %q is not an occurrence of the series: expected AllDay %t", recurrenceID, s.AllDay)
+	}
+	date := times[0]
+	if !isDate {
+		date = date.In(s.StartDate.Location())
+		if !s.occurrenceStart(date).Equal(times[0]) {
+			return Instance{}, fmt.Errorf("RECURRENCE-ID %q is not an occurrence of the series: expected it at %s", recurrenceID, s.StartDate.Format("15:04:05"))
+		}
+	}
+	if !s.IsValidOccurrenceDate(date) {
+		return Instance{}, fmt.Errorf("RECURRENCE-ID %q is not an occurrence of the series", recurrenceID)
+	}
+	return s.instance(date), nil
+}
+
+func (s *Series) instance(occurrenceDate time.Time) Instance {
+	start := s.occurrenceStart(occurrenceDate)
+	return Instance{RecurrenceID: s.RecurrenceID(occurrenceDate), Start: start, End: start.Add(s.Duration)}
+}
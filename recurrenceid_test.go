@@ -0,0 +1,57 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecurrenceID(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2016, 1, 1, 9, 30, 0, 0, newYork)
+	s := Series{Recurrence: Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(42)}, Duration: time.Hour,
+		ExceptionDates: []time.Time{time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC)}}
+	instances := s.Instances(time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC))
+	if len(instances) != 2 {
+		t.Fatal("expected Monday and Friday without the exception", instances)
+	}
+	expected := Instance{RecurrenceID: "RECURRENCE-ID;TZID=America/New_York:20160104T093000", Start: time.Date(2016, 1, 4, 9, 30, 0, 0, newYork), End: time.Date(2016, 1, 4, 10, 30, 0, 0, newYork)}
+	if instances[0] != expected {
+		t.Error("unexpected instance", instances[0])
+	}
+	for _, instance := range instances {
+		if actual, err := s.InstanceOf(instance.RecurrenceID); err != nil || actual != instance {
+			t.Error("expected the instance back", instance, actual, err)
+		}
+	}
+	if actual, err := s.InstanceOf("RECURRENCE-ID:20160104T143000Z"); err != nil || !actual.Start.Equal(expected.Start) {
+		t.Error("expected the same instant in UTC to identify the occurrence", actual, err)
+	}
+
+	for _, id := range []string{
+		"RECURRENCE-ID;TZID=America/New_York:20160106T093000", // exception
+		"RECURRENCE-ID;TZID=America/New_York:20160105T093000", // Tuesday
+		"RECURRENCE-ID;TZID=America/New_York:20160104T100000", // wrong time of day
+		"RECURRENCE-ID;VALUE=DATE:20160104",                   // all day
+		"DTSTART;TZID=America/New_York:20160104T093000",
+		"RECURRENCE-ID;TZID=America/New_York:20160104T093000,20160108T093000",
+		"RECURRENCE-ID:2016",
+		"not a content line",
+	} {
+		if actual, err := s.InstanceOf(id); err == nil {
+			t.Error("expected an error for", id, actual)
+		}
+	}
+
+	s = Series{Recurrence: Recurrence{StartDate: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15)},
+		AllDay: true, Duration: 24 * time.Hour}
+	id := s.RecurrenceID(time.Date(2016, 2, 15, 0, 0, 0, 0, time.UTC))
+	if id != "RECURRENCE-ID;VALUE=DATE:20160215" {
+		t.Error("unexpected all day RECURRENCE-ID", id)
+	}
+	if actual, err := s.InstanceOf(id); err != nil || actual.Start != time.Date(2016, 2, 15, 0, 0, 0, 0, time.UTC) {
+		t.Error("expected the all day instance back", actual, err)
+	}
+}
@@ -0,0 +1,432 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rrule holds the RFC 5545 RECUR rule parts that a Recurrence can express
+type rrule struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	untilIsSet bool
+	untilDate  bool // UNTIL is a DATE rather than a DATE-TIME
+	byDay      []rruleDay
+	byMonthDay []int
+	byMonth    []int
+	wkst       time.Weekday
+	wkstIsSet  bool
+}
+
+// rruleDay is a BYDAY entry such as MO, 2TU or -1FR. An nth of 0 means every such day
+type rruleDay struct {
+	nth int
+	day time.Weekday
+}
+
+const (
+	icalDateLayout     = "20060102"
+	icalDateTimeLayout = "20060102T150405"
+)
+
+var rruleDayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// RRule returns the recurrence as an RFC 5545 RRULE value such as FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE,FR. UNTIL is
+// written as a DATE when StartDate is midnight, otherwise as the end of the EndByDate day converted to UTC
+func (r *Recurrence) RRule() (string, error) {
+	rule, err := r.rrule(r.StartDate.Hour() == 0 && r.StartDate.Minute() == 0 && r.StartDate.Second() == 0)
+	if err != nil {
+		return "", err
+	}
+	return rule.String(), nil
+}
+
+// ParseRRule parses an RFC 5545 RRULE value, with or without the RRULE: prefix, that starts at start (DTSTART).
+// A COUNT also sets the EndByDate to the last occurrence. Rules using parts the Recurrence struct cannot express,
+// such as BYSETPOS, BYHOUR or a yearly BYDAY without a BYMONTH, return ErrNotRepresentable
+func ParseRRule(value string, start time.Time) (*Recurrence, error) {
+	rule, err := parseRRule(strings.TrimPrefix(value, "RRULE:"))
+	if err != nil {
+		return nil, err
+	}
+	return rule.recurrence(start)
+}
+
+// rrule converts the recurrence to rule parts. untilDate selects whether UNTIL is a DATE, which RFC 5545 requires
+// to match whether DTSTART is a DATE
+func (r *Recurrence) rrule(untilDate bool) (*rrule, error) {
+	if r.RecurEvery < 1 {
+		return nil, fmt.Errorf("%w: rrule interval must be at least 1, got %d", ErrNotRepresentable, r.RecurEvery)
+	}
+	rule := &rrule{interval: int(r.RecurEvery)}
+	switch r.RecurrencePatternCode {
+	case FrequencyDaily:
+		rule.freq = "DAILY"
+		if r.DailyIsOnlyWeekday != nil && *r.DailyIsOnlyWeekday {
+			// BYDAY only filters the days FREQ=DAILY produces, so every N weekdays has no equivalent
+			if r.RecurEvery != 1 {
+				return nil, fmt.Errorf("%w: rrule cannot recur every %d weekdays", ErrNotRepresentable, r.RecurEvery)
+			}
+			rule.byDay = rruleDays(32 + 16 + 8 + 4 + 2)
+		}
+	case FrequencyWeekly:
+		weeklyDaysIncluded := r.Weekdays().Bitmask()
+		rule.freq = "WEEKLY"
+		rule.byDay = rruleDays(weeklyDaysIncluded)
+		if r.RecurEvery > 1 {
+			rule.wkst, rule.wkstIsSet = time.Sunday, true // weeks are counted from Sunday rather than the RFC 5545 default of Monday
+		}
+	case FrequencyMonthly, FrequencyYearly:
+		rule.freq = "MONTHLY"
+		if r.RecurrencePatternCode == FrequencyYearly {
+			if r.YearlyMonth == nil {
+				return nil, fmt.Errorf("%w: yearly recurrence has no YearlyMonth", ErrNotRepresentable)
+			}
+			rule.freq = "YEARLY"
+			rule.byMonth = []int{int(*r.YearlyMonth)}
+		}
+		switch {
+		case r.MonthlyDay != nil:
+			if r.MonthlyNearestWeekday != nil && *r.MonthlyNearestWeekday {
+				return nil, fmt.Errorf("%w: rrule cannot move the day of month to the nearest weekday", ErrNotRepresentable)
+			}
+			rule.byMonthDay = []int{int(*r.MonthlyDay)}
+		case r.MonthlyDayOfWeek != nil && r.MonthlyWeekOfMonth != nil:
+			nth := int(*r.MonthlyWeekOfMonth)
+			if nth == 54 {
+				nth = -1
+			}
+			rule.byDay = []rruleDay{{nth: nth, day: time.Weekday(*r.MonthlyDayOfWeek)}}
+		default:
+			return nil, fmt.Errorf("%w: recurrence has neither MonthlyDay nor MonthlyDayOfWeek and MonthlyWeekOfMonth", ErrNotRepresentable)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown recurrence pattern code %q", ErrNotRepresentable, r.RecurrencePatternCode)
+	}
+	switch {
+	case r.NumberOfOccurrences != nil:
+		rule.count = int(*r.NumberOfOccurrences)
+	case r.EndByDate != nil:
+		rule.untilIsSet, rule.untilDate = true, untilDate
+		if untilDate {
+			rule.until = time.Date(r.EndByDate.Year(), r.EndByDate.Month(), r.EndByDate.Day(), 0, 0, 0, 0, time.UTC)
+		} else {
+			rule.until = time.Date(r.EndByDate.Year(), r.EndByDate.Month(), r.EndByDate.Day(), 23, 59, 59, 0, r.StartDate.Location()).UTC()
+		}
+	}
+	return rule, nil
+}
+
+func (rule *rrule) recurrence(start time.Time) (*Recurrence, error) {
+	if rule.interval > 32767 {
+		return nil, fmt.Errorf("%w: rrule INTERVAL %d is out of range", ErrNotRepresentable, rule.interval)
+	}
+	r := &Recurrence{StartDate: start, RecurEvery: int16(rule.interval)}
+	if r.RecurEvery == 0 {
+		r.RecurEvery = 1
+	}
+	switch rule.freq {
+	case "DAILY":
+		r.RecurrencePatternCode = FrequencyDaily
+		if len(rule.byMonthDay) > 0 || len(rule.byMonth) > 0 {
+			return nil, fmt.Errorf("%w: daily rrule cannot use BYMONTHDAY or BYMONTH", ErrNotRepresentable)
+		}
+		if len(rule.byDay) > 0 {
+			if days, err := rruleWeeklyDaysIncluded(rule.byDay); err != nil || days != 32+16+8+4+2 || r.RecurEvery != 1 {
+				return nil, fmt.Errorf("%w: daily rrule BYDAY must be every weekday", ErrNotRepresentable)
+			}
+			dailyIsOnlyWeekday := true
+			r.DailyIsOnlyWeekday = &dailyIsOnlyWeekday
+		}
+	case "WEEKLY":
+		r.RecurrencePatternCode = FrequencyWeekly
+		if len(rule.byMonthDay) > 0 || len(rule.byMonth) > 0 {
+			return nil, fmt.Errorf("%w: weekly rrule cannot use BYMONTHDAY or BYMONTH", ErrNotRepresentable)
+		}
+		days := weekdayBit(start.Weekday())
+		if len(rule.byDay) > 0 {
+			var err error
+			if days, err = rruleWeeklyDaysIncluded(rule.byDay); err != nil {
+				return nil, err
+			}
+		}
+		wkst := time.Monday
+		if rule.wkstIsSet {
+			wkst = rule.wkst
+		}
+		if r.RecurEvery > 1 && !sundayWeeksMatch(days, wkst) {
+			return nil, fmt.Errorf("%w: weeks starting on %s group these days differently than weeks starting on Sunday", ErrNotRepresentable, wkst)
+		}
+		r.WeeklyDaysIncluded = &days
+	case "MONTHLY", "YEARLY":
+		r.RecurrencePatternCode = FrequencyMonthly
+		if rule.freq == "YEARLY" {
+			month := int16(start.Month())
+			switch {
+			case len(rule.byMonth) == 1:
+				month = int16(rule.byMonth[0])
+			case len(rule.byMonth) > 1:
+				return nil, fmt.Errorf("%w: yearly rrule must have a single BYMONTH", ErrNotRepresentable)
+			case len(rule.byDay) > 0 || len(rule.byMonthDay) > 0:
+				// without BYMONTH, an nth BYDAY counts the days of the year and BYMONTHDAY is in every month
+				return nil, fmt.Errorf("%w: yearly rrule with BYDAY or BYMONTHDAY must have a single BYMONTH", ErrNotRepresentable)
+			}
+			r.RecurrencePatternCode = FrequencyYearly
+			r.YearlyMonth = &month
+		} else if len(rule.byMonth) > 0 {
+			return nil, fmt.Errorf("%w: monthly rrule cannot use BYMONTH", ErrNotRepresentable)
+		}
+		switch {
+		case len(rule.byMonthDay) == 0 && len(rule.byDay) == 0:
+			day := int16(start.Day())
+			r.MonthlyDay = &day
+		case len(rule.byMonthDay) == 1 && len(rule.byDay) == 0:
+			day := int16(rule.byMonthDay[0])
+			r.MonthlyDay = &day
+		case len(rule.byMonthDay) == 0 && len(rule.byDay) == 1:
+			nth := rule.byDay[0].nth
+			switch {
+			case nth == -1:
+				nth = 54
+			case nth < 1 || nth > 5:
+				return nil, fmt.Errorf("%w: rrule BYDAY must be the 1st to 5th or last day of the month, got %d", ErrNotRepresentable, nth)
+			}
+			dayOfWeek, weekOfMonth := int16(rule.byDay[0].day), int16(nth)
+			r.MonthlyDayOfWeek = &dayOfWeek
+			r.MonthlyWeekOfMonth = &weekOfMonth
+		default:
+			return nil, fmt.Errorf("%w: rrule must have a single BYMONTHDAY or BYDAY", ErrNotRepresentable)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported rrule FREQ %q", ErrNotRepresentable, rule.freq)
+	}
+	if rule.count > 0 {
+		if rule.count > 32767 {
+			return nil, fmt.Errorf("%w: rrule COUNT %d is out of range", ErrNotRepresentable, rule.count)
+		}
+		if err := r.SetNumberOfOccurrences(rule.count); err != nil {
+			return nil, fmt.Errorf("invalid rrule COUNT: %w", err)
+		}
+	}
+	if rule.untilIsSet {
+		until := rule.until
+		if !rule.untilDate {
+			until = until.In(start.Location())
+		}
+		endByDate := time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, start.Location())
+		r.EndByDate = &endByDate
+	}
+	return r, nil
+}
+
+// String formats the rule parts in the order RFC 5545 examples use
+func (rule *rrule) String() string {
+	parts := []string{"FREQ=" + rule.freq}
+	if rule.interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(rule.interval))
+	}
+	if rule.count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(rule.count))
+	}
+	if rule.untilIsSet {
+		if rule.untilDate {
+			parts = append(parts, "UNTIL="+rule.until.Format(icalDateLayout))
+		} else {
+			parts = append(parts, "UNTIL="+rule.until.UTC().Format(icalDateTimeLayout)+"Z")
+		}
+	}
+	if len(rule.byMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(rule.byMonth))
+	}
+	if len(rule.byMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(rule.byMonthDay))
+	}
+	if len(rule.byDay) > 0 {
+		days := make([]string, len(rule.byDay))
+		for i, day := range rule.byDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if rule.wkstIsSet {
+		parts = append(parts, "WKST="+rruleDayNames[rule.wkst])
+	}
+	return strings.Join(parts, ";")
+}
+
+func (day rruleDay) String() string {
+	if day.nth == 0 {
+		return rruleDayNames[day.day]
+	}
+	return strconv.Itoa(day.nth) + rruleDayNames[day.day]
+}
+
+func parseRRule(value string) (*rrule, error) {
+	rule := &rrule{}
+	for _, part := range strings.Split(value, ";") {
+		name, partValue, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rrule part %q", part)
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.freq = strings.ToUpper(partValue)
+		case "INTERVAL":
+			if rule.interval, err = strconv.Atoi(partValue); err == nil && rule.interval < 1 {
+				err = fmt.Errorf("must be at least 1")
+			}
+		case "COUNT":
+			if rule.count, err = strconv.Atoi(partValue); err == nil && rule.count < 1 {
+				err = fmt.Errorf("must be at least 1")
+			}
+		case "UNTIL":
+			rule.until, rule.untilDate, err = parseICalDateTime(partValue, time.UTC)
+			rule.untilIsSet = true
+		case "BYDAY":
+			rule.byDay, err = parseRRuleDays(partValue)
+		case "BYMONTHDAY":
+			rule.byMonthDay, err = parseInts(partValue, -31, 31)
+		case "BYMONTH":
+			rule.byMonth, err = parseInts(partValue, 1, 12)
+		case "WKST":
+			var day rruleDay
+			if day, err = parseRRuleDay(partValue); err == nil && day.nth != 0 {
+				err = fmt.Errorf("must not have an ordinal")
+			}
+			rule.wkst, rule.wkstIsSet = day.day, true
+		default:
+			return nil, fmt.Errorf("%w: unsupported rrule part %s", ErrNotRepresentable, name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid rrule %s %q: %w", name, partValue, err)
+		}
+	}
+	if rule.freq == "" {
+		return nil, fmt.Errorf("rrule %q has no FREQ", value)
+	}
+	if rule.count > 0 && rule.untilIsSet {
+		return nil, fmt.Errorf("rrule %q has both COUNT and UNTIL", value)
+	}
+	return rule, nil
+}
+
+func parseRRuleDays(value string) ([]rruleDay, error) {
+	var days []rruleDay
+	for _, item := range strings.Split(value, ",") {
+		day, err := parseRRuleDay(item)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func parseRRuleDay(value string) (rruleDay, error) {
+	value = strings.ToUpper(value)
+	if len(value) < 2 {
+		return rruleDay{}, fmt.Errorf("invalid day %q", value)
+	}
+	for day, name := range rruleDayNames {
+		if strings.HasSuffix(value, name) {
+			prefix := strings.TrimSuffix(value, name)
+			if prefix == "" {
+				return rruleDay{day: time.Weekday(day)}, nil
+			}
+			nth, err := strconv.Atoi(prefix)
+			if err != nil || nth == 0 || nth < -53 || nth > 53 {
+				return rruleDay{}, fmt.Errorf("invalid day %q", value)
+			}
+			return rruleDay{nth: nth, day: time.Weekday(day)}, nil
+		}
+	}
+	return rruleDay{}, fmt.Errorf("invalid day %q", value)
+}
+
+func rruleDays(weeklyDaysIncluded int16) []rruleDay {
+	var days []rruleDay
+	for _, day := range getIncludedWeeklyDays(weeklyDaysIncluded) {
+		days = append(days, rruleDay{day: day})
+	}
+	return days
+}
+
+func rruleWeeklyDaysIncluded(days []rruleDay) (int16, error) {
+	var weeklyDaysIncluded int16
+	for _, day := range days {
+		if day.nth != 0 {
+			return 0, fmt.Errorf("%w: rrule BYDAY %s has an ordinal", ErrNotRepresentable, day)
+		}
+		weeklyDaysIncluded |= weekdayBit(day.day)
+	}
+	return weeklyDaysIncluded, nil
+}
+
+// parseICalDateTime parses an RFC 5545 DATE or DATE-TIME value. Floating DATE-TIMEs and DATEs are placed in loc
+func parseICalDateTime(value string, loc *time.Location) (t time.Time, isDate bool, err error) {
+	switch {
+	case len(value) == len(icalDateLayout):
+		t, err = time.ParseInLocation(icalDateLayout, value, loc)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse(icalDateTimeLayout, strings.TrimSuffix(value, "Z"))
+		return t, false, err
+	default:
+		t, err = time.ParseInLocation(icalDateTimeLayout, value, loc)
+		return t, false, err
+	}
+}
+
+// parseContentLine splits an RFC 5545 content line such as EXDATE;TZID=America/New_York:20160104T123000 into its
+// upper case name, parameters and value
+func parseContentLine(line string) (name string, params map[string]string, value string, err error) {
+	quoted := false
+	for i, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ':' && !quoted:
+			params = map[string]string{}
+			parts := strings.Split(line[:i], ";")
+			for _, param := range parts[1:] {
+				paramName, paramValue, ok := strings.Cut(param, "=")
+				if !ok {
+					return "", nil, "", fmt.Errorf("invalid content line parameter %q", param)
+				}
+				params[strings.ToUpper(paramName)] = strings.Trim(paramValue, `"`)
+			}
+			return strings.ToUpper(parts[0]), params, line[i+1:], nil
+		}
+	}
+	return "", nil, "", fmt.Errorf("invalid content line %q", line)
+}
+
+func parseInts(value string, min, max int) ([]int, error) {
+	var ints []int
+	for _, item := range strings.Split(value, ",") {
+		i, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, err
+		}
+		if i < min || i > max || i == 0 {
+			return nil, fmt.Errorf("%d is out of range", i)
+		}
+		ints = append(ints, i)
+	}
+	sort.Ints(ints)
+	return ints, nil
+}
+
+func joinInts(ints []int) string {
+	items := make([]string, len(ints))
+	for i, value := range ints {
+		items[i] = strconv.Itoa(value)
+	}
+	return strings.Join(items, ",")
+}
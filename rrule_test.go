@@ -0,0 +1,131 @@
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRRule(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	startTime := time.Date(2016, 1, 1, 12, 30, 0, 0, time.UTC)
+	rules := []struct {
+		recurrence Recurrence
+		rule       string
+	}{
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1}, "FREQ=DAILY"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 3, NumberOfOccurrences: int16Ptr(10),
+			EndByDate: timePtr(time.Date(2016, 1, 28, 0, 0, 0, 0, time.UTC))}, "FREQ=DAILY;INTERVAL=3;COUNT=10"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)}, "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(64)}, "FREQ=WEEKLY;BYDAY=SU"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(32 + 8 + 2),
+			EndByDate: timePtr(time.Date(2016, 6, 30, 0, 0, 0, 0, time.UTC))}, "FREQ=WEEKLY;INTERVAL=2;UNTIL=20160630;BYDAY=MO,WE,FR;WKST=SU"},
+		{Recurrence{StartDate: startTime, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(2),
+			EndByDate: timePtr(time.Date(2016, 6, 30, 0, 0, 0, 0, time.UTC))}, "FREQ=WEEKLY;UNTIL=20160630T235959Z;BYDAY=FR"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15)}, "FREQ=MONTHLY;BYMONTHDAY=15"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-3)}, "FREQ=MONTHLY;BYMONTHDAY=-3"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 2, MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(4)}, "FREQ=MONTHLY;INTERVAL=2;BYDAY=4TH"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(2), MonthlyWeekOfMonth: int16Ptr(5)}, "FREQ=MONTHLY;BYDAY=5TU"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(14)}, "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=14"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 4, YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54)}, "FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=-1TH"},
+	}
+	for _, rule := range rules {
+		actual, err := rule.recurrence.RRule()
+		if err != nil {
+			t.Error(rule.rule, err)
+		} else if actual != rule.rule {
+			t.Errorf("expected %s vs actual %s", rule.rule, actual)
+		}
+
+		parsed, err := ParseRRule("RRULE:"+rule.rule, rule.recurrence.StartDate)
+		if err != nil {
+			t.Error(rule.rule, err)
+			continue
+		}
+		compareRecurrences(t, &rule.recurrence, parsed, rule.rule)
+		if rule.recurrence.NumberOfOccurrences != nil {
+			compareOccurrenceCount(t, int(*rule.recurrence.NumberOfOccurrences), parsed, rule.rule)
+		}
+	}
+}
+
+func TestParseRRuleDefaults(t *testing.T) {
+	start := time.Date(2016, 3, 9, 0, 0, 0, 0, time.UTC) // Wednesday
+	r, err := ParseRRule("FREQ=WEEKLY", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareInt16s(t, int16Ptr(8), r.WeeklyDaysIncluded, "weekly defaults to the DTSTART day")
+
+	r, err = ParseRRule("FREQ=MONTHLY;INTERVAL=3", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareInt16s(t, int16Ptr(9), r.MonthlyDay, "monthly defaults to the DTSTART day of the month")
+
+	r, err = ParseRRule("freq=yearly", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareInt16s(t, int16Ptr(3), r.YearlyMonth, "yearly defaults to the DTSTART month")
+	compareInt16s(t, int16Ptr(9), r.MonthlyDay, "yearly defaults to the DTSTART day of the month")
+
+	// Monday and Wednesday fall in the same week whether weeks start on Sunday or the default of Monday
+	if _, err = ParseRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", start); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseRRuleErrors(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	unrepresentable := []string{
+		"FREQ=HOURLY",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;INTERVAL=2;BYDAY=MO,TU,WE,TH,FR",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYMONTHDAY=1,15",
+		"FREQ=MONTHLY;BYDAY=-2FR",
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		"FREQ=YEARLY;BYMONTH=1,7",
+		"FREQ=YEARLY;BYDAY=1MO",
+		"FREQ=YEARLY;BYDAY=-1FR",
+		"FREQ=YEARLY;BYMONTHDAY=15",
+	}
+	for _, rule := range unrepresentable {
+		if _, err := ParseRRule(rule, start); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be unrepresentable: %v", rule, err)
+		}
+	}
+
+	invalid := []string{"", "INTERVAL=2", "FREQ=DAILY;INTERVAL=0", "FREQ=DAILY;COUNT=2;UNTIL=20160101", "FREQ=DAILY;UNTIL=2016", "FREQ=WEEKLY;BYDAY=XX", "FREQ"}
+	for _, rule := range invalid {
+		if _, err := ParseRRule(rule, start); err == nil || errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be invalid: %v", rule, err)
+		}
+	}
+
+	r := Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 2, DailyIsOnlyWeekday: boolPtr(true)}
+	if _, err := r.RRule(); !errors.Is(err, ErrNotRepresentable) {
+		t.Error("expected every other weekday to be unrepresentable", err)
+	}
+	r = Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15), MonthlyNearestWeekday: boolPtr(true)}
+	if _, err := r.RRule(); !errors.Is(err, ErrNotRepresentable) {
+		t.Error("expected nearest weekday to be unrepresentable", err)
+	}
+}
+
+func TestParseContentLine(t *testing.T) {
+	name, params, value, err := parseContentLine(`exdate;TZID="America/New_York";VALUE=DATE-TIME:20160104T123000,20160105T123000`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "EXDATE" || params["TZID"] != "America/New_York" || params["VALUE"] != "DATE-TIME" || value != "20160104T123000,20160105T123000" {
+		t.Error("expected content line parts", name, params, value)
+	}
+
+	if _, _, _, err := parseContentLine("EXDATE"); err == nil {
+		t.Error("expected error for content line without a value")
+	}
+}
@@ -0,0 +1,96 @@
+package calendar
+
+import (
+	"slices"
+	"time"
+)
+
+// Schedule is a set of occurrence dates that can be expanded and tested, such as a *Recurrence, a *Series or a
+// CombinedSchedule of them
+type Schedule interface {
+	Expander
+	IsValidOccurrenceDate(occurrenceDate time.Time) bool
+}
+
+type scheduleOperation int
+
+const (
+	scheduleUnion scheduleOperation = iota
+	scheduleIntersection
+	scheduleDifference
+)
+
+// CombinedSchedule is the union, intersection or difference of schedules, returned by Union, Intersect and
+// Difference. It holds the schedules rather than their occurrences, which are only found when it is expanded or
+// tested, so it reflects later changes to them
+type CombinedSchedule struct {
+	operation scheduleOperation
+	schedules []Schedule
+}
+
+// Union returns the schedule of the dates of any of the schedules, e.g. the days with cleaning or an inspection
+func Union(schedules ...Schedule) *CombinedSchedule {
+	return &CombinedSchedule{operation: scheduleUnion, schedules: schedules}
+}
+
+// Intersect returns the schedule of the dates of all of the schedules, e.g. the days with both cleaning and an
+// inspection. The intersection of no schedules has no dates
+func Intersect(schedules ...Schedule) *CombinedSchedule {
+	return &CombinedSchedule{operation: scheduleIntersection, schedules: schedules}
+}
+
+// Difference returns the schedule of the dates of schedule that are not dates of any of the excluded schedules,
+// e.g. the training days that are not maintenance days
+func Difference(schedule Schedule, excluded ...Schedule) *CombinedSchedule {
+	return &CombinedSchedule{operation: scheduleDifference, schedules: append([]Schedule{schedule}, excluded...)}
+}
+
+// GetOccurrences returns the dates of the schedule within the time period. A union expands each of its schedules,
+// while an intersection or difference expands only the first and tests its dates against the others
+func (c *CombinedSchedule) GetOccurrences(timePeriodStart, timePeriodEnd time.Time) []time.Time {
+	if len(c.schedules) == 0 {
+		return []time.Time{}
+	}
+	if c.operation == scheduleUnion {
+		occurrences := []time.Time{}
+		for _, schedule := range c.schedules {
+			occurrences = append(occurrences, schedule.GetOccurrences(timePeriodStart, timePeriodEnd)...)
+		}
+		slices.SortFunc(occurrences, time.Time.Compare)
+		return slices.CompactFunc(occurrences, time.Time.Equal)
+	}
+	occurrences := c.schedules[0].GetOccurrences(timePeriodStart, timePeriodEnd)
+	included := occurrences[:0]
+	for _, occurrence := range occurrences {
+		if c.isRestOccurrenceDate(occurrence) {
+			included = append(included, occurrence)
+		}
+	}
+	return included
+}
+
+// IsValidOccurrenceDate returns whether the date is a date of the schedule
+func (c *CombinedSchedule) IsValidOccurrenceDate(occurrenceDate time.Time) bool {
+	if len(c.schedules) == 0 {
+		return false
+	}
+	if c.operation == scheduleUnion {
+		for _, schedule := range c.schedules {
+			if schedule.IsValidOccurrenceDate(occurrenceDate) {
+				return true
+			}
+		}
+		return false
+	}
+	return c.schedules[0].IsValidOccurrenceDate(occurrenceDate) && c.isRestOccurrenceDate(occurrenceDate)
+}
+
+// isRestOccurrenceDate tests a date of the first schedule of an intersection or difference against the others
+func (c *CombinedSchedule) isRestOccurrenceDate(occurrenceDate time.Time) bool {
+	for _, schedule := range c.schedules[1:] {
+		if schedule.IsValidOccurrenceDate(occurrenceDate) != (c.operation == scheduleIntersection) {
+			return false
+		}
+	}
+	return true
+}
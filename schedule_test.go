@@ -0,0 +1,61 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	start := time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC) // a Monday
+
+	cleaning := &Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(42)} // MWF
+	inspection := &Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 4}
+	timePeriodEnd := time.Date(2016, 1, 17, 0, 0, 0, 0, time.UTC)
+
+	expected := []time.Time{time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 11, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 12, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 13, 0, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 16, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, Union(cleaning, inspection).GetOccurrences(start, timePeriodEnd), "TestSchedule, union")
+	expected = []time.Time{time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, Intersect(cleaning, inspection).GetOccurrences(start, timePeriodEnd), "TestSchedule, intersection")
+	expected = []time.Time{time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 11, 0, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 13, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, Difference(cleaning, inspection).GetOccurrences(start, timePeriodEnd), "TestSchedule, difference")
+
+	// schedules are evaluated when used
+	intersection := Intersect(cleaning, inspection)
+	inspection.RecurEvery = 2
+	if !intersection.IsValidOccurrenceDate(time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected the intersection to follow the changed inspection schedule")
+	}
+	if len(Intersect().GetOccurrences(start, timePeriodEnd)) != 0 || Union().IsValidOccurrenceDate(start) {
+		t.Error("expected no dates without schedules")
+	}
+
+	recurrences := variedRecurrences()
+	for i := range recurrences {
+		a, b, c := &recurrences[i], &recurrences[(i*7+3)%len(recurrences)], &Series{Recurrence: recurrences[(i*13+5)%len(recurrences)]}
+		if occurrence, ok := c.OccurrenceAt(1); ok {
+			c.ExceptionDates = []time.Time{occurrence}
+		}
+		schedules := []Schedule{Union(a, b, c), Intersect(a, b), Difference(a, b, c), Difference(Union(a, c), Intersect(a, b))}
+		expected := [][]bool{}
+		timePeriodStart, timePeriodEnd := a.startDate(), a.startDate().AddDate(1, 0, 0)
+		for date := timePeriodStart; !date.After(timePeriodEnd); date = date.AddDate(0, 0, 1) {
+			inA, inB, inC := a.IsValidOccurrenceDate(date), b.IsValidOccurrenceDate(date), c.IsValidOccurrenceDate(date)
+			expected = append(expected, []bool{inA || inB || inC, inA && inB, inA && !inB && !inC, (inA || inC) && !(inA && inB)})
+		}
+		for j, schedule := range schedules {
+			var dates []time.Time
+			for day, date := 0, timePeriodStart; !date.After(timePeriodEnd); day, date = day+1, date.AddDate(0, 0, 1) {
+				if schedule.IsValidOccurrenceDate(date) != expected[day][j] {
+					t.Fatalf("recurrences %d: schedule %d: unexpected membership of %s", i, j, date)
+				}
+				if expected[day][j] {
+					dates = append(dates, date)
+				}
+			}
+			compareTimes(t, dates, schedule.GetOccurrences(timePeriodStart, timePeriodEnd), "TestSchedule, varied")
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package calendar
+
+import "time"
+
+// Series is a recurring event: the Recurrence that generates it plus the length of each occurrence and the
+// occurrences that have been removed from it
+type Series struct {
+	Recurrence
+	AllDay         bool          // occurrences are whole days rather than starting at the StartDate time of day
+	Duration       time.Duration // length of each occurrence
+	ExceptionDates []time.Time   // dates of occurrences removed from the series (EXDATE). Time and time zone are NOT used
+}
+
+// GetOccurrences returns the dates of the recurrence within the time period, without the ExceptionDates
+func (s *Series) GetOccurrences(timePeriodStart, timePeriodEnd time.Time) []time.Time {
+	return s.withoutExceptionDates(s.Recurrence.GetOccurrences(timePeriodStart, timePeriodEnd))
+}
+
+// withoutExceptionDates removes the ExceptionDates from occurrences in place
+func (s *Series) withoutExceptionDates(occurrences []time.Time) []time.Time {
+	if len(s.ExceptionDates) == 0 {
+		return occurrences
+	}
+	included := occurrences[:0]
+	for _, occurrence := range occurrences {
+		if !s.isExceptionDate(occurrence) {
+			included = append(included, occurrence)
+		}
+	}
+	return included
+}
+
+// IsValidOccurrenceDate returns whether the recurrence has an occurrence on the date that is not an ExceptionDate
+func (s *Series) IsValidOccurrenceDate(occurrenceDate time.Time) bool {
+	return s.Recurrence.IsValidOccurrenceDate(occurrenceDate) && !s.isExceptionDate(occurrenceDate)
+}
+
+func (s *Series) isExceptionDate(date time.Time) bool {
+	for _, exceptionDate := range s.ExceptionDates {
+		if exceptionDate.Year() == date.Year() && exceptionDate.Month() == date.Month() && exceptionDate.Day() == date.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// occurrenceStart combines an occurrence date with the time of day the series starts at
+func (s *Series) occurrenceStart(occurrenceDate time.Time) time.Time {
+	return time.Date(occurrenceDate.Year(), occurrenceDate.Month(), occurrenceDate.Day(), s.StartDate.Hour(), s.StartDate.Minute(), s.StartDate.Second(), 0, s.StartDate.Location())
+}
@@ -0,0 +1,29 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeriesGetOccurrences(t *testing.T) {
+	s := Series{
+		Recurrence:     Recurrence{StartDate: time.Date(2016, 1, 4, 9, 30, 0, 0, time.UTC), RecurrencePatternCode: "D", RecurEvery: 1},
+		ExceptionDates: []time.Time{time.Date(2016, 1, 5, 9, 30, 0, 0, time.UTC), time.Date(2016, 1, 7, 0, 0, 0, 0, time.UTC)},
+	}
+	expected := []time.Time{time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, s.GetOccurrences(time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC)), "TestSeriesGetOccurrences")
+
+	if s.IsValidOccurrenceDate(time.Date(2016, 1, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected exception date to not be a valid occurrence")
+	}
+	if !s.IsValidOccurrenceDate(time.Date(2016, 1, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected to be a valid occurrence")
+	}
+}
+
+func TestSeriesOccurrenceStart(t *testing.T) {
+	s := Series{Recurrence: Recurrence{StartDate: time.Date(2016, 1, 4, 9, 30, 0, 0, time.UTC)}}
+	if actual := s.occurrenceStart(time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC)); actual != time.Date(2016, 2, 1, 9, 30, 0, 0, time.UTC) {
+		t.Error("expected the series time of day", actual)
+	}
+}
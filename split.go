@@ -0,0 +1,72 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// Truncate returns a copy of the series ending the day before at, e.g. to end it where a "this and all following"
+// edit starts. The copy has the ExceptionDates before at and, when the series ends after a NumberOfOccurrences, the
+// number of its occurrences before at. It is an error when the series has no occurrences before at
+func (s *Series) Truncate(at time.Time) (*Series, error) {
+	at = time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	count := s.CountUntil(at.AddDate(0, 0, -1))
+	if count == 0 {
+		return nil, fmt.Errorf("series has no occurrences before %s", at.Format("2006-01-02"))
+	}
+	head := *s
+	endByDate := time.Date(at.Year(), at.Month(), at.Day()-1, 0, 0, 0, 0, s.StartDate.Location())
+	if s.EndByDate == nil || s.EndByDate.After(endByDate) {
+		head.EndByDate = &endByDate
+	}
+	if s.NumberOfOccurrences != nil && int(*s.NumberOfOccurrences) > count {
+		head.NumberOfOccurrences = int16Pointer(count)
+	}
+	head.ExceptionDates = s.exceptionDatesBetween(time.Time{}, at)
+	return &head, nil
+}
+
+// Split splits the series at a date into the series truncated before it and a new series of the occurrences from
+// it, which together have exactly the occurrences of the series. The new series starts on the first occurrence on or
+// after at, at the time of day of the series, so that a weekly series every few weeks keeps its weeks, and ends by
+// the same EndByDate or after the rest of the NumberOfOccurrences. It is an error when either part has no occurrences
+func (s *Series) Split(at time.Time) (*Series, *Series, error) {
+	head, err := s.Truncate(at)
+	if err != nil {
+		return nil, nil, err
+	}
+	at = time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	remaining := 0
+	if s.NumberOfOccurrences != nil {
+		if remaining = int(*s.NumberOfOccurrences) - s.CountUntil(at.AddDate(0, 0, -1)); remaining < 1 {
+			return nil, nil, fmt.Errorf("series has no occurrences from %s", at.Format("2006-01-02"))
+		}
+	}
+	var first time.Time
+	for occurrence := range s.occurrencesFrom(at) {
+		first = occurrence
+		break
+	}
+	if first.IsZero() {
+		return nil, nil, fmt.Errorf("series has no occurrences from %s", at.Format("2006-01-02"))
+	}
+	tail := *s
+	tail.StartDate = s.occurrenceStart(first)
+	if s.NumberOfOccurrences != nil {
+		tail.NumberOfOccurrences = int16Pointer(remaining)
+	}
+	tail.ExceptionDates = s.exceptionDatesBetween(at, time.Time{})
+	return head, &tail, nil
+}
+
+// exceptionDatesBetween returns the ExceptionDates on or after from and before to, where a zero time is unbounded
+func (s *Series) exceptionDatesBetween(from, to time.Time) []time.Time {
+	var exceptionDates []time.Time
+	for _, exceptionDate := range s.ExceptionDates {
+		date := time.Date(exceptionDate.Year(), exceptionDate.Month(), exceptionDate.Day(), 0, 0, 0, 0, time.UTC)
+		if (from.IsZero() || !date.Before(from)) && (to.IsZero() || date.Before(to)) {
+			exceptionDates = append(exceptionDates, exceptionDate)
+		}
+	}
+	return exceptionDates
+}
@@ -0,0 +1,74 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplit(t *testing.T) {
+	start := time.Date(2016, 1, 1, 9, 30, 0, 0, time.UTC)
+	s := Series{Recurrence: Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(42)}, // MWF every 2 weeks
+		Duration: time.Hour, ExceptionDates: []time.Time{time.Date(2016, 1, 11, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 27, 0, 0, 0, 0, time.UTC)}}
+	if err := s.SetNumberOfOccurrences(8); err != nil {
+		t.Fatal(err)
+	}
+	head, tail, err := s.Split(time.Date(2016, 1, 18, 14, 0, 0, 0, time.UTC)) // a Monday in a week without occurrences
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *head.EndByDate != time.Date(2016, 1, 17, 0, 0, 0, 0, time.UTC) || *head.NumberOfOccurrences != 4 || len(head.ExceptionDates) != 1 {
+		t.Errorf("unexpected head %+v", head)
+	}
+	if tail.StartDate != time.Date(2016, 1, 25, 9, 30, 0, 0, time.UTC) || *tail.NumberOfOccurrences != 4 || *tail.EndByDate != *s.EndByDate || len(tail.ExceptionDates) != 1 {
+		t.Errorf("unexpected tail %+v", tail)
+	}
+	expected := []time.Time{time.Date(2016, 1, 25, 0, 0, 0, 0, time.UTC), time.Date(2016, 1, 29, 0, 0, 0, 0, time.UTC), time.Date(2016, 2, 8, 0, 0, 0, 0, time.UTC)}
+	compareTimes(t, expected, tail.GetOccurrences(start, start.AddDate(1, 0, 0)), "TestSplit, tail")
+
+	for _, at := range []time.Time{start, time.Date(2016, 2, 11, 0, 0, 0, 0, time.UTC)} {
+		if _, _, err := s.Split(at); err == nil {
+			t.Error("expected an error splitting at", at)
+		}
+	}
+	if _, err := s.Truncate(start); err == nil {
+		t.Error("expected an error truncating at the start")
+	}
+	if truncated, err := s.Truncate(time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)); err != nil || *truncated.EndByDate != *s.EndByDate || *truncated.NumberOfOccurrences != 8 {
+		t.Error("expected truncating after the end to keep the end", truncated, err)
+	}
+}
+
+func TestSplitVariedRecurrences(t *testing.T) {
+	for i, r := range variedRecurrences() {
+		s := Series{Recurrence: r}
+		if r.EndByDate == nil {
+			if err := s.SetNumberOfOccurrences(12); err != nil {
+				continue // no occurrences
+			}
+		}
+		end := s.endDate().AddDate(1, 0, 0)
+		for n := 1; ; n += 3 {
+			at, ok := s.OccurrenceAt(n)
+			if !ok {
+				break
+			}
+			for _, at := range []time.Time{at, at.AddDate(0, 0, -1)} {
+				head, tail, err := s.Split(at)
+				if err != nil {
+					if s.Count(s.startDate(), at.AddDate(0, 0, -1)) > 0 && s.Count(at, end) > 0 {
+						t.Fatalf("recurrence %d %+v: split at %s: %s", i, r, at, err)
+					}
+					continue
+				}
+				headOccurrences, tailOccurrences := head.GetOccurrences(s.startDate(), end), tail.GetOccurrences(s.startDate(), end)
+				if len(headOccurrences) > 0 && !headOccurrences[len(headOccurrences)-1].Before(at) || len(tailOccurrences) > 0 && tailOccurrences[0].Before(at) {
+					t.Fatalf("recurrence %d %+v: split at %s: expected the head before and the tail from it", i, r, at)
+				}
+				compareTimes(t, s.GetOccurrences(s.startDate(), end), append(headOccurrences, tailOccurrences...), "TestSplitVariedRecurrences")
+				if s.NumberOfOccurrences != nil && int(*head.NumberOfOccurrences+*tail.NumberOfOccurrences) != int(*s.NumberOfOccurrences) {
+					t.Fatalf("recurrence %d %+v: split at %s: expected the NumberOfOccurrences to add up", i, r, at)
+				}
+			}
+		}
+	}
+}
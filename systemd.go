@@ -0,0 +1,578 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// onCalendarDayNames are the systemd names of the days of the week, which start on Monday
+var onCalendarDayNames = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+// onCalendarShorthands are the special expressions understood by systemd
+var onCalendarShorthands = map[string]string{
+	"minutely": "*-*-* *:*:00", "hourly": "*-*-* *:00:00", "daily": "*-*-* 00:00:00", "monthly": "*-*-01 00:00:00",
+	"weekly": "Mon *-*-* 00:00:00", "yearly": "*-01-01 00:00:00", "annually": "*-01-01 00:00:00",
+	"quarterly": "*-01,04,07,10-01 00:00:00", "semiannually": "*-01,07-01 00:00:00",
+}
+
+// onCalendar is a parsed systemd calendar event. A nil component matches every value
+type onCalendar struct {
+	weekdays                               int // bit 0 is Monday. 0 matches every day
+	year, month, day, hour, minute, second []onCalendarChain
+	endOfMonth                             bool // day counts back from the end of the month (~)
+	location                               string
+}
+
+// onCalendarChain is one comma separated item of a component: start, start..stop or either with a /repeat
+type onCalendarChain struct {
+	start, stop, repeat int // stop is -1 and repeat 0 when not given
+}
+
+// ParseOnCalendar converts a systemd.time(7) calendar event, such as the OnCalendar= setting of a timer, to the
+// recurrences that fire at the same times, starting on the date of start. A trailing time zone replaces the time zone
+// of start. Components are AND'd as in systemd, so days of the week combined with days 1..07, 08..14, 15..21,
+// 22..28, 29..31 or ~07/1 become the nth day of the week. Other combinations that no Recurrence can describe,
+// including specific years, return ErrNotRepresentable
+func ParseOnCalendar(expr string, start time.Time) ([]Recurrence, error) {
+	c, err := parseOnCalendar(expr)
+	if err != nil {
+		return nil, err
+	}
+	if c.location != "" {
+		loc, _ := time.LoadLocation(c.location) // already validated
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	}
+	var yearStep int
+	if c.year != nil {
+		if len(c.year) != 1 || c.year[0].stop >= 0 || c.year[0].repeat == 0 {
+			return nil, fmt.Errorf("%w: calendar event runs in some years", ErrNotRepresentable)
+		}
+		yearStep = c.year[0].repeat
+		first := c.year[0].start
+		switch {
+		case start.Year() < first:
+			start = time.Date(first, 1, 1, 0, 0, 0, 0, start.Location())
+		case (start.Year()-first)%yearStep != 0:
+			start = time.Date(start.Year()+yearStep-(start.Year()-first)%yearStep, 1, 1, 0, 0, 0, 0, start.Location())
+		}
+	}
+
+	months := onCalendarValues(c.month, 1, 12)
+	days := onCalendarValues(c.day, 1, 31)
+	if c.endOfMonth {
+		days = onCalendarEndOfMonthValues(c.day)
+	}
+	allDays := len(days) == 31 && !c.endOfMonth
+	var dates []Recurrence
+	switch {
+	case c.weekdays == 0 && allDays:
+		if len(months) != 12 {
+			return nil, fmt.Errorf("%w: calendar event runs every day of some months", ErrNotRepresentable)
+		}
+		dates = append(dates, Recurrence{StartDate: start, RecurrencePatternCode: FrequencyDaily, RecurEvery: 1})
+	case c.weekdays == 0:
+		selectors := cronMonthlyDays(days)
+		if c.endOfMonth {
+			for _, selector := range selectors {
+				*selector.MonthlyDay = -*selector.MonthlyDay
+			}
+		}
+		if dates, err = cronMonthlyDates(selectors, months, start); err != nil {
+			return nil, err
+		}
+	case allDays:
+		if len(months) != 12 {
+			return nil, fmt.Errorf("%w: calendar event runs on days of the week in some months", ErrNotRepresentable)
+		}
+		var weeklyDaysIncluded int16
+		for _, weekday := range c.weekdayValues() {
+			weeklyDaysIncluded |= weekdayBit(weekday)
+		}
+		dates = append(dates, Recurrence{StartDate: start, RecurrencePatternCode: FrequencyWeekly, RecurEvery: 1, WeeklyDaysIncluded: &weeklyDaysIncluded})
+	default:
+		weekOfMonth := onCalendarWeekOfMonth(days, c.endOfMonth)
+		if weekOfMonth == 0 {
+			return nil, fmt.Errorf("%w: calendar event runs on days of the week that are also given days of the month", ErrNotRepresentable)
+		}
+		var selectors []Recurrence
+		for _, weekday := range c.weekdayValues() {
+			dayOfWeek := int16(weekday)
+			selectors = append(selectors, Recurrence{MonthlyDayOfWeek: &dayOfWeek, MonthlyWeekOfMonth: &weekOfMonth})
+		}
+		if dates, err = cronMonthlyDates(selectors, months, start); err != nil {
+			return nil, err
+		}
+	}
+	if yearStep > 0 {
+		for i := range dates {
+			if dates[i].RecurrencePatternCode != FrequencyYearly {
+				return nil, fmt.Errorf("%w: calendar event runs every %d years on more than one month", ErrNotRepresentable, yearStep)
+			}
+			dates[i].RecurEvery = int16(yearStep)
+		}
+	}
+	return cronTimes(dates, onCalendarValues(c.hour, 0, 23), onCalendarValues(c.minute, 0, 59), onCalendarValues(c.second, 0, 59)), nil
+}
+
+// NormalizeOnCalendar returns a systemd.time(7) calendar event in the normalized form printed by
+// systemd-analyze calendar, e.g. "Mon..Fri *-*-01..07 09:00:00" for "mon..fri *-*-1..7 9:00"
+func NormalizeOnCalendar(expr string) (string, error) {
+	c, err := parseOnCalendar(expr)
+	if err != nil {
+		return "", err
+	}
+	return c.String(), nil
+}
+
+// OnCalendar returns the recurrence as a normalized systemd.time(7) calendar event firing at the StartDate time of
+// day, followed by the StartDate time zone unless it is time.Local. Calendar events have no end and no interval for
+// days, weeks or months that do not repeat evenly each year, so recurrences using those return ErrNotRepresentable
+func (r *Recurrence) OnCalendar() (string, error) {
+	if r.EndByDate != nil || r.NumberOfOccurrences != nil {
+		return "", fmt.Errorf("%w: calendar events cannot end", ErrNotRepresentable)
+	}
+	if r.StartDate.Nanosecond() != 0 {
+		return "", fmt.Errorf("%w: calendar event formatting has no fractions of a second", ErrNotRepresentable)
+	}
+	if r.RecurEvery < 1 {
+		return "", fmt.Errorf("%w: calendar event interval must be at least 1, got %d", ErrNotRepresentable, r.RecurEvery)
+	}
+	c := onCalendar{
+		hour:   []onCalendarChain{{r.StartDate.Hour(), -1, 0}},
+		minute: []onCalendarChain{{r.StartDate.Minute(), -1, 0}},
+		second: []onCalendarChain{{r.StartDate.Second(), -1, 0}},
+	}
+	if r.StartDate.Location() != time.Local {
+		c.location = r.StartDate.Location().String()
+	}
+	switch r.RecurrencePatternCode {
+	case FrequencyDaily:
+		if r.RecurEvery != 1 {
+			return "", fmt.Errorf("%w: calendar events cannot run every %d days", ErrNotRepresentable, r.RecurEvery)
+		}
+		if r.DailyIsOnlyWeekday != nil && *r.DailyIsOnlyWeekday {
+			c.weekdays = 31 // Monday to Friday
+		}
+	case FrequencyWeekly:
+		if r.RecurEvery != 1 {
+			return "", fmt.Errorf("%w: calendar events cannot run every %d weeks", ErrNotRepresentable, r.RecurEvery)
+		}
+		if weekdays := r.Weekdays(); weekdays != AllWeekdays {
+			for weekday := range weekdays.Iter() {
+				c.weekdays |= onCalendarWeekdayBit(weekday)
+			}
+		}
+	case FrequencyMonthly, FrequencyYearly:
+		if err := c.setMonthDay(r); err != nil {
+			return "", err
+		}
+		if r.RecurrencePatternCode == FrequencyYearly {
+			if r.YearlyMonth == nil {
+				return "", fmt.Errorf("%w: yearly recurrence has no YearlyMonth", ErrNotRepresentable)
+			}
+			c.month = []onCalendarChain{{int(*r.YearlyMonth), -1, 0}}
+			if r.RecurEvery > 1 {
+				c.year = []onCalendarChain{{r.StartDate.Year(), -1, int(r.RecurEvery)}}
+			}
+		} else if r.RecurEvery > 1 {
+			step := int(r.RecurEvery)
+			if 12%step != 0 {
+				return "", fmt.Errorf("%w: calendar events cannot run every %d months", ErrNotRepresentable, r.RecurEvery)
+			}
+			c.month = []onCalendarChain{{(int(r.StartDate.Month())-1)%step + 1, -1, step}}
+		}
+	default:
+		return "", fmt.Errorf("%w: unknown recurrence pattern code %q", ErrNotRepresentable, r.RecurrencePatternCode)
+	}
+	return c.String(), nil
+}
+
+// setMonthDay sets the day components for the day of the month or nth day of the week of a monthly or yearly recurrence
+func (c *onCalendar) setMonthDay(r *Recurrence) error {
+	switch {
+	case r.MonthlyDay != nil:
+		if r.MonthlyNearestWeekday != nil && *r.MonthlyNearestWeekday {
+			return fmt.Errorf("%w: calendar events cannot move the day of month to the nearest weekday", ErrNotRepresentable)
+		}
+		day := int(*r.MonthlyDay)
+		if day < 0 {
+			day, c.endOfMonth = -day, true
+		}
+		c.day = []onCalendarChain{{day, -1, 0}}
+	case r.MonthlyDayOfWeek != nil && r.MonthlyWeekOfMonth != nil:
+		c.weekdays = onCalendarWeekdayBit(time.Weekday(*r.MonthlyDayOfWeek))
+		switch week := int(*r.MonthlyWeekOfMonth); {
+		case week >= 1 && week <= 4:
+			c.day = []onCalendarChain{{7*week - 6, 7 * week, 0}}
+		case week == 5:
+			c.day = []onCalendarChain{{29, 31, 0}}
+		case week == 54:
+			c.day, c.endOfMonth = []onCalendarChain{{7, -1, 1}}, true
+		default:
+			return fmt.Errorf("%w: week of month %d", ErrNotRepresentable, week)
+		}
+	default:
+		return fmt.Errorf("%w: monthly recurrence has neither MonthlyDay nor MonthlyDayOfWeek and MonthlyWeekOfMonth", ErrNotRepresentable)
+	}
+	return nil
+}
+
+// String returns the calendar event in systemd's normalized form
+func (c *onCalendar) String() string {
+	var b strings.Builder
+	if c.weekdays != 0 {
+		b.WriteString(formatOnCalendarWeekdays(c.weekdays))
+		b.WriteByte(' ')
+	}
+	daySeparator := "-"
+	if c.endOfMonth {
+		daySeparator = "~"
+	}
+	b.WriteString(formatOnCalendarChains(c.year, "%04d") + "-" + formatOnCalendarChains(c.month, "%02d") + daySeparator + formatOnCalendarChains(c.day, "%02d"))
+	b.WriteString(" " + formatOnCalendarChains(c.hour, "%02d") + ":" + formatOnCalendarChains(c.minute, "%02d") + ":" + formatOnCalendarChains(c.second, "%02d"))
+	if c.location != "" {
+		b.WriteString(" " + c.location)
+	}
+	return b.String()
+}
+
+// weekdayValues returns the days of the week of the weekdays bits
+func (c *onCalendar) weekdayValues() []time.Weekday {
+	var weekdays []time.Weekday
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if c.weekdays&onCalendarWeekdayBit(weekday) != 0 {
+			weekdays = append(weekdays, weekday)
+		}
+	}
+	return weekdays
+}
+
+func onCalendarWeekdayBit(weekday time.Weekday) int {
+	return 1 << ((int(weekday) + 6) % 7)
+}
+
+// onCalendarWeekOfMonth returns the MonthlyWeekOfMonth of days that are a whole week of the month, or 0
+func onCalendarWeekOfMonth(days []int, endOfMonth bool) int16 {
+	if endOfMonth {
+		if len(days) == 7 && days[0] == 1 && days[6] == 7 {
+			return 54
+		}
+		return 0
+	}
+	if len(days) == 3 && days[0] == 29 && days[2] == 31 {
+		return 5
+	}
+	if len(days) == 7 && days[6] == days[0]+6 && days[0]%7 == 1 && days[0] < 29 {
+		return int16(days[0]/7 + 1)
+	}
+	return 0
+}
+
+// onCalendarValues returns the sorted values matched by chains, or every value from min to max for nil
+func onCalendarValues(chains []onCalendarChain, min, max int) []int {
+	included := make([]bool, max+1)
+	if chains == nil {
+		for value := min; value <= max; value++ {
+			included[value] = true
+		}
+	}
+	for _, chain := range chains {
+		last := chain.start
+		if chain.stop >= 0 {
+			last = chain.stop
+		} else if chain.repeat > 0 {
+			last = max
+		}
+		step := chain.repeat
+		if step == 0 {
+			step = 1
+		}
+		for value := chain.start; value <= last && value <= max; value += step {
+			included[value] = true
+		}
+	}
+	var values []int
+	for value := min; value <= max; value++ {
+		if included[value] {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// onCalendarEndOfMonthValues returns the sorted days back from the end of the month matched by chains. A repeat
+// steps towards the end of the month, so ~07/1 is each of the last 7 days
+func onCalendarEndOfMonthValues(chains []onCalendarChain) []int {
+	included := make([]bool, 32)
+	for _, chain := range chains {
+		included[chain.start] = true
+		for value := chain.start - chain.repeat; chain.repeat > 0 && value >= 1; value -= chain.repeat {
+			included[value] = true
+		}
+	}
+	var values []int
+	for value := 1; value <= 31; value++ {
+		if included[value] {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func parseOnCalendar(expr string) (*onCalendar, error) {
+	fields := strings.Fields(expr)
+	c := &onCalendar{}
+	if len(fields) > 1 && strings.ContainsAny(fields[len(fields)-1], "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz") {
+		location := fields[len(fields)-1]
+		if _, err := time.LoadLocation(location); err != nil {
+			return nil, fmt.Errorf("invalid calendar event time zone %q: %w", location, err)
+		}
+		c.location, fields = location, fields[:len(fields)-1]
+	}
+	if len(fields) == 1 {
+		if shorthand, ok := onCalendarShorthands[strings.ToLower(fields[0])]; ok {
+			fields = strings.Fields(shorthand)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("calendar event %q is empty", expr)
+	}
+
+	if first := fields[0][0]; first >= 'A' && first <= 'Z' || first >= 'a' && first <= 'z' {
+		weekdays, err := parseOnCalendarWeekdays(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar event day of week %q: %w", fields[0], err)
+		}
+		c.weekdays, fields = weekdays, fields[1:]
+	}
+	clock := "00:00:00"
+	switch {
+	case len(fields) == 2:
+		if err := c.parseDate(fields[0]); err != nil {
+			return nil, err
+		}
+		clock = fields[1]
+	case len(fields) == 1 && strings.Contains(fields[0], ":"):
+		clock = fields[0]
+	case len(fields) == 1:
+		if err := c.parseDate(fields[0]); err != nil {
+			return nil, err
+		}
+	case len(fields) > 2:
+		return nil, fmt.Errorf("calendar event %q has too many fields", expr)
+	}
+	if err := c.parseTime(clock); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseDate parses [year-]month-day, where ~ in place of the last - counts days back from the end of the month
+func (c *onCalendar) parseDate(date string) error {
+	separator := strings.LastIndexAny(date, "-~")
+	if separator < 0 {
+		return fmt.Errorf("invalid calendar event date %q", date)
+	}
+	c.endOfMonth = date[separator] == '~'
+	parts := append(strings.Split(date[:separator], "-"), date[separator+1:])
+	if len(parts) > 3 {
+		return fmt.Errorf("invalid calendar event date %q", date)
+	}
+	var err error
+	if len(parts) == 3 {
+		if c.year, err = parseOnCalendarChains(parts[0], 1970, 2199); err != nil {
+			return fmt.Errorf("invalid calendar event year %q: %w", parts[0], err)
+		}
+		parts = parts[1:]
+	}
+	if c.month, err = parseOnCalendarChains(parts[0], 1, 12); err != nil {
+		return fmt.Errorf("invalid calendar event month %q: %w", parts[0], err)
+	}
+	if c.day, err = parseOnCalendarChains(parts[1], 1, 31); err != nil {
+		return fmt.Errorf("invalid calendar event day %q: %w", parts[1], err)
+	}
+	if c.endOfMonth {
+		for _, chain := range c.day {
+			if chain.stop >= 0 {
+				return fmt.Errorf("invalid calendar event day %q: ranges cannot count back from the end of the month", parts[1])
+			}
+		}
+	}
+	return nil
+}
+
+// parseTime parses hour:minute[:second]
+func (c *onCalendar) parseTime(value string) error {
+	parts := strings.Split(value, ":")
+	if len(parts) == 2 {
+		parts = append(parts, "00")
+	}
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid calendar event time %q", value)
+	}
+	if strings.Contains(parts[2], ".") {
+		return fmt.Errorf("invalid calendar event time %q: fractions of a second are not supported", value)
+	}
+	var err error
+	if c.hour, err = parseOnCalendarChains(parts[0], 0, 23); err != nil {
+		return fmt.Errorf("invalid calendar event hour %q: %w", parts[0], err)
+	}
+	if c.minute, err = parseOnCalendarChains(parts[1], 0, 59); err != nil {
+		return fmt.Errorf("invalid calendar event minute %q: %w", parts[1], err)
+	}
+	if c.second, err = parseOnCalendarChains(parts[2], 0, 59); err != nil {
+		return fmt.Errorf("invalid calendar event second %q: %w", parts[2], err)
+	}
+	return nil
+}
+
+// parseOnCalendarChains returns the sorted, distinct chains of a comma separated component, or nil for *
+func parseOnCalendarChains(component string, min, max int) ([]onCalendarChain, error) {
+	if component == "*" {
+		return nil, nil
+	}
+	var chains []onCalendarChain
+	for _, item := range strings.Split(component, ",") {
+		chain := onCalendarChain{stop: -1}
+		valueRange, repeat, hasRepeat := strings.Cut(item, "/")
+		if hasRepeat {
+			var err error
+			if chain.repeat, err = strconv.Atoi(repeat); err != nil || chain.repeat < 1 {
+				return nil, fmt.Errorf("invalid repeat %q", repeat)
+			}
+		}
+		first, last, isRange := strings.Cut(valueRange, "..")
+		var err error
+		if first == "*" && hasRepeat && !isRange {
+			chain.start = min
+		} else if chain.start, err = parseOnCalendarValue(first, min, max); err != nil {
+			return nil, err
+		}
+		if isRange {
+			if chain.stop, err = parseOnCalendarValue(last, min, max); err != nil {
+				return nil, err
+			}
+			if chain.stop < chain.start {
+				return nil, fmt.Errorf("invalid range %q", valueRange)
+			}
+		}
+		chains = append(chains, chain)
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		if chains[i].start != chains[j].start {
+			return chains[i].start < chains[j].start
+		}
+		if chains[i].stop != chains[j].stop {
+			return chains[i].stop < chains[j].stop
+		}
+		return chains[i].repeat < chains[j].repeat
+	})
+	distinct := chains[:1]
+	for _, chain := range chains[1:] {
+		if chain != distinct[len(distinct)-1] {
+			distinct = append(distinct, chain)
+		}
+	}
+	return distinct, nil
+}
+
+func parseOnCalendarValue(value string, min, max int) (int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	if min == 1970 && i < 100 { // two digit years are 1970 to 2069
+		if i < 70 {
+			i += 2000
+		} else {
+			i += 1900
+		}
+	}
+	if i < min || i > max {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return i, nil
+}
+
+// parseOnCalendarWeekdays returns the weekdays bits of a comma separated list of days of the week and day..day ranges
+func parseOnCalendarWeekdays(field string) (int, error) {
+	var weekdays int
+	for i, item := range strings.Split(field, ",") {
+		if item == "" && i > 0 { // systemd allows a trailing comma such as "Wed, 17:48"
+			continue
+		}
+		first, last, isRange := strings.Cut(item, "..")
+		start, err := parseOnCalendarWeekday(first)
+		if err != nil {
+			return 0, err
+		}
+		stop := start
+		if isRange {
+			if stop, err = parseOnCalendarWeekday(last); err != nil {
+				return 0, err
+			}
+			if stop < start {
+				return 0, fmt.Errorf("invalid range %q", item)
+			}
+		}
+		for day := start; day <= stop; day++ {
+			weekdays |= 1 << day
+		}
+	}
+	return weekdays, nil
+}
+
+// parseOnCalendarWeekday returns the index in onCalendarDayNames of a full or three letter day name
+func parseOnCalendarWeekday(name string) (int, error) {
+	for i, dayName := range onCalendarDayNames {
+		if strings.EqualFold(name, dayName) || strings.EqualFold(name, dayName[:3]) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day of week %q", name)
+}
+
+// formatOnCalendarWeekdays writes runs of three or more days as ranges, as systemd does
+func formatOnCalendarWeekdays(weekdays int) string {
+	var items []string
+	for day := 0; day < 7; {
+		if weekdays&(1<<day) == 0 {
+			day++
+			continue
+		}
+		last := day
+		for last+1 < 7 && weekdays&(1<<(last+1)) != 0 {
+			last++
+		}
+		switch {
+		case last-day >= 2:
+			items = append(items, onCalendarDayNames[day][:3]+".."+onCalendarDayNames[last][:3])
+		case last > day:
+			items = append(items, onCalendarDayNames[day][:3], onCalendarDayNames[last][:3])
+		default:
+			items = append(items, onCalendarDayNames[day][:3])
+		}
+		day = last + 1
+	}
+	return strings.Join(items, ",")
+}
+
+func formatOnCalendarChains(chains []onCalendarChain, format string) string {
+	if chains == nil {
+		return "*"
+	}
+	items := make([]string, len(chains))
+	for i, chain := range chains {
+		items[i] = fmt.Sprintf(format, chain.start)
+		if chain.stop >= 0 {
+			items[i] += ".." + fmt.Sprintf(format, chain.stop)
+		}
+		if chain.repeat > 0 {
+			items[i] += "/" + strconv.Itoa(chain.repeat)
+		}
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,183 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNormalizeOnCalendar(t *testing.T) {
+	// the examples of systemd.time(7) other than fractional seconds, which are not supported
+	examples := []struct {
+		expr, normalized string
+	}{
+		{"Sat,Thu,Mon..Wed,Sat..Sun", "Mon..Thu,Sat,Sun *-*-* 00:00:00"},
+		{"Mon,Sun 12-*-* 2,1:23", "Mon,Sun 2012-*-* 01,02:23:00"},
+		{"Wed *-1", "Wed *-*-01 00:00:00"},
+		{"Wed..Wed,Wed *-1", "Wed *-*-01 00:00:00"},
+		{"Wed, 17:48", "Wed *-*-* 17:48:00"},
+		{"Wed..Sat,Tue 12-10-15 1:2:3", "Tue..Sat 2012-10-15 01:02:03"},
+		{"*-*-7 0:0:0", "*-*-07 00:00:00"},
+		{"10-15", "*-10-15 00:00:00"},
+		{"monday *-12-* 17:00", "Mon *-12-* 17:00:00"},
+		{"Mon,Fri *-*-3,1,2 *:30:45", "Mon,Fri *-*-01,02,03 *:30:45"},
+		{"12,14,13,12:20,10,30", "*-*-* 12,13,14:10,20,30:00"},
+		{"12..14:10,20,30", "*-*-* 12..14:10,20,30:00"},
+		{"mon,fri *-1/2-1,3 *:30:45", "Mon,Fri *-01/2-01,03 *:30:45"},
+		{"03-05 08:05:40", "*-03-05 08:05:40"},
+		{"08:05:40", "*-*-* 08:05:40"},
+		{"05:40", "*-*-* 05:40:00"},
+		{"Sat,Sun 12-05 08:05:40", "Sat,Sun *-12-05 08:05:40"},
+		{"Sat,Sun 08:05:40", "Sat,Sun *-*-* 08:05:40"},
+		{"2003-03-05 05:40", "2003-03-05 05:40:00"},
+		{"2003-02..04-05", "2003-02..04-05 00:00:00"},
+		{"2003-03-05 05:40 UTC", "2003-03-05 05:40:00 UTC"},
+		{"2003-03-05", "2003-03-05 00:00:00"},
+		{"03-05", "*-03-05 00:00:00"},
+		{"hourly", "*-*-* *:00:00"},
+		{"daily", "*-*-* 00:00:00"},
+		{"daily UTC", "*-*-* 00:00:00 UTC"},
+		{"monthly", "*-*-01 00:00:00"},
+		{"weekly", "Mon *-*-* 00:00:00"},
+		{"weekly Pacific/Auckland", "Mon *-*-* 00:00:00 Pacific/Auckland"},
+		{"yearly", "*-01-01 00:00:00"},
+		{"annually", "*-01-01 00:00:00"},
+		{"*:2/3", "*-*-* *:02/3:00"},
+		{"*-02~03", "*-02~03 00:00:00"},
+		{"Mon *-05~07/1", "Mon *-05~07/1 00:00:00"},
+	}
+	for _, example := range examples {
+		actual, err := NormalizeOnCalendar(example.expr)
+		if err != nil {
+			t.Error(example.expr, err)
+		} else if actual != example.normalized {
+			t.Errorf("%s: expected %s vs actual %s", example.expr, example.normalized, actual)
+		}
+	}
+
+	invalid := []string{"", "Funday", "*-13-01", "*-*-32", "25:00", "*-*-* 1:2:3:4", "Fri..Mon", "*-*~01..03", "05:40:23.4200004/3.1700005",
+		"daily Nowhere/Special", "*-*-* 00:00 extra", "*/0:00", "1969-01-01", "Mon *-*-* 00:00 UTC extra"}
+	for _, expr := range invalid {
+		if _, err := NormalizeOnCalendar(expr); err == nil {
+			t.Errorf("expected %s to be invalid", expr)
+		}
+	}
+}
+
+func TestParseOnCalendar(t *testing.T) {
+	start := time.Date(2016, 2, 10, 0, 0, 0, 0, time.UTC) // Wednesday
+	at := func(year int, month time.Month, day, hour, minute, second int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+	}
+	auckland, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstWeek := func(dayOfWeek int16) Recurrence {
+		return Recurrence{StartDate: at(2016, 2, 10, 9, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(dayOfWeek), MonthlyWeekOfMonth: int16Ptr(1)}
+	}
+	expressions := []struct {
+		expr     string
+		expected []Recurrence
+	}{
+		{"Mon..Fri *-*-01..07 09:00", []Recurrence{firstWeek(1), firstWeek(2), firstWeek(3), firstWeek(4), firstWeek(5)}},
+		{"daily", []Recurrence{{StartDate: at(2016, 2, 10, 0, 0, 0), RecurrencePatternCode: "D", RecurEvery: 1}}},
+		{"*-*-* 9,17:00", []Recurrence{
+			{StartDate: at(2016, 2, 10, 9, 0, 0), RecurrencePatternCode: "D", RecurEvery: 1},
+			{StartDate: at(2016, 2, 10, 17, 0, 0), RecurrencePatternCode: "D", RecurEvery: 1},
+		}},
+		{"Sat,Sun 08:05:40", []Recurrence{{StartDate: at(2016, 2, 10, 8, 5, 40), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(64 + 1)}}},
+		{"weekly Pacific/Auckland", []Recurrence{{StartDate: time.Date(2016, 2, 10, 0, 0, 0, 0, auckland), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(32)}}},
+		{"quarterly", []Recurrence{{StartDate: at(2016, 4, 1, 0, 0, 0), RecurrencePatternCode: "M", RecurEvery: 3, MonthlyDay: int16Ptr(1)}}},
+		{"*-*~01 12:00", []Recurrence{{StartDate: at(2016, 2, 10, 12, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-1)}}},
+		{"*-02~03", []Recurrence{{StartDate: at(2016, 2, 10, 0, 0, 0), RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(-3)}}},
+		{"Mon *-05~07/1", []Recurrence{{StartDate: at(2016, 2, 10, 0, 0, 0), RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(5), MonthlyDayOfWeek: int16Ptr(1), MonthlyWeekOfMonth: int16Ptr(54)}}},
+		{"Tue *-*-29..31", []Recurrence{{StartDate: at(2016, 2, 10, 0, 0, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(2), MonthlyWeekOfMonth: int16Ptr(5)}}},
+		{"2016/4-02-29 12:00", []Recurrence{{StartDate: at(2016, 2, 10, 12, 0, 0), RecurrencePatternCode: "Y", RecurEvery: 4, YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(29)}}},
+		{"Thu 2017/2-11-22..28", []Recurrence{{StartDate: at(2017, 1, 1, 0, 0, 0), RecurrencePatternCode: "Y", RecurEvery: 2, YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(4)}}},
+		{"2015/2-07-04", []Recurrence{{StartDate: at(2017, 1, 1, 0, 0, 0), RecurrencePatternCode: "Y", RecurEvery: 2, YearlyMonth: int16Ptr(7), MonthlyDay: int16Ptr(4)}}},
+	}
+	for _, expression := range expressions {
+		actual, err := ParseOnCalendar(expression.expr, start)
+		if err != nil {
+			t.Error(expression.expr, err)
+			continue
+		}
+		if len(actual) != len(expression.expected) {
+			t.Errorf("%s: expected %d recurrences vs actual %d", expression.expr, len(expression.expected), len(actual))
+			continue
+		}
+		for i := range actual {
+			compareRecurrences(t, &expression.expected[i], &actual[i], fmt.Sprintf("%s[%d]", expression.expr, i))
+		}
+	}
+
+	unrepresentable := []string{"2003-03-05", "*-06..08-* 00:00", "Mon *-12-* 17:00", "Mon *-*-03", "2016/2-*-01", "2016..2018-01-01"}
+	for _, expr := range unrepresentable {
+		if _, err := ParseOnCalendar(expr, start); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be unrepresentable: %v", expr, err)
+		}
+	}
+	if _, err := ParseOnCalendar("*-*-* 25:00", start); err == nil || errors.Is(err, ErrNotRepresentable) {
+		t.Error("expected invalid hour", err)
+	}
+}
+
+func TestOnCalendar(t *testing.T) {
+	start := time.Date(2016, 2, 10, 9, 30, 0, 0, time.UTC)
+	recurrences := []struct {
+		recurrence Recurrence
+		expr       string
+	}{
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1}, "*-*-* 09:30:00 UTC"},
+		{Recurrence{StartDate: time.Date(2016, 2, 10, 9, 30, 15, 0, time.Local), RecurrencePatternCode: "D", RecurEvery: 1}, "*-*-* 09:30:15"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)}, "Mon..Fri *-*-* 09:30:00 UTC"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(32 + 8 + 2)}, "Mon,Wed,Fri *-*-* 09:30:00 UTC"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(64 + 1)}, "Sat,Sun *-*-* 09:30:00 UTC"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(127)}, "*-*-* 09:30:00 UTC"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15)}, "*-*-15 09:30:00 UTC"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-1)}, "*-*~01 09:30:00 UTC"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 3, MonthlyDay: int16Ptr(15)}, "*-02/3-15 09:30:00 UTC"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(2), MonthlyWeekOfMonth: int16Ptr(2)}, "Tue *-*-08..14 09:30:00 UTC"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(2), MonthlyWeekOfMonth: int16Ptr(5)}, "Tue *-*-29..31 09:30:00 UTC"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54)}, "Thu *-*~07/1 09:30:00 UTC"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(4)}, "Thu *-11-22..28 09:30:00 UTC"},
+		{Recurrence{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 4, YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(29)}, "2016/4-02-29 09:30:00 UTC"},
+	}
+	for _, recurrence := range recurrences {
+		actual, err := recurrence.recurrence.OnCalendar()
+		if err != nil {
+			t.Error(recurrence.expr, err)
+		} else if actual != recurrence.expr {
+			t.Errorf("expected %s vs actual %s", recurrence.expr, actual)
+		}
+
+		// the calendar event must fire on the same dates as the recurrence it came from
+		parsed, err := ParseOnCalendar(actual, recurrence.recurrence.StartDate)
+		if err != nil || len(parsed) != 1 {
+			t.Error(recurrence.expr, err)
+			continue
+		}
+		periodEnd := start.AddDate(10, 0, 0)
+		compareTimes(t, recurrence.recurrence.GetOccurrences(start, periodEnd), parsed[0].GetOccurrences(start, periodEnd), recurrence.expr)
+	}
+
+	unrepresentable := []Recurrence{
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 2},
+		{StartDate: start, RecurrencePatternCode: "W", RecurEvery: 3, WeeklyDaysIncluded: int16Ptr(32)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 5, MonthlyDay: int16Ptr(1)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15), MonthlyNearestWeekday: boolPtr(true)},
+		{StartDate: start, RecurrencePatternCode: "M", RecurEvery: 1},
+		{StartDate: start, RecurrencePatternCode: "Y", RecurEvery: 1, MonthlyDay: int16Ptr(4)},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, EndByDate: timePtr(start.AddDate(1, 0, 0))},
+		{StartDate: start, RecurrencePatternCode: "D", RecurEvery: 1, NumberOfOccurrences: int16Ptr(5)},
+		{StartDate: start.Add(time.Millisecond), RecurrencePatternCode: "D", RecurEvery: 1},
+		{StartDate: start, RecurrencePatternCode: "X", RecurEvery: 1},
+	}
+	for i, r := range unrepresentable {
+		if _, err := r.OnCalendar(); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected recurrence %d to be unrepresentable: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,857 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// textFillers are words ParseText skips wherever they appear
+var textFillers = map[string]bool{
+	"and": true, "on": true, "the": true, "of": true, "in": true, "repeat": true, "repeats": true, "repeating": true,
+	"recurring": true, "occurs": true, "happens": true, "&": true, "-": true,
+}
+
+// textFrequencies are the adverbs that alone give how often a recurrence repeats
+var textFrequencies = map[string]struct {
+	code  Frequency
+	every int
+}{
+	"daily": {FrequencyDaily, 1}, "nightly": {FrequencyDaily, 1},
+	"weekly": {FrequencyWeekly, 1}, "biweekly": {FrequencyWeekly, 2}, "fortnightly": {FrequencyWeekly, 2},
+	"monthly": {FrequencyMonthly, 1}, "quarterly": {FrequencyMonthly, 3}, "semiannually": {FrequencyMonthly, 6},
+	"yearly": {FrequencyYearly, 1}, "annually": {FrequencyYearly, 1},
+}
+
+// textUnits are the units that can follow every and a number, with the pattern code and multiple of the number
+var textUnits = map[string]struct {
+	code  Frequency
+	every int
+}{
+	"day": {FrequencyDaily, 1}, "days": {FrequencyDaily, 1},
+	"week": {FrequencyWeekly, 1}, "weeks": {FrequencyWeekly, 1}, "fortnight": {FrequencyWeekly, 2}, "fortnights": {FrequencyWeekly, 2},
+	"month": {FrequencyMonthly, 1}, "months": {FrequencyMonthly, 1}, "quarter": {FrequencyMonthly, 3}, "quarters": {FrequencyMonthly, 3},
+	"year": {FrequencyYearly, 1}, "years": {FrequencyYearly, 1},
+}
+
+var textNumbers = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+	"ten": 10, "eleven": 11, "twelve": 12, "other": 2,
+}
+
+var textOrdinals = map[string]int{"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1}
+
+var textDays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "weds": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var textMonths = map[string]time.Month{
+	"jan": time.January, "january": time.January, "feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March, "apr": time.April, "april": time.April, "may": time.May,
+	"jun": time.June, "june": time.June, "jul": time.July, "july": time.July, "aug": time.August, "august": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September, "oct": time.October, "october": time.October,
+	"nov": time.November, "november": time.November, "dec": time.December, "december": time.December,
+}
+
+// ParseError reports the phrase ParseText could not understand
+type ParseError struct {
+	Text    string // text being parsed
+	Offset  int    // byte offset of Phrase in Text
+	Phrase  string // phrase that was not understood, empty when something is missing at the end of Text
+	Message string // what went wrong, e.g. "expected a day of the week"
+}
+
+func (e *ParseError) Error() string {
+	if e.Phrase == "" {
+		return fmt.Sprintf("%s at the end of %q", e.Message, e.Text)
+	}
+	return fmt.Sprintf("%s: %q at offset %d of %q", e.Message, e.Phrase, e.Offset, e.Text)
+}
+
+type textToken struct {
+	word        string // lower case with dots removed
+	offset, end int
+}
+
+type textParser struct {
+	text   string
+	tokens []textToken
+	pos    int
+	start  time.Time
+
+	code        Frequency
+	every       int // 0 when only implied, e.g. by a day of the week
+	weekdays    int16
+	onlyWeekday bool
+	monthlyDay  *int16
+	nearest     bool
+	dayOfWeek   *int16
+	weekOfMonth *int16
+	yearlyMonth *int16
+
+	hour, minute int
+	hasTime      bool
+	duration     time.Duration
+	startDate    *time.Time
+	endByDate    *time.Time
+	count        *int16
+	forPeriod    int       // number of forUnit the recurrence lasts for
+	forUnit      Frequency // pattern code of the forPeriod unit
+}
+
+// ParseText converts an English description such as "every other Tuesday until March", "first Monday of each month"
+// or "weekdays at 9am for 30 minutes" to a Series starting on the date of start or the date given after "starting".
+// The series is all day unless a time of day is given. "until" a month ends the day before the month starts and
+// "through" a month ends on its last day, and a number of times ends on the last of the occurrences. Text that cannot
+// be understood returns a *ParseError pointing at the phrase
+func ParseText(text string, start time.Time) (*Series, error) {
+	p := &textParser{text: text, tokens: tokenizeText(text), start: start}
+	for p.pos < len(p.tokens) {
+		if err := p.parseClause(); err != nil {
+			return nil, err
+		}
+	}
+	return p.series()
+}
+
+func tokenizeText(text string) []textToken {
+	var tokens []textToken
+	offset := -1
+	for i, r := range text + " " {
+		separator := unicode.IsSpace(r) || r == ',' || r == ';'
+		if separator && offset >= 0 {
+			word := strings.ReplaceAll(strings.ToLower(text[offset:i]), ".", "")
+			if word != "" {
+				tokens = append(tokens, textToken{word, offset, i})
+			}
+			offset = -1
+		} else if !separator && offset < 0 {
+			offset = i
+		}
+	}
+	return tokens
+}
+
+func (p *textParser) peek(ahead int) string {
+	if p.pos+ahead < len(p.tokens) {
+		return p.tokens[p.pos+ahead].word
+	}
+	return ""
+}
+
+// errorAt returns a ParseError for the token at pos, or for the end of the text when pos is past the last token
+func (p *textParser) errorAt(pos int, message string) *ParseError {
+	if pos >= len(p.tokens) {
+		return &ParseError{Text: p.text, Offset: len(p.text), Message: message}
+	}
+	token := p.tokens[pos]
+	return &ParseError{Text: p.text, Offset: token.offset, Phrase: p.text[token.offset:token.end], Message: message}
+}
+
+func (p *textParser) parseClause() error {
+	word := p.peek(0)
+	if frequency, ok := textFrequencies[word]; ok {
+		p.pos++
+		return p.setPattern(frequency.code, frequency.every, p.pos-1)
+	}
+	if word == "once" && (p.peek(1) == "a" || p.peek(1) == "per" || p.peek(1) == "every" || p.peek(1) == "each") {
+		unit, ok := textUnits[p.peek(2)]
+		if !ok || unit.code == FrequencyDaily && p.peek(2) != "day" {
+			return p.errorAt(p.pos+2, "expected day, week, month or year")
+		}
+		p.pos += 3
+		return p.setPattern(unit.code, unit.every, p.pos-1)
+	}
+	if _, ok := textCount(p.peek(0), p.peek(1)); ok {
+		return p.parseCount()
+	}
+	if _, _, ok := p.timeAt(p.pos, false); ok {
+		return p.parseTimes()
+	}
+	switch {
+	case textFillers[word]:
+		p.pos++
+		return nil
+	case word == "every" || word == "each":
+		return p.parseEvery()
+	case word == "weekdays" || word == "weekday":
+		p.pos++
+		p.onlyWeekday = true
+		return p.setPattern(FrequencyDaily, 1, p.pos-1)
+	case word == "weekends" || word == "weekend":
+		p.pos++
+		p.weekdays |= weekdayBit(time.Saturday) | weekdayBit(time.Sunday)
+		return p.setPattern(FrequencyWeekly, 0, p.pos-1)
+	case isTextDay(word):
+		return p.parseDays()
+	case isTextOrdinal(word):
+		_, err := p.parseOrdinal()
+		return err
+	case isTextMonth(word):
+		return p.parseMonthDay()
+	case word == "at":
+		p.pos++
+		return p.parseTimes()
+	case word == "from":
+		// from is followed by either a start date or the times of day an occurrence runs from and to
+		pos := p.pos
+		p.pos++
+		if _, _, ok := p.parseDate(); ok {
+			p.pos = pos
+			return p.parseStart()
+		}
+		return p.parseTimes()
+	case word == "for":
+		return p.parseFor()
+	case word == "until" || word == "till" || word == "til" || word == "through" || word == "thru" || word == "ending":
+		return p.parseEnd()
+	case word == "starting" || word == "beginning" || word == "starts" || word == "begins":
+		return p.parseStart()
+	}
+	return p.errorAt(p.pos, "unrecognized phrase")
+}
+
+// setPattern sets the pattern code and interval of the phrase at pos. every is 0 when the phrase only implies the
+// pattern, as days of the week imply a weekly recurrence
+func (p *textParser) setPattern(code Frequency, every int, pos int) error {
+	switch {
+	case p.code == "":
+		p.code, p.every = code, every
+	case p.code != code:
+		return p.errorAt(pos, "conflicting repeat")
+	case every != 0 && p.every != 0 && every != p.every:
+		return p.errorAt(pos, "conflicting interval")
+	case every != 0:
+		p.every = every
+	}
+	return nil
+}
+
+// parseEvery parses every [n|other] followed by a unit, days of the week, a month or an nth day of the week
+func (p *textParser) parseEvery() error {
+	p.pos++
+	if word := p.peek(0); word == "first" || word == "last" {
+		_, err := p.parseOrdinal()
+		return err
+	}
+	if isTextOrdinal(p.peek(0)) {
+		// every 2nd Monday of the month is an nth day of the week, but every 2nd Tuesday alone is every other Tuesday
+		saved := *p
+		if ok, err := p.parseOrdinal(); err == nil && ok {
+			return nil
+		}
+		*p = saved
+	}
+	every, ok := p.intervalAt(p.pos)
+	if ok {
+		p.pos++
+	} else {
+		every = 1
+	}
+	word := p.peek(0)
+	if unit, ok := textUnits[word]; ok {
+		p.pos++
+		return p.setPattern(unit.code, every*unit.every, p.pos-1)
+	}
+	switch {
+	case word == "weekday" || word == "weekdays":
+		p.pos++
+		p.onlyWeekday = true
+		return p.setPattern(FrequencyDaily, every, p.pos-1)
+	case word == "weekend" || word == "weekends":
+		p.pos++
+		p.weekdays |= weekdayBit(time.Saturday) | weekdayBit(time.Sunday)
+		return p.setPattern(FrequencyWeekly, every, p.pos-1)
+	case isTextDay(word):
+		if err := p.setPattern(FrequencyWeekly, every, p.pos); err != nil {
+			return err
+		}
+		return p.parseDays()
+	case isTextMonth(word):
+		if err := p.setPattern(FrequencyYearly, every, p.pos); err != nil {
+			return err
+		}
+		return p.parseMonthDay()
+	}
+	return p.errorAt(p.pos, "expected day, week, month, year or a day of the week")
+}
+
+// intervalAt returns the number of units between recurrences given by the word at pos: a number, other or an
+// ordinal such as second or 3rd
+func (p *textParser) intervalAt(pos int) (int, bool) {
+	if pos >= len(p.tokens) {
+		return 0, false
+	}
+	word := p.tokens[pos].word
+	if n, ok := textNumbers[word]; ok {
+		return n, true
+	}
+	if n, err := strconv.Atoi(word); err == nil && n > 0 {
+		return n, true
+	}
+	if n, ok := textOrdinals[word]; ok && n > 1 {
+		return n, true
+	}
+	if n, ok := textNumericOrdinal(word); ok {
+		return n, true
+	}
+	return 0, false
+}
+
+// parseDays parses a list of days of the week such as "mon, wed and fri" or "tuesdays and thursdays"
+func (p *textParser) parseDays() error {
+	pos := p.pos
+	for isTextDay(p.peek(0)) || (p.peek(0) == "and" || p.peek(0) == "&" || p.peek(0) == "or") && isTextDay(p.peek(1)) {
+		if day, ok := textDay(p.peek(0)); ok {
+			p.weekdays |= weekdayBit(day)
+		}
+		p.pos++
+	}
+	return p.setPattern(FrequencyWeekly, 0, pos)
+}
+
+// parseOrdinal parses an ordinal followed by a day of the week, day or weekday, and then optionally "of the month",
+// "of every 2 months" or "of March". Alone, a numeric ordinal such as 15th is a day of the month. It returns whether
+// the ordinal was followed by one of the "of" phrases
+func (p *textParser) parseOrdinal() (bool, error) {
+	pos := p.pos
+	word := p.peek(0)
+	n, isWord := textOrdinals[word]
+	if !isWord {
+		n, _ = textNumericOrdinal(word)
+	}
+	p.pos++
+	next := p.peek(0)
+	switch {
+	case isTextDay(next):
+		day, _ := textDay(next)
+		p.pos++
+		if n > 5 {
+			return false, p.errorAt(pos, "expected first to fifth or last")
+		}
+		weekOfMonth, dayOfWeek := int16(n), int16(day)
+		if n == -1 {
+			weekOfMonth = 54
+		}
+		if p.dayOfWeek != nil || p.monthlyDay != nil {
+			return false, p.errorAt(pos, "more than one day of the month")
+		}
+		p.weekOfMonth, p.dayOfWeek = &weekOfMonth, &dayOfWeek
+	case next == "weekday" && (n == 1 || n == -1):
+		p.pos++
+		p.nearest = true
+		if err := p.setMonthlyDay(n, pos); err != nil {
+			return false, err
+		}
+	case next == "day" || next == "weekday" || next == "to" && p.peek(1) == "last" && p.peek(2) == "day" || !isWord || n == 1:
+		if next == "day" {
+			p.pos++
+		} else if next == "weekday" {
+			return false, p.errorAt(pos, "expected first or last weekday")
+		}
+		if next == "to" && p.peek(1) == "last" && p.peek(2) == "day" && n > 1 {
+			p.pos += 3
+			n = -n
+		}
+		if err := p.setMonthlyDay(n, pos); err != nil {
+			return false, err
+		}
+	default:
+		return false, p.errorAt(p.pos, "expected a day of the week or day")
+	}
+	return p.parseOfMonth()
+}
+
+func (p *textParser) setMonthlyDay(n, pos int) error {
+	if n > 31 || n == 0 {
+		return p.errorAt(pos, "expected a day of the month")
+	}
+	if p.dayOfWeek != nil || p.monthlyDay != nil {
+		return p.errorAt(pos, "more than one day of the month")
+	}
+	monthlyDay := int16(n)
+	p.monthlyDay = &monthlyDay
+	return nil
+}
+
+// parseOfMonth parses an optional "of|in [the|each|every] [n|other] month[s]" or "of|in <month>"
+func (p *textParser) parseOfMonth() (bool, error) {
+	if p.peek(0) != "of" && p.peek(0) != "in" {
+		return false, nil
+	}
+	pos := p.pos + 1
+	for word := p.word(pos); word == "the" || word == "each" || word == "every"; word = p.word(pos) {
+		pos++
+	}
+	every, hasInterval := p.intervalAt(pos)
+	if hasInterval {
+		pos++
+	} else {
+		every = 1
+	}
+	switch word := p.word(pos); {
+	case word == "month" || word == "months":
+		p.pos = pos + 1
+		return true, p.setPattern(FrequencyMonthly, every, pos)
+	case isTextMonth(word) && !hasInterval:
+		p.pos = pos
+		return true, p.parseMonthDay()
+	}
+	return false, nil
+}
+
+func (p *textParser) word(pos int) string {
+	if pos < len(p.tokens) {
+		return p.tokens[pos].word
+	}
+	return ""
+}
+
+// parseMonthDay parses a month, optionally followed by a day such as 5 or 5th, as the month of a yearly recurrence
+func (p *textParser) parseMonthDay() error {
+	pos := p.pos
+	month, _ := textMonth(p.peek(0))
+	p.pos++
+	if p.yearlyMonth != nil {
+		return p.errorAt(pos, "more than one month")
+	}
+	yearlyMonth := int16(month)
+	p.yearlyMonth = &yearlyMonth
+	if day, ok := textDayOfMonth(p.peek(0)); ok {
+		if err := p.setMonthlyDay(day, p.pos); err != nil {
+			return err
+		}
+		p.pos++
+	}
+	return p.setPattern(FrequencyYearly, 0, pos)
+}
+
+// parseTimes parses a time of day, optionally followed by "to" and the time it ends
+func (p *textParser) parseTimes() error {
+	hour, minute, ok := p.timeAt(p.pos, true)
+	if !ok {
+		return p.errorAt(p.pos, "expected a time of day")
+	}
+	if p.hasTime {
+		return p.errorAt(p.pos, "more than one time of day")
+	}
+	p.hour, p.minute, p.hasTime = hour, minute, true
+	p.pos += textTimeLength(p.peek(1))
+	if word := p.peek(0); word == "to" || word == "-" || word == "until" || word == "till" {
+		if endHour, endMinute, ok := p.timeAt(p.pos+1, true); ok {
+			p.pos++
+			p.duration = time.Duration(endHour-hour)*time.Hour + time.Duration(endMinute-minute)*time.Minute
+			if p.duration <= 0 {
+				p.duration += 24 * time.Hour
+			}
+			p.pos += textTimeLength(p.peek(1))
+		}
+	}
+	return nil
+}
+
+// timeAt returns the time of day at pos, such as 9am, 9:30 pm, 14:00 or noon. A bare hour such as 9 is a time only
+// when bare is true
+func (p *textParser) timeAt(pos int, bare bool) (int, int, bool) {
+	word := p.word(pos)
+	switch word {
+	case "noon", "midday":
+		return 12, 0, true
+	case "midnight":
+		return 0, 0, true
+	}
+	suffix := ""
+	for _, s := range []string{"am", "pm", "a", "p"} {
+		if strings.HasSuffix(word, s) && len(word) > len(s) {
+			word, suffix = strings.TrimSuffix(word, s), s[:1]
+			break
+		}
+	}
+	if suffix == "" && (p.word(pos+1) == "am" || p.word(pos+1) == "pm") {
+		suffix = p.word(pos + 1)[:1]
+	}
+	hourText, minuteText, hasMinute := strings.Cut(word, ":")
+	if !hasMinute && suffix == "" && !bare {
+		return 0, 0, false
+	}
+	hour, err := strconv.Atoi(hourText)
+	if err != nil || hour < 0 || hour > 23 || suffix != "" && (hour < 1 || hour > 12) {
+		return 0, 0, false
+	}
+	minute := 0
+	if hasMinute {
+		if minute, err = strconv.Atoi(minuteText); err != nil || len(minuteText) != 2 || minute > 59 {
+			return 0, 0, false
+		}
+	}
+	switch {
+	case suffix == "a" && hour == 12:
+		hour = 0
+	case suffix == "p" && hour < 12:
+		hour += 12
+	}
+	return hour, minute, true
+}
+
+// textTimeLength returns the number of tokens of a time whose next token is next
+func textTimeLength(next string) int {
+	if next == "am" || next == "pm" {
+		return 2
+	}
+	return 1
+}
+
+// parseFor parses "for" followed by a number of times, a duration such as 30 minutes or a period such as 10 weeks
+func (p *textParser) parseFor() error {
+	pos := p.pos
+	p.pos++
+	if _, ok := textCount(p.peek(0), p.peek(1)); ok {
+		return p.parseCount()
+	}
+	n, ok := textNumbers[p.peek(0)]
+	if !ok || p.peek(0) == "other" {
+		if n, ok = textInt(p.peek(0)); !ok || n < 1 {
+			return p.errorAt(p.pos, "expected a number")
+		}
+	}
+	unit := p.peek(1)
+	switch unit {
+	case "minute", "minutes", "min", "mins":
+		p.duration = time.Duration(n) * time.Minute
+	case "hour", "hours", "hr", "hrs":
+		p.duration = time.Duration(n) * time.Hour
+	default:
+		period, ok := textUnits[unit]
+		if !ok {
+			return p.errorAt(p.pos+1, "expected times, minutes, hours, days, weeks, months or years")
+		}
+		if p.forPeriod != 0 || p.endByDate != nil || p.count != nil {
+			return p.errorAt(pos, "more than one end")
+		}
+		p.forPeriod, p.forUnit = n*period.every, period.code
+	}
+	p.pos += 2
+	return nil
+}
+
+// parseCount parses a number of occurrences such as "10 times" or "twice"
+func (p *textParser) parseCount() error {
+	count, _ := textCount(p.peek(0), p.peek(1))
+	if count < 1 || count > 32767 {
+		return p.errorAt(p.pos, "expected a number of times from 1 to 32767")
+	}
+	if p.count != nil {
+		return p.errorAt(p.pos, "more than one number of times")
+	}
+	if p.endByDate != nil || p.forPeriod != 0 {
+		return p.errorAt(p.pos, "more than one end")
+	}
+	numberOfOccurrences := int16(count)
+	p.count = &numberOfOccurrences
+	if p.peek(0) == "once" || p.peek(0) == "twice" {
+		p.pos++
+	} else {
+		p.pos += 2
+	}
+	return nil
+}
+
+// textCount returns the number of occurrences given by word and the word after it
+func textCount(word, next string) (int, bool) {
+	switch word {
+	case "once":
+		return 1, true
+	case "twice":
+		return 2, true
+	}
+	if next != "times" && next != "time" && next != "occurrences" && next != "occurrence" {
+		return 0, false
+	}
+	if n, ok := textNumbers[word]; ok && word != "other" {
+		return n, true
+	}
+	return textInt(word)
+}
+
+// parseEnd parses until, till or through followed by a date or a month
+func (p *textParser) parseEnd() error {
+	pos := p.pos
+	through := p.peek(0) == "through" || p.peek(0) == "thru"
+	p.pos++
+	date, monthOnly, ok := p.parseDate()
+	if !ok {
+		return p.errorAt(p.pos, "expected a date")
+	}
+	if p.endByDate != nil || p.forPeriod != 0 || p.count != nil {
+		return p.errorAt(pos, "more than one end")
+	}
+	if monthOnly {
+		// until March ends before March starts, and through March at the end of March
+		if through {
+			date = date.AddDate(0, 1, 0)
+		}
+		date = date.AddDate(0, 0, -1)
+	}
+	p.endByDate = &date
+	return nil
+}
+
+// parseStart parses starting, beginning or from followed by a date
+func (p *textParser) parseStart() error {
+	pos := p.pos
+	p.pos++
+	if p.peek(0) == "on" || p.peek(0) == "from" {
+		p.pos++
+	}
+	date, _, ok := p.parseDate()
+	if !ok {
+		return p.errorAt(p.pos, "expected a date")
+	}
+	if p.startDate != nil {
+		return p.errorAt(pos, "more than one start")
+	}
+	p.startDate = &date
+	return nil
+}
+
+// parseDate parses today, tomorrow, 2026-06-30, 6/30[/2026], March [5[th]] [2027] or 5[th] [of] March [2027]. Dates
+// without a year are the next such date on or after the start. monthOnly is true for a month without a day, which
+// returns the first of the month
+func (p *textParser) parseDate() (date time.Time, monthOnly bool, ok bool) {
+	startDate := time.Date(p.start.Year(), p.start.Month(), p.start.Day(), 0, 0, 0, 0, p.start.Location())
+	word := p.peek(0)
+	switch word {
+	case "today":
+		p.pos++
+		return startDate, false, true
+	case "tomorrow":
+		p.pos++
+		return startDate.AddDate(0, 0, 1), false, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", word, p.start.Location()); err == nil {
+		p.pos++
+		return t, false, true
+	}
+	if parts := strings.Split(word, "/"); len(parts) == 2 || len(parts) == 3 {
+		month, monthErr := strconv.Atoi(parts[0])
+		day, dayErr := strconv.Atoi(parts[1])
+		year := -1
+		if len(parts) == 3 {
+			year, _ = strconv.Atoi(parts[2])
+		}
+		if monthErr != nil || dayErr != nil || month < 1 || month > 12 || day < 1 || day > daysIn(time.Month(month), 2016) || len(parts) == 3 && year < 1 {
+			return time.Time{}, false, false
+		}
+		p.pos++
+		return p.nextDate(time.Month(month), day, year), false, true
+	}
+
+	pos := p.pos
+	month, isMonth := textMonth(word)
+	day, isDay := textDayOfMonth(word)
+	switch {
+	case isMonth:
+		p.pos++
+		if day, isDay = textDayOfMonth(p.peek(0)); isDay {
+			p.pos++
+		} else {
+			day, monthOnly = 1, true
+		}
+	case isDay:
+		p.pos++
+		if p.peek(0) == "of" {
+			p.pos++
+		}
+		if month, isMonth = textMonth(p.peek(0)); !isMonth {
+			p.pos = pos
+			return time.Time{}, false, false
+		}
+		p.pos++
+	default:
+		return time.Time{}, false, false
+	}
+	if day > daysIn(month, 2016) {
+		p.pos = pos
+		return time.Time{}, false, false
+	}
+	year := -1
+	if y, ok := textInt(p.peek(0)); ok && y >= 1000 && y <= 9999 {
+		year = y
+		p.pos++
+	}
+	return p.nextDate(month, day, year), monthOnly, true
+}
+
+// nextDate returns the date in year, or when year is -1 the next such date on or after the start
+func (p *textParser) nextDate(month time.Month, day, year int) time.Time {
+	if year >= 0 {
+		return time.Date(year, month, day, 0, 0, 0, 0, p.start.Location())
+	}
+	startDate := time.Date(p.start.Year(), p.start.Month(), p.start.Day(), 0, 0, 0, 0, p.start.Location())
+	for year = p.start.Year(); ; year++ {
+		// February 29 waits for a leap year
+		if date := time.Date(year, month, day, 0, 0, 0, 0, p.start.Location()); date.Day() == day && !date.Before(startDate) {
+			return date
+		}
+	}
+}
+
+// series resolves the parsed phrases into a Series, filling in what the text left out from the start date
+func (p *textParser) series() (*Series, error) {
+	end := len(p.tokens)
+	startDate := time.Date(p.start.Year(), p.start.Month(), p.start.Day(), 0, 0, 0, 0, p.start.Location())
+	if p.startDate != nil {
+		startDate = *p.startDate
+	}
+	if p.code == "" {
+		switch {
+		case p.monthlyDay == nil && p.dayOfWeek == nil:
+			return nil, p.errorAt(end, "expected how often it repeats")
+		case p.yearlyMonth != nil:
+			p.code = FrequencyYearly
+		default:
+			p.code = FrequencyMonthly
+		}
+	}
+	r := Recurrence{StartDate: startDate, RecurrencePatternCode: p.code, RecurEvery: 1, EndByDate: p.endByDate}
+	if p.every > 1 {
+		if p.every > 32767 {
+			return nil, p.errorAt(end, "interval is out of range")
+		}
+		r.RecurEvery = int16(p.every)
+	}
+	hasMonthDay := p.monthlyDay != nil || p.dayOfWeek != nil
+	switch p.code {
+	case FrequencyDaily:
+		if hasMonthDay || p.yearlyMonth != nil {
+			return nil, p.errorAt(end, "day of the month given for a daily recurrence")
+		}
+		if p.onlyWeekday {
+			r.DailyIsOnlyWeekday = &p.onlyWeekday
+		}
+	case FrequencyWeekly:
+		if hasMonthDay || p.yearlyMonth != nil {
+			return nil, p.errorAt(end, "day of the month given for a weekly recurrence")
+		}
+		weeklyDaysIncluded := p.weekdays
+		if weeklyDaysIncluded == 0 {
+			weeklyDaysIncluded = weekdayBit(startDate.Weekday())
+		}
+		r.WeeklyDaysIncluded = &weeklyDaysIncluded
+	case FrequencyMonthly, FrequencyYearly:
+		if p.code == FrequencyMonthly && p.yearlyMonth != nil {
+			return nil, p.errorAt(end, "month given for a monthly recurrence")
+		}
+		if p.code == FrequencyYearly && p.yearlyMonth == nil {
+			yearlyMonth := int16(startDate.Month())
+			p.yearlyMonth = &yearlyMonth
+		} else if p.code == FrequencyYearly && !hasMonthDay {
+			return nil, p.errorAt(end, "expected a day of the month")
+		}
+		if !hasMonthDay {
+			monthlyDay := int16(startDate.Day())
+			p.monthlyDay = &monthlyDay
+		}
+		r.YearlyMonth, r.MonthlyDay, r.MonthlyDayOfWeek, r.MonthlyWeekOfMonth = p.yearlyMonth, p.monthlyDay, p.dayOfWeek, p.weekOfMonth
+		if p.nearest {
+			r.MonthlyNearestWeekday = &p.nearest
+		}
+	}
+	if p.forPeriod > 0 {
+		var endByDate time.Time
+		switch p.forUnit {
+		case FrequencyDaily:
+			endByDate = startDate.AddDate(0, 0, p.forPeriod-1)
+		case FrequencyWeekly:
+			endByDate = startDate.AddDate(0, 0, 7*p.forPeriod-1)
+		case FrequencyMonthly:
+			endByDate = startDate.AddDate(0, p.forPeriod, -1)
+		case FrequencyYearly:
+			endByDate = startDate.AddDate(p.forPeriod, 0, -1)
+		}
+		r.EndByDate = &endByDate
+	}
+	if r.EndByDate != nil && r.EndByDate.Before(startDate) {
+		return nil, p.errorAt(end, "ends before it starts")
+	}
+	if p.count != nil {
+		if err := r.SetNumberOfOccurrences(int(*p.count)); err != nil {
+			return nil, p.errorAt(end, "does not repeat that many times")
+		}
+	}
+
+	s := &Series{Recurrence: r, AllDay: !p.hasTime, Duration: 24 * time.Hour}
+	if p.hasTime {
+		s.StartDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), p.hour, p.minute, 0, 0, startDate.Location())
+		s.Duration = p.duration
+	} else if p.duration != 0 {
+		return nil, p.errorAt(end, "duration given without a time of day")
+	}
+	return s, nil
+}
+
+func isTextDay(word string) bool {
+	_, ok := textDay(word)
+	return ok
+}
+
+// textDay returns the day of the week of a name, abbreviation or plural such as tuesdays
+func textDay(word string) (time.Weekday, bool) {
+	if day, ok := textDays[word]; ok {
+		return day, true
+	}
+	day, ok := textDays[strings.TrimSuffix(word, "s")]
+	return day, ok && strings.HasSuffix(word, "days")
+}
+
+func isTextMonth(word string) bool {
+	_, ok := textMonths[word]
+	return ok
+}
+
+func textMonth(word string) (time.Month, bool) {
+	month, ok := textMonths[word]
+	return month, ok
+}
+
+func isTextOrdinal(word string) bool {
+	if _, ok := textOrdinals[word]; ok {
+		return true
+	}
+	_, ok := textNumericOrdinal(word)
+	return ok
+}
+
+// textNumericOrdinal returns the number of an ordinal such as 1st, 22nd or 15th
+func textNumericOrdinal(word string) (int, bool) {
+	if len(word) < 3 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(word[:len(word)-2])
+	if err != nil || n < 1 || englishOrdinal(n) != word {
+		return 0, false
+	}
+	return n, true
+}
+
+// textDayOfMonth returns the day of a day of the month such as 5 or 5th
+func textDayOfMonth(word string) (int, bool) {
+	n, ok := textNumericOrdinal(word)
+	if !ok {
+		n, ok = textInt(word)
+	}
+	return n, ok && n >= 1 && n <= 31
+}
+
+func textInt(word string) (int, bool) {
+	n, err := strconv.Atoi(word)
+	return n, err == nil && word[0] != '+' && word[0] != '-'
+}
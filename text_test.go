@@ -0,0 +1,202 @@
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseText(t *testing.T) {
+	start := time.Date(2026, 1, 7, 15, 45, 0, 0, time.UTC) // Wednesday
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC) }
+	at := func(hour, minute int) time.Time { return time.Date(2026, 1, 7, hour, minute, 0, 0, time.UTC) }
+	allDay := func(r Recurrence) Series { return Series{Recurrence: r, AllDay: true, Duration: 24 * time.Hour} }
+	timed := func(r Recurrence, duration time.Duration) Series { return Series{Recurrence: r, Duration: duration} }
+	wednesday := day(1, 7)
+
+	texts := []struct {
+		text     string
+		expected Series
+	}{
+		// daily
+		{"daily", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1})},
+		{"every day", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1})},
+		{"Each day", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1})},
+		{"once a day", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1})},
+		{"every 3 days", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 3})},
+		{"every three days", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 3})},
+		{"every other day", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 2})},
+		{"every 3rd day", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 3})},
+		{"weekdays", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)})},
+		{"every weekday", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)})},
+		{"on weekdays", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)})},
+		{"every 4 weekdays", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 4, DailyIsOnlyWeekday: boolPtr(true)})},
+
+		// weekly
+		{"weekly", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(8)})},
+		{"every week", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(8)})},
+		{"once a week", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(8)})},
+		{"biweekly", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(8)})},
+		{"fortnightly", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(8)})},
+		{"every fortnight", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(8)})},
+		{"every 3 weeks", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 3, WeeklyDaysIncluded: int16Ptr(8)})},
+		{"every tuesday", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(16)})},
+		{"Tuesdays", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(16)})},
+		{"on tuesdays and thursdays", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(16 + 4)})},
+		{"mon, wed and fri", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(32 + 8 + 2)})},
+		{"every Mon, Wed & Fri", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(32 + 8 + 2)})},
+		{"weekends", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(64 + 1)})},
+		{"every weekend", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(64 + 1)})},
+		{"every other Tuesday", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(16)})},
+		{"every second tuesday", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(16)})},
+		{"every 2nd tuesday", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(16)})},
+		{"every other week on monday and thursday", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(32 + 4)})},
+		{"tuesdays every 3 weeks", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 3, WeeklyDaysIncluded: int16Ptr(16)})},
+		{"biweekly on friday", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(2)})},
+
+		// monthly
+		{"monthly", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(7)})},
+		{"every month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(7)})},
+		{"quarterly", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 3, MonthlyDay: int16Ptr(7)})},
+		{"every quarter", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 3, MonthlyDay: int16Ptr(7)})},
+		{"every 6 months", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 6, MonthlyDay: int16Ptr(7)})},
+		{"on the 15th", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(15)})},
+		{"the 1st of every month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(1)})},
+		{"the first of each month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(1)})},
+		{"monthly on the 22nd", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(22)})},
+		{"every month on the 31st", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(31)})},
+		{"on the 15th of every other month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 2, MonthlyDay: int16Ptr(15)})},
+		{"the 10th day of every 3 months", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 3, MonthlyDay: int16Ptr(10)})},
+		{"last day of the month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-1)})},
+		{"second to last day of every month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-2)})},
+		{"last weekday of the month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(-1), MonthlyNearestWeekday: boolPtr(true)})},
+		{"first weekday of each month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(1), MonthlyNearestWeekday: boolPtr(true)})},
+		{"first Monday of each month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(1), MonthlyWeekOfMonth: int16Ptr(1)})},
+		{"every first monday of the month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(1), MonthlyWeekOfMonth: int16Ptr(1)})},
+		{"the 3rd thursday of every month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(3)})},
+		{"every 2nd tuesday of the month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(2), MonthlyWeekOfMonth: int16Ptr(2)})},
+		{"last friday of the month", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(5), MonthlyWeekOfMonth: int16Ptr(54)})},
+		{"every last friday", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(5), MonthlyWeekOfMonth: int16Ptr(54)})},
+		{"monthly on the last sunday", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(0), MonthlyWeekOfMonth: int16Ptr(54)})},
+		{"the fifth saturday of every 2 months", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 2, MonthlyDayOfWeek: int16Ptr(6), MonthlyWeekOfMonth: int16Ptr(5)})},
+
+		// yearly
+		{"yearly", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(1), MonthlyDay: int16Ptr(7)})},
+		{"annually", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(1), MonthlyDay: int16Ptr(7)})},
+		{"every 2 years", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "Y", RecurEvery: 2, YearlyMonth: int16Ptr(1), MonthlyDay: int16Ptr(7)})},
+		{"every march 5th", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(3), MonthlyDay: int16Ptr(5)})},
+		{"on July 4th every year", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(7), MonthlyDay: int16Ptr(4)})},
+		{"every year on dec 25", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(12), MonthlyDay: int16Ptr(25)})},
+		{"the 4th of july", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(7), MonthlyDay: int16Ptr(4)})},
+		{"last thursday of november", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(54)})},
+		{"4th thursday in november every year", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "Y", RecurEvery: 1, YearlyMonth: int16Ptr(11), MonthlyDayOfWeek: int16Ptr(4), MonthlyWeekOfMonth: int16Ptr(4)})},
+		{"feb 29 every 4 years", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "Y", RecurEvery: 4, YearlyMonth: int16Ptr(2), MonthlyDay: int16Ptr(29)})},
+
+		// times of day
+		{"weekdays at 9am", timed(Recurrence{StartDate: at(9, 0), RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)}, 0)},
+		{"daily at 9:30 pm", timed(Recurrence{StartDate: at(21, 30), RecurrencePatternCode: "D", RecurEvery: 1}, 0)},
+		{"every day at noon", timed(Recurrence{StartDate: at(12, 0), RecurrencePatternCode: "D", RecurEvery: 1}, 0)},
+		{"every day at midnight", timed(Recurrence{StartDate: at(0, 0), RecurrencePatternCode: "D", RecurEvery: 1}, 0)},
+		{"daily at 12am", timed(Recurrence{StartDate: at(0, 0), RecurrencePatternCode: "D", RecurEvery: 1}, 0)},
+		{"daily at 12 p.m.", timed(Recurrence{StartDate: at(12, 0), RecurrencePatternCode: "D", RecurEvery: 1}, 0)},
+		{"daily at 14:15", timed(Recurrence{StartDate: at(14, 15), RecurrencePatternCode: "D", RecurEvery: 1}, 0)},
+		{"daily at 7", timed(Recurrence{StartDate: at(7, 0), RecurrencePatternCode: "D", RecurEvery: 1}, 0)},
+		{"mon wed fri 7pm for 1 hour", timed(Recurrence{StartDate: at(19, 0), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(32 + 8 + 2)}, time.Hour)},
+		{"weekdays at 9am for 30 minutes", timed(Recurrence{StartDate: at(9, 0), RecurrencePatternCode: "D", RecurEvery: 1, DailyIsOnlyWeekday: boolPtr(true)}, 30*time.Minute)},
+		{"tuesdays from 9am to 10:30am", timed(Recurrence{StartDate: at(9, 0), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(16)}, 90*time.Minute)},
+		{"fridays from 10pm to 2am", timed(Recurrence{StartDate: at(22, 0), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(2)}, 4*time.Hour)},
+		{"saturdays 9 am - 5 pm", timed(Recurrence{StartDate: at(9, 0), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(1)}, 8*time.Hour)},
+		{"first monday of each month at 10am for an hour", timed(Recurrence{StartDate: at(10, 0), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDayOfWeek: int16Ptr(1), MonthlyWeekOfMonth: int16Ptr(1)}, time.Hour)},
+
+		// ends and starts
+		{"every other Tuesday until March", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(16), EndByDate: timePtr(day(2, 28))})},
+		{"every other Tuesday through March", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 2, WeeklyDaysIncluded: int16Ptr(16), EndByDate: timePtr(day(3, 31))})},
+		{"daily until june 30", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1, EndByDate: timePtr(day(6, 30))})},
+		{"daily until 30th of june 2027", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1, EndByDate: timePtr(time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC))})},
+		{"daily until 2026-06-30", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1, EndByDate: timePtr(day(6, 30))})},
+		{"daily till 6/30", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1, EndByDate: timePtr(day(6, 30))})},
+		{"daily until Jan 1", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1, EndByDate: timePtr(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC))})},
+		{"daily for 10 times", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1, NumberOfOccurrences: int16Ptr(10), EndByDate: timePtr(day(1, 16))})},
+		{"every week 5 times", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(8), NumberOfOccurrences: int16Ptr(5), EndByDate: timePtr(day(2, 4))})},
+		{"monthly for twelve occurrences", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(7), NumberOfOccurrences: int16Ptr(12), EndByDate: timePtr(day(12, 7))})},
+		{"every day twice", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1, NumberOfOccurrences: int16Ptr(2), EndByDate: timePtr(day(1, 8))})},
+		{"daily for 2 weeks", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1, EndByDate: timePtr(day(1, 20))})},
+		{"weekly for 3 months", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(8), EndByDate: timePtr(day(4, 6))})},
+		{"tuesdays starting march 3", allDay(Recurrence{StartDate: day(3, 3), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(16)})},
+		{"weekly starting tomorrow", allDay(Recurrence{StartDate: day(1, 8), RecurrencePatternCode: "W", RecurEvery: 1, WeeklyDaysIncluded: int16Ptr(4)})},
+		{"daily starting today for 3 days", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "D", RecurEvery: 1, EndByDate: timePtr(day(1, 9))})},
+		{"every month from 5 feb until 5 june", allDay(Recurrence{StartDate: day(2, 5), RecurrencePatternCode: "M", RecurEvery: 1, MonthlyDay: int16Ptr(5), EndByDate: timePtr(day(6, 5))})},
+		{"Repeats every 2 weeks on Monday, Wednesday and Friday, until June 30 2026", allDay(Recurrence{StartDate: wednesday, RecurrencePatternCode: "W", RecurEvery: 2,
+			WeeklyDaysIncluded: int16Ptr(32 + 8 + 2), EndByDate: timePtr(day(6, 30))})},
+	}
+	for _, text := range texts {
+		actual, err := ParseText(text.text, start)
+		if err != nil {
+			t.Error(text.text, err)
+			continue
+		}
+		compareSeries(t, &text.expected, actual, text.text)
+		if text.expected.NumberOfOccurrences != nil {
+			compareOccurrenceCount(t, int(*text.expected.NumberOfOccurrences), &actual.Recurrence, text.text)
+		}
+	}
+}
+
+func TestParseTextErrors(t *testing.T) {
+	start := time.Date(2026, 1, 7, 15, 45, 0, 0, time.UTC)
+	texts := []struct {
+		text    string
+		offset  int
+		phrase  string
+		message string
+	}{
+		{"", 0, "", "expected how often it repeats"},
+		{"at 9am", 6, "", "expected how often it repeats"},
+		{"every fortnite", 6, "fortnite", "expected day, week, month, year or a day of the week"},
+		{"every other", 11, "", "expected day, week, month, year or a day of the week"},
+		{"daily bananas", 6, "bananas", "unrecognized phrase"},
+		{"every tuesday at lunchtime", 17, "lunchtime", "expected a time of day"},
+		{"daily on mondays", 9, "mondays", "conflicting repeat"},
+		{"weekly every 2 months", 15, "months", "conflicting repeat"},
+		{"every 2 weeks every 3 weeks", 22, "weeks", "conflicting interval"},
+		{"daily until someday", 12, "someday", "expected a date"},
+		{"daily until march 2026 until april", 23, "until", "more than one end"},
+		{"daily at 9am at 10am", 16, "10am", "more than one time of day"},
+		{"sixth monday of the month", 0, "sixth", "unrecognized phrase"},
+		{"6th monday of the month", 0, "6th", "expected first to fifth or last"},
+		{"the 32nd", 4, "32nd", "expected a day of the month"},
+		{"the 15th and the 16th", 17, "16th", "more than one day of the month"},
+		{"daily for 3 parsecs", 12, "parsecs", "expected times, minutes, hours, days, weeks, months or years"},
+		{"daily for many days", 10, "many", "expected a number"},
+		{"every week on the 15th", 22, "", "day of the month given for a weekly recurrence"},
+		{"monthly on tuesday", 11, "tuesday", "conflicting repeat"},
+		{"daily on the 15th", 17, "", "day of the month given for a daily recurrence"},
+		{"every year in march", 19, "", "expected a day of the month"},
+		{"daily for 30 minutes", 20, "", "duration given without a time of day"},
+		{"daily until 2025-12-31", 22, "", "ends before it starts"},
+		{"daily 10 times until june", 15, "until", "more than one end"},
+		{"daily for 2 weeks 10 times", 18, "10", "more than one end"},
+		{"every year on april 31 twice", 28, "", "does not repeat that many times"},
+		{"once a fortnightly", 7, "fortnightly", "expected day, week, month or year"},
+	}
+	for _, text := range texts {
+		_, err := ParseText(text.text, start)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%q: expected a ParseError: %v", text.text, err)
+			continue
+		}
+		if parseErr.Text != text.text || parseErr.Offset != text.offset || parseErr.Phrase != text.phrase || parseErr.Message != text.message {
+			t.Errorf("%q: expected %q at %d (%s) vs actual %q at %d (%s)", text.text, text.phrase, text.offset, text.message, parseErr.Phrase, parseErr.Offset, parseErr.Message)
+		}
+	}
+
+	err := &ParseError{Text: "daily bananas", Offset: 6, Phrase: "bananas", Message: "unrecognized phrase"}
+	if err.Error() != `unrecognized phrase: "bananas" at offset 6 of "daily bananas"` {
+		t.Error(err.Error())
+	}
+	err = &ParseError{Text: "every other", Offset: 11, Message: "expected a unit"}
+	if err.Error() != `expected a unit at the end of "every other"` {
+		t.Error(err.Error())
+	}
+}
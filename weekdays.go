@@ -0,0 +1,166 @@
+package calendar
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Weekdays is a set of days of the week. Bit n holds time.Weekday(n), so Sunday is 1 and Saturday is 64, the
+// reverse of the legacy WeeklyDaysIncluded bitmask
+type Weekdays uint8
+
+// AllWeekdays holds every day of the week, which is what a weekly recurrence without WeeklyDaysIncluded uses
+const AllWeekdays Weekdays = 127
+
+// NewWeekdays returns the set holding days
+func NewWeekdays(days ...time.Weekday) Weekdays {
+	var w Weekdays
+	for _, day := range days {
+		w.Add(day)
+	}
+	return w
+}
+
+// WeekdaysFromBitmask converts a legacy WeeklyDaysIncluded bitmask (Sunday = 64 through Saturday = 1)
+func WeekdaysFromBitmask(weeklyDaysIncluded int16) Weekdays {
+	var w Weekdays
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if weeklyDaysIncluded&weekdayBit(day) != 0 {
+			w.Add(day)
+		}
+	}
+	return w
+}
+
+// Bitmask converts the set to a legacy WeeklyDaysIncluded bitmask (Sunday = 64 through Saturday = 1)
+func (w Weekdays) Bitmask() int16 {
+	var weeklyDaysIncluded int16
+	for day := range w.Iter() {
+		weeklyDaysIncluded |= weekdayBit(day)
+	}
+	return weeklyDaysIncluded
+}
+
+// Add adds day to the set. Days outside Sunday to Saturday are ignored
+func (w *Weekdays) Add(day time.Weekday) {
+	if day >= time.Sunday && day <= time.Saturday {
+		*w |= 1 << uint(day)
+	}
+}
+
+// Has returns whether day is in the set
+func (w Weekdays) Has(day time.Weekday) bool {
+	return day >= time.Sunday && day <= time.Saturday && w&(1<<uint(day)) != 0
+}
+
+// Iter yields the days in the set from Sunday to Saturday
+func (w Weekdays) Iter() iter.Seq[time.Weekday] {
+	return func(yield func(time.Weekday) bool) {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if w.Has(day) && !yield(day) {
+				return
+			}
+		}
+	}
+}
+
+// Days returns the days in the set from Sunday to Saturday
+func (w Weekdays) Days() []time.Weekday {
+	var days []time.Weekday
+	for day := range w.Iter() {
+		days = append(days, day)
+	}
+	return days
+}
+
+// String returns the days as RFC 5545 day names, e.g. "MO,WE,FR"
+func (w Weekdays) String() string {
+	var names []string
+	for day := range w.Iter() {
+		names = append(names, rruleDayNames[day])
+	}
+	return strings.Join(names, ",")
+}
+
+// MarshalText implements encoding.TextMarshaler, writing the days as in String. JSON uses it too
+func (w Weekdays) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, reading a comma separated list of RFC 5545 day names in any case
+func (w *Weekdays) UnmarshalText(text []byte) error {
+	var days Weekdays
+	if strings.TrimSpace(string(text)) == "" {
+		*w = days
+		return nil
+	}
+	for _, name := range strings.Split(string(text), ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		day, ok := weekdayByName(name)
+		if !ok {
+			return fmt.Errorf("invalid day of the week %q", name)
+		}
+		days.Add(day)
+	}
+	*w = days
+	return nil
+}
+
+// Scan implements sql.Scanner, reading either a legacy WeeklyDaysIncluded bitmask or a list of day names
+func (w *Weekdays) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*w = 0
+		return nil
+	case int64:
+		if value < 0 || value > 127 {
+			return fmt.Errorf("days of the week bitmask %d is out of range", value)
+		}
+		*w = WeekdaysFromBitmask(int16(value))
+		return nil
+	case []byte:
+		return w.scanString(string(value))
+	case string:
+		return w.scanString(value)
+	}
+	return fmt.Errorf("cannot scan %T into Weekdays", src)
+}
+
+func (w *Weekdays) scanString(value string) error {
+	if n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+		return w.Scan(n)
+	}
+	return w.UnmarshalText([]byte(value))
+}
+
+// Value implements driver.Valuer, storing the legacy WeeklyDaysIncluded bitmask so existing columns keep their meaning
+func (w Weekdays) Value() (driver.Value, error) {
+	return int64(w.Bitmask()), nil
+}
+
+// Weekdays returns the days of a weekly recurrence, every day when WeeklyDaysIncluded is nil
+func (r *Recurrence) Weekdays() Weekdays {
+	if r.WeeklyDaysIncluded == nil {
+		return AllWeekdays
+	}
+	return WeekdaysFromBitmask(*r.WeeklyDaysIncluded)
+}
+
+// SetWeekdays sets WeeklyDaysIncluded to the bitmask of w
+func (r *Recurrence) SetWeekdays(w Weekdays) {
+	weeklyDaysIncluded := w.Bitmask()
+	r.WeeklyDaysIncluded = &weeklyDaysIncluded
+}
+
+func weekdayByName(name string) (time.Weekday, bool) {
+	for day, dayName := range rruleDayNames {
+		if dayName == name {
+			return time.Weekday(day), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,108 @@
+package calendar
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWeekdays(t *testing.T) {
+	w := NewWeekdays(time.Monday, time.Wednesday)
+	w.Add(time.Friday)
+	w.Add(time.Weekday(9))
+	if !w.Has(time.Monday) || !w.Has(time.Friday) || w.Has(time.Tuesday) || w.Has(time.Weekday(-1)) {
+		t.Error("unexpected membership", w)
+	}
+	days := w.Days()
+	if len(days) != 3 || days[0] != time.Monday || days[1] != time.Wednesday || days[2] != time.Friday {
+		t.Error("expected Monday, Wednesday and Friday", days)
+	}
+	for day := range w.Iter() {
+		if day != time.Monday {
+			t.Error("expected iteration to start on Monday", day)
+		}
+		break
+	}
+	if w.Bitmask() != 32+8+2 || WeekdaysFromBitmask(42) != w {
+		t.Error("expected MWF to be the legacy bitmask 42", w.Bitmask())
+	}
+	if WeekdaysFromBitmask(127) != AllWeekdays || AllWeekdays.Bitmask() != 127 || len(AllWeekdays.Days()) != 7 {
+		t.Error("expected all days")
+	}
+	if w.String() != "MO,WE,FR" || AllWeekdays.String() != "SU,MO,TU,WE,TH,FR,SA" || Weekdays(0).String() != "" {
+		t.Error("unexpected string", w)
+	}
+
+	r := Recurrence{RecurrencePatternCode: "W", RecurEvery: 1}
+	if r.Weekdays() != AllWeekdays {
+		t.Error("expected a nil WeeklyDaysIncluded to include all days")
+	}
+	r.SetWeekdays(NewWeekdays(time.Sunday, time.Saturday))
+	if *r.WeeklyDaysIncluded != 64+1 || r.Weekdays() != NewWeekdays(time.Saturday, time.Sunday) {
+		t.Error("expected weekends", *r.WeeklyDaysIncluded)
+	}
+}
+
+func TestWeekdaysText(t *testing.T) {
+	texts := map[string]Weekdays{
+		"MO,WE,FR":   NewWeekdays(time.Monday, time.Wednesday, time.Friday),
+		"fr, mo ,We": NewWeekdays(time.Monday, time.Wednesday, time.Friday),
+		"SU,SA":      NewWeekdays(time.Sunday, time.Saturday),
+		"":           0,
+		" ":          0,
+		"TU,TU":      NewWeekdays(time.Tuesday),
+	}
+	for text, expected := range texts {
+		var actual Weekdays
+		if err := actual.UnmarshalText([]byte(text)); err != nil || actual != expected {
+			t.Errorf("%q: expected %s vs actual %s: %v", text, expected, actual, err)
+		}
+	}
+	for _, text := range []string{"MO,", "MONDAY", "MO;WE", "XX"} {
+		var w Weekdays
+		if err := w.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("expected %q to be invalid", text)
+		}
+	}
+
+	value := struct {
+		Days Weekdays `json:"days"`
+	}{NewWeekdays(time.Tuesday, time.Thursday)}
+	data, err := json.Marshal(value)
+	if err != nil || string(data) != `{"days":"TU,TH"}` {
+		t.Error("unexpected JSON", string(data), err)
+	}
+	value.Days = 0
+	if err := json.Unmarshal(data, &value); err != nil || value.Days != NewWeekdays(time.Tuesday, time.Thursday) {
+		t.Error("expected Tuesday and Thursday", value.Days, err)
+	}
+}
+
+func TestWeekdaysSQL(t *testing.T) {
+	w := NewWeekdays(time.Monday, time.Wednesday, time.Friday)
+	if value, err := w.Value(); err != nil || value != int64(42) {
+		t.Error("expected the legacy bitmask", value, err)
+	}
+	sources := []struct {
+		src      interface{}
+		expected Weekdays
+	}{
+		{int64(42), w},
+		{[]byte("42"), w},
+		{"MO,WE,FR", w},
+		{[]byte("mo,we,fr"), w},
+		{nil, 0},
+	}
+	for _, source := range sources {
+		actual := AllWeekdays
+		if err := actual.Scan(source.src); err != nil || actual != source.expected {
+			t.Errorf("%v: expected %s vs actual %s: %v", source.src, source.expected, actual, err)
+		}
+	}
+	for _, src := range []interface{}{int64(128), int64(-1), "200", 4.2, "XX"} {
+		var actual Weekdays
+		if err := actual.Scan(src); err == nil {
+			t.Errorf("expected %v to be invalid", src)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package calendar
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// XCalNamespace is the xCal (RFC 6321) namespace that iCalendar elements belong to
+const XCalNamespace = "urn:ietf:params:xml:ns:icalendar-2.0"
+
+// xcalElement is any xCal element. Value elements hold Text and the others hold Children
+type xcalElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr    `xml:",any,attr"`
+	Text     string        `xml:",chardata"`
+	Children []xcalElement `xml:",any"`
+}
+
+// xcalRecurOrder is the order RFC 6321 requires the rule parts of a recur element to be in
+var xcalRecurOrder = []string{"FREQ", "UNTIL", "COUNT", "INTERVAL", "BYSECOND", "BYMINUTE", "BYHOUR", "BYDAY", "BYMONTHDAY",
+	"BYYEARDAY", "BYWEEKNO", "BYMONTH", "BYSETPOS", "WKST"}
+
+// ToXCal returns the series as an xCal (RFC 6321) icalendar document holding one vevent with the given UID
+func (s *Series) ToXCal(uid string) ([]byte, error) {
+	calendar, err := s.icalCalendar(uid)
+	if err != nil {
+		return nil, err
+	}
+	// the namespace is written as an attribute so that child elements inherit it rather than resetting it
+	root := newXCalElement("icalendar", "", xcalComponent(calendar))
+	root.Attrs = []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: XCalNamespace}}
+	data, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// FromXCal reads the first vevent of an xCal (RFC 6321) icalendar document as a Series
+func FromXCal(data []byte) (*Series, error) {
+	root := xcalElement{}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid xcal: %w", err)
+	}
+	if root.XMLName.Space != XCalNamespace || root.XMLName.Local != "icalendar" {
+		return nil, fmt.Errorf("xcal root element must be icalendar in the %s namespace", XCalNamespace)
+	}
+	for _, child := range root.Children {
+		if child.XMLName.Local == "vcalendar" {
+			return seriesFromICalCalendar(parseXCalComponent(child))
+		}
+	}
+	return nil, fmt.Errorf("xcal has no vcalendar")
+}
+
+func newXCalElement(name, text string, children ...xcalElement) xcalElement {
+	return xcalElement{XMLName: xml.Name{Local: strings.ToLower(name)}, Text: text, Children: children}
+}
+
+// xcalComponent returns a component element holding its properties and components elements
+func xcalComponent(c *icalComponent) xcalElement {
+	properties := newXCalElement("properties", "")
+	for _, property := range c.properties {
+		properties.Children = append(properties.Children, xcalProperty(property))
+	}
+	component := newXCalElement(c.name, "", properties)
+	if len(c.components) > 0 {
+		components := newXCalElement("components", "")
+		for i := range c.components {
+			components.Children = append(components.Children, xcalComponent(&c.components[i]))
+		}
+		component.Children = append(component.Children, components)
+	}
+	return component
+}
+
+// xcalProperty returns a property element holding its parameters and one element per value
+func xcalProperty(p icalProperty) xcalElement {
+	property := newXCalElement(p.name, "")
+	if len(p.params) > 0 {
+		parameters := newXCalElement("parameters", "")
+		for _, name := range sortedKeys(p.params) {
+			parameters.Children = append(parameters.Children, newXCalElement(name, "", newXCalElement("text", p.params[name])))
+		}
+		property.Children = append(property.Children, parameters)
+	}
+	for _, value := range p.values {
+		switch p.valueType {
+		case "DATE", "DATE-TIME":
+			property.Children = append(property.Children, newXCalElement(p.valueType, icalExtendedDateTime(value)))
+		case "RECUR":
+			parts := map[string]string{}
+			for _, part := range strings.Split(value, ";") {
+				name, partValue, _ := strings.Cut(part, "=")
+				parts[name] = partValue
+			}
+			recur := newXCalElement("recur", "")
+			for _, name := range xcalRecurOrder {
+				if _, ok := parts[name]; !ok {
+					continue
+				}
+				for _, item := range strings.Split(parts[name], ",") {
+					if name == "UNTIL" {
+						item = icalExtendedDateTime(item)
+					}
+					recur.Children = append(recur.Children, newXCalElement(name, item))
+				}
+			}
+			property.Children = append(property.Children, recur)
+		default:
+			property.Children = append(property.Children, newXCalElement(p.valueType, value))
+		}
+	}
+	return property
+}
+
+func parseXCalComponent(e xcalElement) *icalComponent {
+	c := &icalComponent{name: strings.ToUpper(e.XMLName.Local)}
+	for _, child := range e.Children {
+		switch child.XMLName.Local {
+		case "properties":
+			for _, property := range child.Children {
+				c.properties = append(c.properties, parseXCalProperty(property))
+			}
+		case "components":
+			for _, component := range child.Children {
+				c.components = append(c.components, *parseXCalComponent(component))
+			}
+		}
+	}
+	return c
+}
+
+// parseXCalProperty converts a property element. The value type is taken from the first value element
+func parseXCalProperty(e xcalElement) icalProperty {
+	property := icalProperty{name: strings.ToUpper(e.XMLName.Local), params: map[string]string{}}
+	for _, child := range e.Children {
+		if child.XMLName.Local == "parameters" {
+			for _, parameter := range child.Children {
+				var values []string
+				for _, value := range parameter.Children {
+					values = append(values, strings.TrimSpace(value.Text))
+				}
+				property.params[strings.ToUpper(parameter.XMLName.Local)] = strings.Join(values, ",")
+			}
+			continue
+		}
+		if property.valueType == "" {
+			property.valueType = strings.ToUpper(child.XMLName.Local)
+		}
+		switch property.valueType {
+		case "DATE", "DATE-TIME":
+			property.values = append(property.values, icalBasicDateTime(strings.TrimSpace(child.Text)))
+		case "RECUR":
+			property.values = append(property.values, xcalRRule(child))
+		default:
+			property.values = append(property.values, strings.TrimSpace(child.Text))
+		}
+	}
+	return property
+}
+
+// xcalRRule converts a recur element to an RRULE value, joining repeated rule parts with commas
+func xcalRRule(recur xcalElement) string {
+	var names []string
+	values := map[string][]string{}
+	for _, part := range recur.Children {
+		name, value := strings.ToUpper(part.XMLName.Local), strings.TrimSpace(part.Text)
+		if name == "UNTIL" {
+			value = icalBasicDateTime(value)
+		}
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		values[name] = append(values[name], value)
+	}
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + strings.Join(values[name], ",")
+	}
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,69 @@
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestXCalFixtures(t *testing.T) {
+	for _, fixture := range icalFixtures(t) {
+		file := fixture.name + ".xml"
+		data := readTestData(t, "xcal", file)
+		actual, err := FromXCal(data)
+		if err != nil {
+			t.Error(file, err)
+			continue
+		}
+		compareSeries(t, &fixture.expected, actual, file)
+		if fixture.expected.NumberOfOccurrences != nil {
+			compareOccurrenceCount(t, int(*fixture.expected.NumberOfOccurrences), &actual.Recurrence, file)
+		}
+
+		exported, err := fixture.expected.ToXCal(fixture.uid)
+		if err != nil {
+			t.Error(file, err)
+		} else if string(exported)+"\n" != string(data) {
+			t.Errorf("%s: expected\n%s\nvs actual\n%s", file, data, exported)
+		}
+	}
+}
+
+func TestFromXCal(t *testing.T) {
+	// prefixed elements, unordered rule parts and unknown properties are accepted
+	data := `<x:icalendar xmlns:x="urn:ietf:params:xml:ns:icalendar-2.0"><x:vcalendar><x:components><x:vevent><x:properties>
+		<x:summary><x:text>Standup</x:text></x:summary>
+		<x:dtstart><x:date-time>2016-01-05T09:00:00Z</x:date-time></x:dtstart>
+		<x:rrule><x:recur><x:byday>TU</x:byday><x:freq>WEEKLY</x:freq><x:byday>TH</x:byday><x:count>4</x:count></x:recur></x:rrule>
+		</x:properties></x:vevent></x:components></x:vcalendar></x:icalendar>`
+	actual, err := FromXCal([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Series{Recurrence: Recurrence{StartDate: time.Date(2016, 1, 5, 9, 0, 0, 0, time.UTC), RecurrencePatternCode: "W", RecurEvery: 1,
+		WeeklyDaysIncluded: int16Ptr(16 + 4), NumberOfOccurrences: int16Ptr(4), EndByDate: timePtr(time.Date(2016, 1, 14, 0, 0, 0, 0, time.UTC))}}
+	compareSeries(t, &expected, actual, "TestFromXCal")
+	compareOccurrenceCount(t, 4, &actual.Recurrence, "TestFromXCal")
+
+	rdate := `<icalendar xmlns="urn:ietf:params:xml:ns:icalendar-2.0"><vcalendar><components><vevent><properties>
+		<dtstart><date>2016-01-05</date></dtstart><rrule><recur><freq>DAILY</freq></recur></rrule><rdate><date>2016-01-10</date></rdate>
+		</properties></vevent></components></vcalendar></icalendar>`
+	if _, err := FromXCal([]byte(rdate)); !errors.Is(err, ErrNotRepresentable) {
+		t.Error("expected RDATE to be unrepresentable", err)
+	}
+
+	invalid := []string{
+		"",
+		`<icalendar><vcalendar/></icalendar>`,
+		`<icalendar xmlns="urn:ietf:params:xml:ns:icalendar-2.0"></icalendar>`,
+		`<icalendar xmlns="urn:ietf:params:xml:ns:icalendar-2.0"><vcalendar></vcalendar></icalendar>`,
+		`<icalendar xmlns="urn:ietf:params:xml:ns:icalendar-2.0"><vcalendar><components><vevent><properties>
+			<dtstart><text>2016-01-05</text></dtstart><rrule><recur><freq>DAILY</freq></recur></rrule>
+			</properties></vevent></components></vcalendar></icalendar>`,
+	}
+	for _, xcal := range invalid {
+		if _, err := FromXCal([]byte(xcal)); err == nil || errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("expected %s to be invalid: %v", xcal, err)
+		}
+	}
+}